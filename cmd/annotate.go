@@ -24,6 +24,7 @@ information from [SnapGene](https://www.snapgene.com/resources/plasmid-files/)`,
 // set flags
 func init() {
 	annotateCmd.Flags().StringP("in", "i", "", "input file name")
+	annotateCmd.Flags().Bool("strip-invalid", false, "remove, rather than error on, characters other than A, T, G and C in the input sequence")
 	annotateCmd.Flags().StringP("out", "o", "", "output file name")
 	annotateCmd.Flags().StringP("exclude", "x", "", "keywords for excluding features")
 	annotateCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd is for checking whether a plasmid can be built before designing it.
+var checkCmd = &cobra.Command{
+	Use:                        "check",
+	Run:                        repp.CheckCmd,
+	Short:                      "Check whether a plasmid can be built from the fragment databases",
+	Example:                    `  repp check --in "./2ndVal_mScarlet-I.fa" --addgene --dbs "parts.fa"`,
+	SuggestionsMinimumDistance: 2,
+	Long: `Check whether the target plasmid can be built from the fragment databases,
+without the search for assemblies.
+
+The target is BLAST'ed against the databases and the regions without a matching
+fragment are logged. If they need more synthetic fragments than an assembly can
+have (fragments-max-count), the target can't be built and the command fails.`,
+}
+
+// set flags
+func init() {
+	checkCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank)")
+	checkCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
+	checkCmd.Flags().StringP("dbs", "d", "", "list of local fragment databases")
+	checkCmd.Flags().String("db-fasta", "", dbFastaHelp)
+	checkCmd.Flags().StringP("inventory", "n", "", inventoryHelp)
+	checkCmd.Flags().String("have", "", haveHelp)
+	checkCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
+	checkCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
+	checkCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
+	checkCmd.Flags().StringP("backbone", "b", "", backboneHelp)
+	checkCmd.Flags().StringP("enzymes", "e", "", enzymeHelp)
+	checkCmd.Flags().String("enzyme-seq", "", enzymeSeqHelp)
+	checkCmd.Flags().StringP("exclude", "x", "", "keywords for excluding fragments")
+	checkCmd.Flags().IntP("identity", "p", 98, "%-identity threshold (see 'blastn -help')")
+	checkCmd.Flags().String("min-identity", "", minIdentityHelp)
+	checkCmd.Flags().String("dust", "on", dustHelp)
+
+	RootCmd.AddCommand(checkCmd)
+}
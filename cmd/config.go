@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configCmd is for logging the effective settings used during plasmid design
+var configCmd = &cobra.Command{
+	Use:                        "config",
+	Short:                      "Print the effective settings",
+	SuggestionsMinimumDistance: 2,
+	Long: `Print the settings used during plasmid design after layering, in increasing precedence:
+the root settings file (~/.repp/config.yaml), a project settings file in the working
+directory (.repp.yaml), environment variables (eg REPP_FRAGMENTS_MAX_COUNT), and the
+settings file passed with --settings.`,
+	Aliases: []string{"settings"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if settings, _ := cmd.Flags().GetString("settings"); settings != "" {
+			viper.Set("settings", settings)
+		}
+
+		config.New()
+		effective, err := config.Effective()
+		if err != nil {
+			repp.Fatal(err)
+		}
+		fmt.Print(string(effective))
+	},
+}
+
+func init() {
+	configCmd.Flags().StringP("settings", "s", "", "build settings")
+
+	RootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd is for comparing the solutions in two output files.
+var diffCmd = &cobra.Command{
+	Use:                        "diff [old.json] [new.json]",
+	Run:                        repp.DiffCmd,
+	Short:                      "Diff the solutions of two plasmid designs",
+	Example:                    "  repp diff ./2ndVal_mScarlet-I.output.json ./2ndVal_mScarlet-I.new.output.json",
+	SuggestionsMinimumDistance: 2,
+	Long: `Log the differences between a solution in each of two output JSON files.
+
+The change in cost and fragment count is logged, along with the fragments and junctions
+removed (-) and added (+). PCR fragments are matched by their template and the region
+amplified from it. Synthetic fragments are matched by their sequence.`,
+}
+
+// set flags
+func init() {
+	diffCmd.Flags().Int("solution", 1, "1-based index of the solution to compare in each file")
+
+	RootCmd.AddCommand(diffCmd)
+}
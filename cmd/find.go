@@ -53,6 +53,19 @@ var fragmentFindCmd = &cobra.Command{
 	Long:                       `Find a fragment with a given name in the databases requested.`,
 }
 
+// databaseFindCmd is for listing the BLAST databases that can be searched
+var databaseFindCmd = &cobra.Command{
+	Use:                        "database",
+	Short:                      "List the BLAST databases and check that they're valid",
+	Run:                        repp.DatabaseFindCmd,
+	Example:                    "  repp ls db --dbs parts_library.fa",
+	SuggestionsMinimumDistance: 2,
+	Long: `List the BLAST databases that can be searched: those of the repositories, those
+made from FASTA files with --db-fasta, and any passed with --dbs. Each is logged with its
+path, number of sequences, and whether it's a valid BLAST database.`,
+	Aliases: []string{"databases", "db", "dbs"},
+}
+
 // sequenceFindCmd is for finding a sequence in the dbs
 var sequenceFindCmd = &cobra.Command{
 	Use:                        "sequence [seq]",
@@ -66,22 +79,29 @@ var sequenceFindCmd = &cobra.Command{
 
 // set flags
 func init() {
+	enzymeFindCmd.Flags().Int("max-distance", 2, "maximum levenshtein distance of similarly named enzymes")
+	enzymeFindCmd.Flags().Int("limit", 0, "maximum number of enzymes to log (0 for all)")
+
 	fragmentFindCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
 	fragmentFindCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
 	fragmentFindCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
 	fragmentFindCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU respository")
 
+	databaseFindCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
+
 	sequenceFindCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
 	sequenceFindCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
 	sequenceFindCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
 	sequenceFindCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU respository")
 	sequenceFindCmd.Flags().StringP("exclude", "x", "", "keywords for excluding fragments")
 	sequenceFindCmd.Flags().IntP("identity", "t", 100, "match %-identity threshold (see 'blastn -help')")
+	sequenceFindCmd.Flags().String("dust", "on", `mask low-complexity regions of the sequence with DUST: "on" or "off"`)
 
 	findCmd.AddCommand(featureFindCmd)
 	findCmd.AddCommand(enzymeFindCmd)
 	findCmd.AddCommand(fragmentFindCmd)
 	findCmd.AddCommand(sequenceFindCmd)
+	findCmd.AddCommand(databaseFindCmd)
 
 	RootCmd.AddCommand(findCmd)
 }
@@ -14,6 +14,156 @@ in one of the dbs or a file on the local filesystem.`
 	enzymeHelp = `comma separated list of enzymes to linearize the backbone with.
 The backbone must be specified. 'repp ls enzymes' prints a list of
 recognized enzymes.`
+
+	explainHelp = `log why the cheapest solution was chosen: the assemblies considered for each
+fragment count, the cost of the runner-up, and what decided between them`
+
+	minIdentityHelp = `comma separated list of %-identity thresholds for specific databases, in place
+of --identity, by their path or file name. Ex: "genome=99,addgene=95"`
+
+	optimizeHelp = `what to optimize solutions for: cost, time for the shortest estimated
+turnaround from the lead times of the fragments' sources, and then cost, or
+max-synth-length for the shortest longest synthetic fragment, and then cost`
+
+	primerTailsHelp = `split each primer in the output into its 5' tail, added for homology with the
+neighboring fragment, and the region that anneals to the template`
+
+	primerRepeatsHelp = `warn of primers that bind the assembled plasmid more than once, like in
+repeated terminators, and may misprime in later PCRs off it, like a colony PCR`
+
+	rejectLogHelp = `file to write the assemblies rejected in the search to, one JSON object per line
+with the fragments and the reason, ex: "off-target". Also logged with --verbose`
+
+	minFragmentLengthHelp = `minimum length of PCR and synthetic fragments, in bp. Synthetic fragments are
+extended into their neighbors to reach it and assemblies with shorter fragments are skipped`
+
+	junctionMethodHelp = `how adjacent fragments are joined: gibson, or soe for overlap-extension PCR.
+SOE uses longer overlaps, from the soe-min-junction-length setting, and costs a PCR for
+each junction. The primers are the same, their tails are the overlaps`
+
+	junctionOverlapHelp = `comma separated list of overlap lengths for specific junctions, by the
+IDs of the fragments on either side: "left/right=40". Other junctions are picked automatically`
+
+	junctionScarHelp = `comma separated list of sequences to put between specific fragments, by
+the IDs of the fragments on either side: "left/right=GGTGGCGGTTCA". Added by the primers' tails`
+
+	flankSitesHelp = `enzymes whose sites the primers add to the left and right ends of the insert,
+for subcloning it later: "EcoRI,BamHI", or one for both ends. The insert can't have the sites`
+
+	flankSpacerHelp = `bp to put outside each of the --flank-sites, between it and the backbone, so
+the enzymes cut near the ends of a linear product`
+
+	seqPrimerSitesHelp = `sequencing primers whose sites the primers add to the left and right ends of the
+insert, reading into it: "M13F,M13R", or one for only the left end. Universal primers
+are built in, more can be added to the seq-primers setting`
+
+	autoEnzymeHelp = `pick the enzyme to linearize the backbone: the first of the --enzymes or,
+if there are none, of all the enzymes that cuts the backbone once and not the inserts`
+
+	linearBackboneHelp = `use the --backbone as it is, without digesting it, if it's already linear, like a
+PCR product or a plasmid that was cut beforehand. No enzyme is needed`
+
+	noBackboneHelp = `build the whole circular plasmid de novo, from matched and synthetic fragments
+alone, with the junction that closes it designed between the last and first fragments`
+
+	keep5OverhangsHelp = `keep the 5' overhangs of the digested backbone, as for ligation, rather than
+trim them as the exonuclease of a Gibson assembly would`
+
+	enzymeSeqHelp = `comma separated list of recognition sequences to linearize the
+backbone with, for enzymes not in the enzyme database. The cut sites are marked with
+"^" and "_", or offset from the site, like 'repp set enzyme'. Ex: "G^AATT_C"`
+
+	outputFormatHelp = `format of additional output files. "benchling" also writes a CSV
+table of the plasmid's features for import into Benchling. "sbol" also writes the
+cheapest solution as an SBOL2 XML document. "jsonl" writes the output of each of a
+batch's targets as a line of the --out file, as soon as it's designed (make sequence only)`
+
+	requireHelp = `comma separated list of fragment IDs that must be in every assembly,
+regardless of cost.`
+
+	inventoryHelp = `comma separated list of local fragment databases with plasmids
+already on hand. Fragments from these are preferred over others.`
+
+	haveHelp = `comma separated list of FASTA files with fragments already in hand,
+like a partially built vector. They're free to use so only the remaining
+fragments are designed.`
+
+	costReportHelp = `file to write a summary of each target's cheapest solution to, sorted
+by cost. A CSV if it ends in ".csv", otherwise a TSV. Pass multiple comma separated
+input files to --in to design them all in a batch.`
+
+	workersHelp = `number of a batch's targets to design at once. Each worker designs
+one target at a time, so memory use grows with the number of workers.`
+
+	graphHelp = `GraphViz DOT file to write the graph of fragments searched for assemblies to.
+Edges are junctions via PCR or synthesis, and the cheapest solution is highlighted.`
+
+	synthFastaHelp = `FASTA file to write the synthetic fragments of the cheapest solution to, for
+a synthesis vendor's bulk order. Each is named by the target and its 1-based range on it.`
+
+	bomHelp = `CSV file to write the bill of materials of the solutions to: the plasmids to
+procure, with their URLs, the backbone and its enzymes, and the primers and synthetic
+fragments to order, with their sequences and costs`
+
+	overlapReportHelp = `TSV file to write the junctions of the solutions to, for review: the fragments on
+either side of each, its overlap's length, sequence, GC % and Tm, and the QC checks it
+fails (hairpin, repeat, duplicate or criteria)`
+
+	noJunctionsHelp = `comma separated list of regions of the target that fragment junctions can't
+be in. Either ranges, 1-based and inclusive, or names of features in the feature database.
+Ex: "120-480,T7_promoter"`
+
+	featureJunctionsHelp = `prefer junctions on the boundaries of the target's features, from the features
+database, where the feature is whole in and shared by the fragment on its side of the junction`
+
+	stripInvalidHelp = "remove, rather than error on, characters other than A, T, G and C in input sequences"
+
+	synthVendorHelp = `synthesis vendor preset with its length limits and costs: "idt", "twist",
+"genscript", or one defined in the settings file's synthetic-vendors`
+
+	primerModHelp = `comma separated list of 5' modifications, in IDT syntax, to add to the ordered
+primers. For every primer, a fragment's primers, or one primer. Ex: "/5Phos/,pSB1A3:REV=/5SpC3/"`
+
+	primer3SettingsHelp = `primer3 settings file, of TAG=VALUE lines, to pass through to primer3. Settings
+like Tms, GC and self-complementarity are honored; those set from the fragments are ignored`
+
+	insertsHelp = `comma separated list of inserts to clone into distinct sites of the backbone,
+as "file[:rev]@position". Each goes after the 1-based position on the uncut backbone,
+so the backbone isn't digested and --enzymes can't be passed with it. Ex: "GFP.fa@120,RFP.fa:rev@2400"`
+
+	primersOnlyHelp = `layout file of the fragments to amplify, in order, to only design their primers.
+BLAST and the search for assemblies are skipped. Each line is a fragment's source, start,
+end and strand (1 or -1), separated by tabs or commas. Coordinates are 1-based. The
+layout is the whole plasmid, so a --backbone can't be passed with it`
+
+	orderHintHelp = `comma separated list of fragment IDs in the order they're preferred
+along the plasmid. Assemblies that follow it are preferred but not required.`
+
+	sourceCostHelp = `fixed cost of each distinct source plasmid procured for an assembly, like
+an order's shipping fee. Assemblies drawing from fewer plasmids are preferred.`
+
+	dustHelp = `mask low-complexity regions of the target with DUST before BLAST: "on" or "off".
+Masking speeds up repetitive targets but fragments that only match in repeats may be missed`
+
+	monovalentConcHelp = "concentration of monovalent cations (mM), ex: K+ and Na+, in the PCR master mix"
+
+	divalentConcHelp = "concentration of divalent cations (mM), ex: Mg2+, in the PCR master mix"
+
+	dntpConcHelp = "total concentration of dNTPs (mM) in the PCR master mix"
+
+	primerConcHelp = "concentration of each primer (nM) in the PCR"
+
+	productsHelp = `list the products of digesting the backbone with the enzymes,
+rather than building, to pick the band to gel-purify`
+
+	avoidSitesHelp = `comma separated list of enzymes, by name or recognition sequence, whose
+sites are removed from synthetic fragments by single bp substitutions. Ex: BsaI,BsmBI`
+
+	bothStrandsHelp = `also design the reverse complement of the target and keep the cheaper
+design. The output's targetStrand is -1 if its coordinates are on the reverse complement`
+
+	dbFastaHelp = `comma separated list of FASTA files to use as fragment databases.
+BLAST databases are made from them and cached until the FASTA files change.`
 )
 
 // makeCmd is for finding building a plasmid from its fragments, features, or sequence
@@ -34,7 +184,8 @@ var fragmentsCmd = &cobra.Command{
 	SuggestionsMinimumDistance: 3,
 	Long: `Prepare a list of fragments for assembly via Gibson Assembly. Fragments are
 checked for existing homology with their neighbors and are prepared for
-assembly with PCR.`,
+assembly with PCR. The fragments may be in a directory of files, read in the
+order listed in its manifest.txt or else in lexical order.`,
 }
 
 // featuresCmd is for building a plasmid from its list of contained features
@@ -55,49 +206,206 @@ var sequenceCmd = &cobra.Command{
 	Long: `Build up a plasmid from its target sequence using a combination of existing and
 synthesized fragments.
 
-Solutions have either a minimum fragment count or assembly cost (or both).`,
+Solutions have either a minimum fragment count or assembly cost (or both).
+
+Multiple comma separated input files are designed in a batch, each with its output
+next to its input file. Use --cost-report for a summary of the whole batch.`,
 	Aliases: []string{"seq", "plasmid"},
 	Example: `repp make sequence -i "./target_plasmid.fa --addgene --dbs "part_library.fa"`,
 }
 
+// synthesisCmd is for splitting a plasmid into equal length synthetic fragments
+var synthesisCmd = &cobra.Command{
+	Use:                        "synthesis",
+	Short:                      "Build a plasmid from equal length synthetic fragments",
+	Run:                        repp.SynthesisCmd,
+	SuggestionsMinimumDistance: 2,
+	Long: `Split the target plasmid into a number of synthetic fragments of roughly equal
+length. Each fragment overlaps the next for Gibson Assembly. No databases are searched.`,
+	Aliases: []string{"synthesize", "synthetic"},
+	Example: `repp make synthesis -i "./target_plasmid.fa" --pieces 4 --overlap 30`,
+}
+
+// biobrickCmd is for composing BioBrick parts by 3A assembly
+var biobrickCmd = &cobra.Command{
+	Use:                        "biobrick \"[part],...[partN]\"",
+	Short:                      "Compose BioBrick parts into a composite part by 3A assembly",
+	Run:                        repp.BiobrickCmd,
+	SuggestionsMinimumDistance: 2,
+	Long: `Compose BioBrick parts, in order, into a composite part with the RFC10 prefix
+and suffix and a scar at each junction. The output has the composite's sequence and
+the rounds of 3A assembly to make it: each joins an upstream part cut with EcoRI
+and SpeI to a downstream part cut with XbaI and PstI in a destination backbone cut
+with EcoRI and PstI. Parts can't have EcoRI, XbaI, SpeI, PstI or NotI sites.`,
+	Aliases: []string{"3a"},
+	Example: `repp make biobrick "BBa_R0062,BBa_B0034,BBa_C0040,BBa_B0015" --igem --backbone pSB1C3`,
+}
+
 // set flags
 func init() {
 	// Flags for specifying the paths to the input file, input fragment files, and output file
-	fragmentsCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank)")
+	fragmentsCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank), or a directory of them")
+	fragmentsCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
 	fragmentsCmd.Flags().StringP("out", "o", "", "output file name (FASTA)")
+	fragmentsCmd.Flags().String("output-format", "json", outputFormatHelp)
+	fragmentsCmd.Flags().String("synth-fasta", "", synthFastaHelp)
+	fragmentsCmd.Flags().String("bom", "", bomHelp)
+	fragmentsCmd.Flags().String("overlap-report", "", overlapReportHelp)
 	fragmentsCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
+	fragmentsCmd.Flags().String("db-fasta", "", dbFastaHelp)
 	fragmentsCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
 	fragmentsCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
 	fragmentsCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
 	fragmentsCmd.Flags().StringP("backbone", "b", "", backboneHelp)
 	fragmentsCmd.Flags().StringP("enzymes", "e", "", enzymeHelp)
+	fragmentsCmd.Flags().Bool("auto-enzyme", false, autoEnzymeHelp)
+	fragmentsCmd.Flags().Bool("linear-backbone", false, linearBackboneHelp)
+	fragmentsCmd.Flags().Bool("no-backbone", false, noBackboneHelp)
+	fragmentsCmd.Flags().String("enzyme-seq", "", enzymeSeqHelp)
+	fragmentsCmd.Flags().Bool("keep-5-overhangs", false, keep5OverhangsHelp)
+	fragmentsCmd.Flags().String("junction-method", "", junctionMethodHelp)
+	fragmentsCmd.Flags().String("junction-overlap", "", junctionOverlapHelp)
+	fragmentsCmd.Flags().String("junction-scar", "", junctionScarHelp)
+	fragmentsCmd.Flags().String("flank-sites", "", flankSitesHelp)
+	fragmentsCmd.Flags().String("flank-spacer", "", flankSpacerHelp)
+	fragmentsCmd.Flags().String("seq-primer-sites", "", seqPrimerSitesHelp)
+	fragmentsCmd.Flags().Bool("strict", false, "error out, rather than warn, if an insert has the backbone enzymes' sites")
+	fragmentsCmd.Flags().Bool("products", false, productsHelp)
+	fragmentsCmd.Flags().String("synth-vendor", "", synthVendorHelp)
+	fragmentsCmd.Flags().String("avoid-sites", "", avoidSitesHelp)
+	fragmentsCmd.Flags().String("primer-mod", "", primerModHelp)
+	fragmentsCmd.Flags().Bool("primer-tails", false, primerTailsHelp)
+	fragmentsCmd.Flags().Bool("primer-repeats", false, primerRepeatsHelp)
+	fragmentsCmd.Flags().String("primer3-settings", "", primer3SettingsHelp)
+	fragmentsCmd.Flags().Float64("monovalent-conc", 0, monovalentConcHelp)
+	fragmentsCmd.Flags().Float64("divalent-conc", 0, divalentConcHelp)
+	fragmentsCmd.Flags().Float64("dntp-conc", 0, dntpConcHelp)
+	fragmentsCmd.Flags().Float64("primer-conc", 0, primerConcHelp)
 
 	// Flags for specifying the paths to the input file, input fragment files, and output file
 	featuresCmd.Flags().StringP("out", "o", "", "output file name")
+	featuresCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
+	featuresCmd.Flags().String("output-format", "json", outputFormatHelp)
+	featuresCmd.Flags().String("synth-fasta", "", synthFastaHelp)
+	featuresCmd.Flags().String("bom", "", bomHelp)
+	featuresCmd.Flags().String("overlap-report", "", overlapReportHelp)
+	featuresCmd.Flags().String("graph", "", graphHelp)
 	featuresCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
+	featuresCmd.Flags().String("db-fasta", "", dbFastaHelp)
+	featuresCmd.Flags().StringP("inventory", "n", "", inventoryHelp)
+	featuresCmd.Flags().String("have", "", haveHelp)
 	featuresCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
 	featuresCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
 	featuresCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
 	featuresCmd.Flags().StringP("backbone", "b", "", backboneHelp)
 	featuresCmd.Flags().StringP("enzymes", "e", "", enzymeHelp)
+	featuresCmd.Flags().Bool("auto-enzyme", false, autoEnzymeHelp)
+	featuresCmd.Flags().Bool("linear-backbone", false, linearBackboneHelp)
+	featuresCmd.Flags().Bool("no-backbone", false, noBackboneHelp)
+	featuresCmd.Flags().String("enzyme-seq", "", enzymeSeqHelp)
+	featuresCmd.Flags().Bool("keep-5-overhangs", false, keep5OverhangsHelp)
+	featuresCmd.Flags().String("junction-method", "", junctionMethodHelp)
+	featuresCmd.Flags().String("junction-overlap", "", junctionOverlapHelp)
+	featuresCmd.Flags().Bool("strict", false, "error out, rather than warn, if an insert has the backbone enzymes' sites")
+	featuresCmd.Flags().Bool("products", false, productsHelp)
+	featuresCmd.Flags().String("synth-vendor", "", synthVendorHelp)
+	featuresCmd.Flags().String("avoid-sites", "", avoidSitesHelp)
+	featuresCmd.Flags().String("primer-mod", "", primerModHelp)
+	featuresCmd.Flags().Bool("primer-tails", false, primerTailsHelp)
+	featuresCmd.Flags().Bool("primer-repeats", false, primerRepeatsHelp)
+	featuresCmd.Flags().String("primer3-settings", "", primer3SettingsHelp)
+	featuresCmd.Flags().Float64("monovalent-conc", 0, monovalentConcHelp)
+	featuresCmd.Flags().Float64("divalent-conc", 0, divalentConcHelp)
+	featuresCmd.Flags().Float64("dntp-conc", 0, dntpConcHelp)
+	featuresCmd.Flags().Float64("primer-conc", 0, primerConcHelp)
 	featuresCmd.Flags().StringP("exclude", "x", "", "keywords for excluding fragments")
+	featuresCmd.Flags().String("require", "", requireHelp)
+	featuresCmd.Flags().String("order-hint", "", orderHintHelp)
+	featuresCmd.Flags().Float64("source-cost", 0, sourceCostHelp)
+	featuresCmd.Flags().Int("min-fragment-length", 0, minFragmentLengthHelp)
 	featuresCmd.Flags().IntP("identity", "p", 98, "%-identity threshold (see 'blastn -help')")
+	featuresCmd.Flags().String("min-identity", "", minIdentityHelp)
+	featuresCmd.Flags().String("dust", "on", dustHelp)
+	featuresCmd.Flags().Bool("explain", false, explainHelp)
+	featuresCmd.Flags().String("reject-log", "", rejectLogHelp)
+	featuresCmd.Flags().String("optimize", "cost", optimizeHelp)
 
 	// Flags for specifying the paths to the input file, input fragment files, and output file
-	sequenceCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank)")
+	sequenceCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank), or comma separated names for a batch")
+	sequenceCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
 	sequenceCmd.Flags().StringP("out", "o", "", "output file name")
+	sequenceCmd.Flags().String("output-format", "json", outputFormatHelp)
+	sequenceCmd.Flags().String("synth-fasta", "", synthFastaHelp)
+	sequenceCmd.Flags().String("bom", "", bomHelp)
+	sequenceCmd.Flags().String("overlap-report", "", overlapReportHelp)
+	sequenceCmd.Flags().String("graph", "", graphHelp)
+	sequenceCmd.Flags().String("cost-report", "", costReportHelp)
+	sequenceCmd.Flags().Int("workers", 1, workersHelp)
 	sequenceCmd.Flags().StringP("dbs", "d", "", "list of local fragment databases")
+	sequenceCmd.Flags().String("db-fasta", "", dbFastaHelp)
+	sequenceCmd.Flags().StringP("inventory", "n", "", inventoryHelp)
+	sequenceCmd.Flags().String("have", "", haveHelp)
 	sequenceCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
 	sequenceCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
 	sequenceCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
 	sequenceCmd.Flags().StringP("backbone", "b", "", backboneHelp)
 	sequenceCmd.Flags().StringP("enzymes", "e", "", enzymeHelp)
+	sequenceCmd.Flags().Bool("auto-enzyme", false, autoEnzymeHelp)
+	sequenceCmd.Flags().Bool("linear-backbone", false, linearBackboneHelp)
+	sequenceCmd.Flags().Bool("no-backbone", false, noBackboneHelp)
+	sequenceCmd.Flags().String("enzyme-seq", "", enzymeSeqHelp)
+	sequenceCmd.Flags().Bool("keep-5-overhangs", false, keep5OverhangsHelp)
+	sequenceCmd.Flags().String("junction-method", "", junctionMethodHelp)
+	sequenceCmd.Flags().String("junction-overlap", "", junctionOverlapHelp)
+	sequenceCmd.Flags().Bool("strict", false, "error out, rather than warn, if an insert has the backbone enzymes' sites")
+	sequenceCmd.Flags().Bool("products", false, productsHelp)
+	sequenceCmd.Flags().String("synth-vendor", "", synthVendorHelp)
+	sequenceCmd.Flags().String("avoid-sites", "", avoidSitesHelp)
+	sequenceCmd.Flags().String("primer-mod", "", primerModHelp)
+	sequenceCmd.Flags().Bool("primer-tails", false, primerTailsHelp)
+	sequenceCmd.Flags().Bool("primer-repeats", false, primerRepeatsHelp)
+	sequenceCmd.Flags().String("primer3-settings", "", primer3SettingsHelp)
+	sequenceCmd.Flags().Float64("monovalent-conc", 0, monovalentConcHelp)
+	sequenceCmd.Flags().Float64("divalent-conc", 0, divalentConcHelp)
+	sequenceCmd.Flags().Float64("dntp-conc", 0, dntpConcHelp)
+	sequenceCmd.Flags().Float64("primer-conc", 0, primerConcHelp)
 	sequenceCmd.Flags().StringP("exclude", "x", "", "keywords for excluding fragments")
+	sequenceCmd.Flags().String("require", "", requireHelp)
+	sequenceCmd.Flags().String("order-hint", "", orderHintHelp)
+	sequenceCmd.Flags().Float64("source-cost", 0, sourceCostHelp)
+	sequenceCmd.Flags().Int("min-fragment-length", 0, minFragmentLengthHelp)
+	sequenceCmd.Flags().String("no-junctions", "", noJunctionsHelp)
+	sequenceCmd.Flags().Bool("feature-junctions", false, featureJunctionsHelp)
+	sequenceCmd.Flags().String("inserts", "", insertsHelp)
+	sequenceCmd.Flags().String("primers-only", "", primersOnlyHelp)
+	sequenceCmd.Flags().Bool("both-strands", false, bothStrandsHelp)
 	sequenceCmd.Flags().IntP("identity", "p", 98, "%-identity threshold (see 'blastn -help')")
+	sequenceCmd.Flags().String("min-identity", "", minIdentityHelp)
+	sequenceCmd.Flags().String("dust", "on", dustHelp)
+	sequenceCmd.Flags().Bool("explain", false, explainHelp)
+	sequenceCmd.Flags().String("reject-log", "", rejectLogHelp)
+	sequenceCmd.Flags().String("optimize", "cost", optimizeHelp)
+
+	synthesisCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank)")
+	synthesisCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
+	synthesisCmd.Flags().StringP("out", "o", "", "output file name")
+	synthesisCmd.Flags().Int("pieces", 2, "number of synthetic fragments")
+	synthesisCmd.Flags().Int("overlap", 30, "bp of overlap between adjacent fragments")
+	synthesisCmd.Flags().String("synth-vendor", "", synthVendorHelp)
+	synthesisCmd.Flags().String("avoid-sites", "", avoidSitesHelp)
+
+	biobrickCmd.Flags().StringP("out", "o", "", "output file name")
+	biobrickCmd.Flags().StringP("dbs", "d", "", "comma separated list of local fragment databases")
+	biobrickCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
+	biobrickCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
+	biobrickCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
+	biobrickCmd.Flags().StringP("backbone", "b", "", "backbone to clone the composite part into, between its EcoRI and PstI sites")
 
 	makeCmd.AddCommand(fragmentsCmd)
 	makeCmd.AddCommand(featuresCmd)
 	makeCmd.AddCommand(sequenceCmd)
+	makeCmd.AddCommand(synthesisCmd)
+	makeCmd.AddCommand(biobrickCmd)
 
 	// settings is an optional parameter for a settings file (that overrides the fields in BaseSettingsFile)
 	makeCmd.PersistentFlags().StringP("settings", "s", config.RootSettingsFile, "build settings")
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd is for upgrading output files to the current schema.
+var migrateCmd = &cobra.Command{
+	Use:                        "migrate [output.json]",
+	Run:                        repp.MigrateCmd,
+	Short:                      "Upgrade output files to the current schema",
+	Example:                    "  repp migrate ./2ndVal_mScarlet-I.output.json --in-place",
+	SuggestionsMinimumDistance: 2,
+	Long: `Upgrade output JSON files, written by older versions of repp, to the current schema.
+
+Fields added to the schema since the file was written are filled from its solutions'
+fragments and primers, and its "schemaVersion" is set to the current version. Files
+written before the schema was versioned are version 0. The upgraded output is logged,
+or written over the files with --in-place.`,
+}
+
+// set flags
+func init() {
+	migrateCmd.Flags().Bool("in-place", false, "write the upgraded outputs over the files, rather than to the stdout")
+
+	RootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// queryCmd is for finding the designs in output files that use a source part.
+var queryCmd = &cobra.Command{
+	Use:                        "query [output.json]...",
+	Run:                        repp.QueryCmd,
+	Short:                      "List the designs that use a source part",
+	Example:                    "  repp query --source addgene:12345 ./results/*.output.json",
+	SuggestionsMinimumDistance: 2,
+	Long: `List the fragments, in the solutions of output JSON files, that are from a source part.
+
+The part is an entry in a repository, like "addgene:12345", "igem:BBa_E0040" or
+"dnasu:123", or the ID of a fragment or its template, like "pSB1A3". Each fragment
+from it is logged with its output, target, solution and its 1-based start and end on
+the target, where its junctions with its neighbors are.`,
+}
+
+// set flags
+func init() {
+	queryCmd.Flags().String("source", "", `source part to query for, ex: "addgene:12345"`)
+
+	RootCmd.AddCommand(queryCmd)
+}
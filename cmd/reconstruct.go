@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// reconstructCmd is for logging the plasmid assembled from a solution in an output file.
+var reconstructCmd = &cobra.Command{
+	Use:                        "reconstruct [output.json]",
+	Run:                        repp.ReconstructCmd,
+	Short:                      "Log the plasmid assembled from a solution's fragments",
+	Example:                    "  repp reconstruct ./2ndVal_mScarlet-I.output.json > ./2ndVal_mScarlet-I.assembled.fa",
+	SuggestionsMinimumDistance: 2,
+	Long: `Log, as FASTA, the plasmid assembled from a solution in an output JSON file.
+
+Each fragment is joined to the next, and the last to the first, at their longest
+overlap: of at least fragments-min-junction-length bp, or a single bp for a digested
+backbone with overhangs. PCR fragments are joined by their amplified sequence, with
+their primers' additions. The command fails if the plasmid isn't the output's target.`,
+}
+
+// set flags
+func init() {
+	reconstructCmd.Flags().Int("solution", 1, "1-based index of the solution to reconstruct")
+	reconstructCmd.Flags().StringP("out", "o", "", "FASTA file to write the plasmid to, rather than the stdout")
+
+	RootCmd.AddCommand(reconstructCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// replCmd is for iterating on the design of a plasmid interactively.
+var replCmd = &cobra.Command{
+	Use:                        "repl",
+	Run:                        repp.ReplCmd,
+	Short:                      "Iterate on the design of a plasmid interactively",
+	Example:                    `  repp repl --in "./2ndVal_mScarlet-I.fa" --addgene --dbs "parts.fa"`,
+	SuggestionsMinimumDistance: 2,
+	Long: `Load the target plasmid and fragment databases once, then change settings
+and re-run the design from a prompt.
+
+The target is only BLAST'ed against the databases on the first run. Later runs
+reuse those matches, so changing a setting, like fragments-min-junction-length or
+the enzymes, and designing again is quick. Enter 'help' at the prompt for the commands.`,
+}
+
+// set flags
+func init() {
+	replCmd.Flags().StringP("in", "i", "", "input file name (FASTA or Genbank)")
+	replCmd.Flags().StringP("out", "o", "", "output file name")
+	replCmd.Flags().Bool("strip-invalid", false, stripInvalidHelp)
+	replCmd.Flags().StringP("dbs", "d", "", "list of local fragment databases")
+	replCmd.Flags().String("db-fasta", "", dbFastaHelp)
+	replCmd.Flags().StringP("inventory", "n", "", inventoryHelp)
+	replCmd.Flags().String("have", "", haveHelp)
+	replCmd.Flags().BoolP("addgene", "a", false, "use the Addgene repository")
+	replCmd.Flags().BoolP("igem", "g", false, "use the iGEM repository")
+	replCmd.Flags().BoolP("dnasu", "u", false, "use the DNASU repository")
+	replCmd.Flags().StringP("backbone", "b", "", backboneHelp)
+	replCmd.Flags().StringP("enzymes", "e", "", enzymeHelp)
+	replCmd.Flags().String("enzyme-seq", "", enzymeSeqHelp)
+	replCmd.Flags().StringP("exclude", "x", "", "keywords for excluding fragments")
+	replCmd.Flags().IntP("identity", "p", 98, "%-identity threshold (see 'blastn -help')")
+	replCmd.Flags().String("min-identity", "", minIdentityHelp)
+	replCmd.Flags().String("dust", "on", dustHelp)
+
+	RootCmd.AddCommand(replCmd)
+}
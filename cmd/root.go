@@ -2,8 +2,9 @@
 package cmd
 
 import (
-	"log"
+	"os"
 
+	"github.com/jjtimmons/repp/config"
 	"github.com/jjtimmons/repp/internal/repp"
 	"github.com/spf13/cobra"
 )
@@ -21,13 +22,46 @@ var RootCmd = &cobra.Command{
 	
 Repository-based plasmid design. Specify and build plasmids using
 their sequence, features, or fragments`,
-	Version: "0.1.0",
+	Version: config.Version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// log failures as JSON objects, for pipelines
+		if jsonErrors, _ := cmd.Flags().GetBool("json-errors"); jsonErrors {
+			repp.UseJSONErrors()
+		}
+
+		// use different enzyme and feature databases than the defaults
+		if enzymePath, _ := cmd.Flags().GetString("enzyme-db"); enzymePath != "" {
+			config.EnzymeDB = enzymePath
+			*enzymeDB = *repp.NewEnzymeDB()
+		}
+
+		if featurePath, _ := cmd.Flags().GetString("feature-db"); featurePath != "" {
+			config.FeatureDB = featurePath
+			*featureDB = *repp.NewFeatureDB()
+		}
+	},
+}
+
+func init() {
+	RootCmd.PersistentFlags().String("enzyme-db", "", "path to the enzymes database. Defaults to $REPP_ENZYME_DB or ~/.repp/enzymes.tsv")
+	RootCmd.PersistentFlags().String("feature-db", "", "path to the features database. Defaults to $REPP_FEATURE_DB or ~/.repp/features.tsv")
+	RootCmd.PersistentFlags().Bool("json-errors", false, "log failures to stderr as JSON objects with the error, phase and details")
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// flags that fail to parse end the command before PersistentPreRun, so --json-errors
+	// is checked for here too. Cobra's own logging of the failure is silenced for it
+	for _, arg := range os.Args[1:] {
+		if arg == "--json-errors" || arg == "--json-errors=true" {
+			repp.UseJSONErrors()
+			RootCmd.SilenceErrors = true
+			RootCmd.SilenceUsage = true
+		}
+	}
+
 	if err := RootCmd.Execute(); err != nil {
-		log.Fatalf("%v", err)
+		repp.Fatal(err)
 	}
 }
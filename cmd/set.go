@@ -38,14 +38,25 @@ Set an enzyme in the enzymes database so it can be used to linearize backbones.
 Enzymes are passed to the build command, by name, with the --enzyme flag.
 
 Valid recognition sequences have both a cut site in the template sequence: "^" and
-a cut site in the complement sequence: "_". Use 'repp ls enzyme' for examples`,
+a cut site in the complement sequence: "_". Use 'repp ls enzyme' for examples.
+
+Cuts outside the recognition sequence can also be written as offsets from it, as
+they are for most Type IIS enzymes: "GGTCTC(1/5)" cuts 1bp after the site on the
+template sequence and 5bp after it on the complement sequence.
+
+Other names of the enzyme, like its isoschizomers, are set with --aliases and can be
+used in place of its name. Setting an alias updates the enzyme it's an alias of.`,
 	Aliases: []string{"add", "update"},
-	Example: "  repp set enzyme BbvCI CC^TCA_GC",
+	Example: `  repp set enzyme BbvCI CC^TCA_GC
+  repp set enzyme BsaI "GGTCTC(1/5)"
+  repp set enzyme BsaI "GGTCTC(1/5)" --aliases "Eco31I,BsaI-HFv2"`,
 }
 
 func init() {
 	setCmd.AddCommand(featureCreateCmd)
 	setCmd.AddCommand(enzymeCreateCmd)
 
+	enzymeCreateCmd.Flags().String("aliases", "", "comma separated list of other names for the enzyme, replacing any it had")
+
 	RootCmd.AddCommand(setCmd)
 }
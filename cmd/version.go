@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jjtimmons/repp/internal/repp"
+	"github.com/spf13/cobra"
+)
+
+// versionCmd is for logging the version of repp.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Log the version, git commit and build date of repp",
+	Long: `Log the version, git commit and build date of repp.
+
+The same are in the "build" of every design's output, to tell which
+version of repp made it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(repp.Build())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(versionCmd)
+}
@@ -2,12 +2,16 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
 	"log"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/mitchellh/mapstructure"
@@ -24,6 +28,10 @@ var (
 	// RootSettingsFile is the default settings file path for the config package
 	RootSettingsFile = filepath.Join(reppDir, "config.yaml")
 
+	// ProjectSettingsFile is a settings file in the working directory, layered
+	// over the root settings file
+	ProjectSettingsFile = ".repp.yaml"
+
 	// Primer3Config is the path to the embedded primer3 config directory
 	Primer3Config = filepath.Join(reppDir, "primer3_config") + string(os.PathSeparator)
 
@@ -36,13 +44,37 @@ var (
 	// DNASUDB is the path to the DNASU db
 	DNASUDB = filepath.Join(reppDir, "dnasu")
 
-	// FeatureDB is the path to the features db
-	FeatureDB = filepath.Join(reppDir, "features.tsv")
+	// DBCacheDir is the directory of BLAST dbs made from FASTA files (--db-fasta)
+	DBCacheDir = filepath.Join(reppDir, "cache")
+
+	// DBHashCache is the file of the hashes of databases' files, for outputs' provenance
+	DBHashCache = filepath.Join(DBCacheDir, "db-hashes.json")
+
+	// FeatureDB is the path to the features db. Overridden by $REPP_FEATURE_DB
+	FeatureDB = envPath("REPP_FEATURE_DB", filepath.Join(reppDir, "features.tsv"))
 
-	// EnzymeDB is the path to the enzymes db file
-	EnzymeDB = filepath.Join(reppDir, "enzymes.tsv")
+	// EnzymeDB is the path to the enzymes db file. Overridden by $REPP_ENZYME_DB
+	EnzymeDB = envPath("REPP_ENZYME_DB", filepath.Join(reppDir, "enzymes.tsv"))
+
+	// Fatalf logs a failure to load the settings and exits. It's replaced to log them as
+	// JSON objects with --json-errors
+	Fatalf = log.Fatalf
+
+	// Version of repp. Commit and BuildDate are the git commit and date of the build.
+	// All three are set with -ldflags "-X ..." by 'make build'
+	Version   = "0.1.0"
+	Commit    = ""
+	BuildDate = ""
 )
 
+// envPath returns the path in the environment variable, or the default path if it's unset
+func envPath(name, defaultPath string) string {
+	if path := os.Getenv(name); path != "" {
+		return path
+	}
+	return defaultPath
+}
+
 // SynthCost contains data of the cost of synthesizing DNA up to a certain
 // size. Can be fixed (ie everything beneath that limit is the same amount)
 // or not (pay by the bp)
@@ -54,6 +86,86 @@ type SynthCost struct {
 	Cost float64 `mapstructure:"cost"`
 }
 
+// SynthVendor is a synthesis provider's limits on, and cost of, synthetic fragments
+type SynthVendor struct {
+	// minimum length of a synthesized piece of DNA
+	MinLength int `mapstructure:"min-length"`
+
+	// maximum length of a synthesized piece of DNA
+	MaxLength int `mapstructure:"max-length"`
+
+	// the cost per bp of synthesized DNA as a fragment (as a step function)
+	FragmentCost map[int]SynthCost `mapstructure:"fragment-cost"`
+}
+
+// JunctionCriteria are the GC % and Tm (celcius) ranges of a type of junction. A bound
+// that's 0 isn't checked
+type JunctionCriteria struct {
+	// minimum GC % of the junction
+	MinGC float64 `mapstructure:"min-gc"`
+
+	// maximum GC % of the junction
+	MaxGC float64 `mapstructure:"max-gc"`
+
+	// minimum estimated Tm of the junction
+	MinTm float64 `mapstructure:"min-tm"`
+
+	// maximum estimated Tm of the junction
+	MaxTm float64 `mapstructure:"max-tm"`
+}
+
+// synthVendors are the built-in synthesis vendor presets. Costs are list prices
+// as of 2020 and approximate a vendor's quote
+var synthVendors = map[string]SynthVendor{
+	// IDT gBlocks: https://www.idtdna.com/pages/products/genes-and-gene-fragments/gblocks-gene-fragments
+	"idt": {
+		MinLength: 125,
+		MaxLength: 3000,
+		FragmentCost: map[int]SynthCost{
+			500:  {Fixed: true, Cost: 89.0},
+			750:  {Fixed: true, Cost: 129.0},
+			1000: {Fixed: true, Cost: 149.0},
+			1250: {Fixed: true, Cost: 209.0},
+			1500: {Fixed: true, Cost: 249.0},
+			1750: {Fixed: true, Cost: 289.0},
+			2000: {Fixed: true, Cost: 329.0},
+			2250: {Fixed: true, Cost: 399.0},
+			2500: {Fixed: true, Cost: 449.0},
+			2750: {Fixed: true, Cost: 499.0},
+			3000: {Fixed: true, Cost: 549.0},
+		},
+	},
+
+	// Twist gene fragments: https://www.twistbioscience.com/products/genes
+	"twist": {
+		MinLength: 300,
+		MaxLength: 5000,
+		FragmentCost: map[int]SynthCost{
+			1800: {Fixed: false, Cost: 0.07},
+			5000: {Fixed: false, Cost: 0.09},
+		},
+	},
+
+	// GenScript GenBrick gene fragments: https://www.genscript.com/gene-fragments.html
+	"genscript": {
+		MinLength: 200,
+		MaxLength: 3000,
+		FragmentCost: map[int]SynthCost{
+			3000: {Fixed: false, Cost: 0.08},
+		},
+	},
+}
+
+// Range is a stretch of the target plasmid. Its start and end are 0-indexed and inclusive
+type Range struct {
+	Start int
+	End   int
+
+	// Name and Seq of the feature that the range is of, if it's one
+	Name string
+	Seq  string
+}
+
 // Config is the Root-level settings struct and is a mix
 // of settings available in config.yaml and those
 // available from the command line
@@ -61,6 +173,48 @@ type Config struct {
 	// Vebose is whether to log debug messages to the stdout
 	Verbose bool
 
+	// Explain is whether to log why the cheapest solution was chosen over the others
+	Explain bool `mapstructure:"-"`
+
+	// RejectLog is the file to write the assemblies rejected in the search, and why, to
+	RejectLog string `mapstructure:"-"`
+
+	// NoJunctions are the ranges of the target plasmid that fragment junctions can't
+	// be in. Set for each build from the command line
+	NoJunctions []Range `mapstructure:"-"`
+
+	// FeatureJunctions are the ranges of the features of the features database in the target.
+	// Junctions on their boundaries, with the feature whole in a fragment, are preferred
+	FeatureJunctions []Range `mapstructure:"-"`
+
+	// CostFeatureJunctionBonus is subtracted from the cost of a junction on the boundary of
+	// one of the FeatureJunctions
+	CostFeatureJunctionBonus float64 `mapstructure:"feature-junction-bonus"`
+
+	// JunctionOverlaps are the overlap lengths of specific junctions, keyed by the IDs of
+	// the fragments on either side. Set from the command line
+	JunctionOverlaps map[string]int `mapstructure:"-"`
+
+	// JunctionScars are the sequences pinned between specific fragments, keyed by the IDs
+	// of the fragments on either side. Set from the command line
+	JunctionScars map[string]string `mapstructure:"-"`
+
+	// SeqPrimers are sequencing primers, by name, whose sites can be added to the ends of
+	// an insert, in addition to the universal primers built into repp. Ex: pGEX-F
+	SeqPrimers map[string]string `mapstructure:"seq-primers"`
+
+	// SeqPrimerSites are the names of the sequencing primers whose sites are added to the
+	// left, and right, ends of the insert. Set from the command line
+	SeqPrimerSites []string `mapstructure:"-"`
+
+	// FlankSites are the sites of the enzymes that flank the insert on the assembled plasmid,
+	// named by their enzyme. Set from the command line's enzymes after the assembly
+	FlankSites []Range `mapstructure:"-"`
+
+	// OrderHint are the IDs of the source fragments, in the order they're preferred in
+	// along the target plasmid. Set from the command line
+	OrderHint []string `mapstructure:"-"`
+
 	// the cost of a single Addgene plasmid
 	CostAddgene float64 `mapstructure:"addgene-cost"`
 
@@ -70,6 +224,61 @@ type Config struct {
 	// the per plasmid cost of DNASU plasmids
 	CostDNASU float64 `mapstructure:"dnasu-cost"`
 
+	// multiplier on the estimated cost of reaching a fragment from the user's inventory
+	CostInventoryFactor float64 `mapstructure:"inventory-cost-factor"`
+
+	// DBWeights are multipliers on the estimated cost of using a fragment from each
+	// database, keyed by the database's file name without its extension
+	DBWeights map[string]float64 `mapstructure:"db-weights"`
+
+	// DBAliases are short names for databases passed to --dbs, by alias. Ex: lab: ~/dbs/lab_parts
+	DBAliases map[string]string `mapstructure:"db-aliases"`
+
+	// GenomicDBs are the databases of genomic DNA, keyed by their file names without extensions
+	GenomicDBs []string `mapstructure:"genomic-dbs"`
+
+	// CostGenomicPCR is added to the cost of a fragment PCR'd from a genomic database,
+	// PCR from a genome is less reliable than from a plasmid
+	CostGenomicPCR float64 `mapstructure:"genomic-pcr-penalty"`
+
+	// CostSource is a fixed cost for each distinct source plasmid procured for an assembly,
+	// like an order's shipping fee
+	CostSource float64 `mapstructure:"source-cost"`
+
+	// CostOrderHintBonus is subtracted from the estimated cost of an assembly, and from
+	// the cost solutions are compared by, for each fragment that follows the order of the
+	// fragments in the --order-hint
+	CostOrderHintBonus float64 `mapstructure:"order-hint-bonus"`
+
+	// CostCurrency is the currency symbol of costs in logs, ex: "$". Costs in the JSON
+	// output are numbers without one
+	CostCurrency string `mapstructure:"cost-currency"`
+
+	// CostDecimals is the number of decimal places costs in logs are rounded to
+	CostDecimals int `mapstructure:"cost-decimals"`
+
+	// LeadTimeAddgene is the estimated days to receive a plasmid ordered from Addgene
+	LeadTimeAddgene float64 `mapstructure:"addgene-lead-time"`
+
+	// LeadTimeIGEM is the estimated days to receive a part ordered from iGEM
+	LeadTimeIGEM float64 `mapstructure:"igem-lead-time"`
+
+	// LeadTimeDNASU is the estimated days to receive a plasmid ordered from DNASU
+	LeadTimeDNASU float64 `mapstructure:"dnasu-lead-time"`
+
+	// LeadTimeSynthesis is the estimated days to receive a synthetic fragment
+	LeadTimeSynthesis float64 `mapstructure:"synthetic-lead-time"`
+
+	// LeadTimePCR is the estimated days to PCR a fragment from a plasmid in hand
+	LeadTimePCR float64 `mapstructure:"pcr-lead-time"`
+
+	// LeadTimeAssembly is the estimated days to assemble the fragments and verify the plasmid
+	LeadTimeAssembly float64 `mapstructure:"assembly-lead-time"`
+
+	// Optimize is what solutions are optimized for: "cost", or "time" for the shortest
+	// turnaround and then cost. Set from the command line
+	Optimize string `mapstructure:"-"`
+
 	// the cost per bp of primer DNA
 	CostBP float64 `mapstructure:"pcr-bp-cost"`
 
@@ -100,9 +309,37 @@ type Config struct {
 	// maximum length of homology between two adjacent fragments in bp
 	FragmentsMaxHomology int `mapstructure:"fragments-max-junction-length"`
 
+	// FragmentsMinLength is the minimum length of PCR and synthetic fragments in an assembly
+	FragmentsMinLength int `mapstructure:"-"`
+
 	// maximum allowable hairpin melting temperature (celcius)
 	FragmentsMaxHairpinMelt float64 `mapstructure:"fragments-max-junction-hairpin"`
 
+	// minimum GC % of the homology between two adjacent fragments
+	FragmentsMinJunctionGC float64 `mapstructure:"fragments-min-junction-gc"`
+
+	// maximum GC % of the homology between two adjacent fragments
+	FragmentsMaxJunctionGC float64 `mapstructure:"fragments-max-junction-gc"`
+
+	// JunctionCriteria are the ranges of junctions by the fragments they join: "synthetic"
+	// between two synthetic fragments, "pcr" between two others, or "mixed"
+	JunctionCriteria map[string]JunctionCriteria `mapstructure:"junction-criteria"`
+
+	// JunctionMethod is how adjacent fragments are joined: "gibson", or "soe" for
+	// overlap-extension PCR
+	JunctionMethod string `mapstructure:"junction-method"`
+
+	// SOEMinHomology is the minimum homology between fragments joined by overlap-extension PCR
+	SOEMinHomology int `mapstructure:"soe-min-junction-length"`
+
+	// BackboneKeep5Overhangs is whether to keep the 5' overhangs of a digested backbone,
+	// as for ligation, rather than trim them as the exonuclease of a Gibson assembly would
+	BackboneKeep5Overhangs bool `mapstructure:"-"`
+
+	// BlastCircularPadding is the bp of a circular target's start BLAST'ed again after its
+	// end, to find matches across its zero index. The whole target if 0
+	BlastCircularPadding int `mapstructure:"blast-circular-padding"`
+
 	// PCRMinLength is the minimum size of a fragment (used to filter BLAST results)
 	PCRMinLength int `mapstructure:"pcr-min-length"`
 
@@ -112,49 +349,144 @@ type Config struct {
 	// the maximum length of a sequence to embed up or downstream of an amplified sequence
 	PCRMaxEmbedLength int `mapstructure:"pcr-primer-max-embed-length"`
 
+	// PCRHomologySplit is the share, 0 to 1, of the homology added to a junction by PCR
+	// that's in the tail of the upstream fragment's REV primer. The rest is in the tail
+	// of the downstream fragment's FWD primer. nil if unset, for an even split
+	PCRHomologySplit *float64 `mapstructure:"pcr-homology-split"`
+
+	// PCRMaxTailShift is the most bp to lengthen a primer's homology tail to remove a
+	// hairpin that pairs the tail with the primer's 3' end
+	PCRMaxTailShift int `mapstructure:"pcr-max-tail-shift"`
+
 	// PCRMaxOfftargetTm is the maximum tm of an offtarget, above which PCR is abandoned
 	PCRMaxOfftargetTm float64 `mapstructure:"pcr-primer-max-ectopic-tm"`
 
+	// PCRPrimerModification is a 5' modification, in IDT syntax, added to the ordered sequence
+	// of every primer. Ex: /5Phos/
+	PCRPrimerModification string `mapstructure:"pcr-primer-modification"`
+
+	// PCRPrimerName is the template of primers' names. {target}, {fragID}, {dir} and {index}
+	// are replaced with the target's name, the fragment's ID, FWD or REV, and the fragment's
+	// 1-based index in the assembly
+	PCRPrimerName string `mapstructure:"pcr-primer-name"`
+
+	// PCRPrimer3Settings are primer3 settings, by tag, passed through to primer3.
+	// Ex: PRIMER_OPT_TM: 62
+	PCRPrimer3Settings map[string]string `mapstructure:"pcr-primer3-settings"`
+
+	// PrimerTails is whether to split each primer in the output into its 5' tail and
+	// annealing region. Set from the command line
+	PrimerTails bool `mapstructure:"-"`
+
+	// PrimerRepeats is whether to warn of primers with more than one binding site in
+	// the assembled plasmid. Set from the command line
+	PrimerRepeats bool `mapstructure:"-"`
+
+	// PrimerModifications are 5' modifications of primers from the command line. Keyed by
+	// fragment ID, fragment ID and direction (ID:FWD or ID:REV), or "" for every primer
+	PrimerModifications map[string]string `mapstructure:"-"`
+
+	// AvoidSites are the recognition sequences, by enzyme name, of the sites to remove
+	// from synthetic fragments. Set from the command line
+	AvoidSites map[string]string `mapstructure:"-"`
+
 	// PCRBufferLength is the length of buffer from the ends of a match in which
 	// to allow Primer3 to look for a primer
 	PCRBufferLength int `mapstructure:"pcr-buffer-length"`
 
+	// PCRExtensionRate is the polymerase's extension time in seconds per kb
+	PCRExtensionRate float64 `mapstructure:"pcr-extension-rate"`
+
+	// PCRMaxAmpliconLength is the longest amplicon the polymerase reliably amplifies
+	PCRMaxAmpliconLength int `mapstructure:"pcr-max-amplicon-length"`
+
+	// PCRAnnealingRange is the range of annealing temperatures (celcius) for PCRs
+	// that can share a thermocycler program
+	PCRAnnealingRange float64 `mapstructure:"pcr-annealing-range"`
+
+	// PCRMonovalentConc is the concentration of monovalent cations (mM), ex: K+ and Na+, in the PCR
+	PCRMonovalentConc float64 `mapstructure:"pcr-monovalent-conc"`
+
+	// PCRDivalentConc is the concentration of divalent cations (mM), ex: Mg2+, in the PCR
+	PCRDivalentConc float64 `mapstructure:"pcr-divalent-conc"`
+
+	// PCRDNTPConc is the total concentration of dNTPs (mM) in the PCR
+	PCRDNTPConc float64 `mapstructure:"pcr-dntp-conc"`
+
+	// PCRPrimerConc is the concentration of each primer (nM) in the PCR
+	PCRPrimerConc float64 `mapstructure:"pcr-primer-conc"`
+
 	// maximum length of a synthesized piece of DNA
 	SyntheticMaxLength int `mapstructure:"synthetic-max-length"`
 
 	// minimum length of a synthesized piece of DNA
 	SyntheticMinLength int `mapstructure:"synthetic-min-length"`
+
+	// maximum fraction of the target plasmid to synthesize in a solution
+	SyntheticMaxFraction float64 `mapstructure:"synthetic-max-fraction"`
+
+	// SynthVendors are user-defined synthesis vendor presets, by name
+	SynthVendors map[string]SynthVendor `mapstructure:"synthetic-vendors"`
 }
 
 // New returns a new Config struct populated by settings from
 // config.yaml, in the repo, or some other settings file the user
 // points to with the "--config" command
 //
+// Settings are layered in increasing precedence:
+//  1. the root settings file, ~/.repp/config.yaml
+//  2. a project settings file in the working directory, .repp.yaml
+//  3. environment variables, eg REPP_FRAGMENTS_MAX_COUNT for fragments-max-count
+//  4. a settings file passed with --settings
+//
 // TODO: check for and error out on nonsense config values
-// TODO: add back the config file path setting
 func New() *Config {
 	// read in the default/base settings file first
 	viper.SetConfigType("yaml")
 	viper.SetConfigFile(RootSettingsFile)
 	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal(err)
+		Fatalf("%v", err)
+	}
+
+	// then the project's settings file, if there is one
+	if _, err := os.Stat(ProjectSettingsFile); err == nil {
+		viper.SetConfigFile(ProjectSettingsFile)
+		if err := viper.MergeInConfig(); err != nil {
+			Fatalf("%v", err)
+		}
+	}
+
+	// then any settings in the environment
+	for _, key := range viper.AllKeys() {
+		if value, set := os.LookupEnv(envName(key)); set {
+			var typed interface{} // parse numbers and bools like they are in YAML
+			if err := yaml.Unmarshal([]byte(value), &typed); err != nil {
+				typed = value
+			}
+			viper.Set(key, typed)
+		}
 	}
 
 	if userSettings := viper.GetString("settings"); userSettings != "" && userSettings != RootSettingsFile {
 		viper.SetConfigFile(userSettings)             // user has specified a new path for a settings file
 		if err := viper.MergeInConfig(); err != nil { // read in user defined settings file
-			log.Fatal(err)
+			Fatalf("%v", err)
 		}
 
 		file, _ := os.Open(userSettings)
 		userData := make(map[string]interface{})
 		if err := yaml.NewDecoder(file).Decode(userData); err != nil {
-			log.Fatal(err)
+			Fatalf("%v", err)
+		}
+
+		// settings from the file take precedence over those in the environment
+		for key, value := range userData {
+			viper.Set(key, value)
 		}
 
 		userConfig := &Config{}
 		if err := mapstructure.Decode(userData, userConfig); err != nil {
-			log.Fatal(err)
+			Fatalf("%v", err)
 		}
 
 		if userConfig.CostSyntheticFragment != nil {
@@ -167,30 +499,321 @@ func New() *Config {
 
 	// make sure all depedencies are available (may belong elsewhere)
 	if _, err := exec.LookPath("blastn"); err != nil {
-		log.Fatal("no blastn executable available in PATH, try `make install`")
+		Fatalf("no blastn executable available in PATH, try `make install`")
 	}
 
 	if _, err := exec.LookPath("blastdbcmd"); err != nil {
-		log.Fatal("no blastdbcmd executable available in PATH, try `make install`")
+		Fatalf("no blastdbcmd executable available in PATH, try `make install`")
 	}
 
 	if _, err := exec.LookPath("primer3_core"); err != nil {
-		log.Fatal("no primer3_core executable available in PATH, try `make install`")
+		Fatalf("no primer3_core executable available in PATH, try `make install`")
 	}
 
 	if _, err := exec.LookPath("ntthal"); err != nil {
-		log.Fatal("no ntthal executable available in PATH, try `make install`")
+		Fatalf("no ntthal executable available in PATH, try `make install`")
 	}
 
+	// primer3's reaction concentrations, for settings files from before they were settings
+	viper.SetDefault("pcr-monovalent-conc", 50.0)
+	viper.SetDefault("pcr-divalent-conc", 1.5)
+	viper.SetDefault("pcr-dntp-conc", 0.6)
+	viper.SetDefault("pcr-primer-conc", 50.0)
+
 	// build Config
 	config := &Config{}
 	if err := viper.Unmarshal(&config); err != nil {
-		log.Fatalf("failed to decode settings file %s: %v", viper.ConfigFileUsed(), err)
+		Fatalf("failed to decode settings file %s: %v", viper.ConfigFileUsed(), err)
 	}
 
 	return config
 }
 
+// Effective returns the resolved settings, from every settings file and the environment,
+// as YAML. It should be called after New.
+func Effective() ([]byte, error) {
+	return yaml.Marshal(viper.AllSettings())
+}
+
+// envName returns the environment variable name for a setting. Ex: REPP_PCR_MIN_LENGTH
+func envName(key string) string {
+	return "REPP_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// UseSynthVendor sets the synthesis length limits and fragment cost curve to those of a
+// vendor preset. User-defined presets take precedence over the built-in ones
+func (c *Config) UseSynthVendor(name string) error {
+	vendor, exists := c.SynthVendors[name]
+	if !exists {
+		if vendor, exists = synthVendors[strings.ToLower(name)]; !exists {
+			return fmt.Errorf("unknown synthesis vendor %s. must be one of: %s", name, strings.Join(c.synthVendorNames(), ", "))
+		}
+	}
+
+	if vendor.MinLength > 0 {
+		c.SyntheticMinLength = vendor.MinLength
+	}
+	if vendor.MaxLength > 0 {
+		c.SyntheticMaxLength = vendor.MaxLength
+	}
+	if len(vendor.FragmentCost) > 0 {
+		c.CostSyntheticFragment = vendor.FragmentCost
+	}
+
+	return nil
+}
+
+// UseJunctionMethod sets how adjacent fragments are joined. Overlap-extension PCR needs
+// longer overlaps than Gibson, so the minimum homology is raised to SOEMinHomology.
+func (c *Config) UseJunctionMethod(method string) error {
+	switch strings.ToLower(method) {
+	case "", "gibson":
+		c.JunctionMethod = "gibson"
+	case "soe":
+		c.JunctionMethod = "soe"
+		if c.FragmentsMinHomology < c.SOEMinHomology {
+			c.FragmentsMinHomology = c.SOEMinHomology
+		}
+		if c.FragmentsMaxHomology < c.FragmentsMinHomology {
+			c.FragmentsMaxHomology = c.FragmentsMinHomology
+		}
+	default:
+		return fmt.Errorf("unknown junction method %s. must be one of: gibson, soe", method)
+	}
+
+	return nil
+}
+
+// ReadPrimer3Settings reads a primer3 settings file, of TAG=VALUE lines, into the
+// settings passed through to primer3. They take precedence over those in the settings
+// file. The header of a primer3 settings file and its P3_FILE_ tags are skipped.
+func (c *Config) ReadPrimer3Settings(filename string) error {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read primer3 settings %s: %v", filename, err)
+	}
+
+	if c.PCRPrimer3Settings == nil {
+		c.PCRPrimer3Settings = make(map[string]string)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		eq := strings.Index(line, "=")
+		if eq < 1 || strings.HasPrefix(line, "P3_FILE_") {
+			continue // a blank line, the header, or the closing "="
+		}
+
+		c.PCRPrimer3Settings[strings.TrimSpace(line[:eq])] = strings.TrimSpace(line[eq+1:])
+	}
+
+	return nil
+}
+
+// SetJunctionOverlaps parses a comma separated list of junctions with overlap lengths,
+// like "left/right=40", where left and right are the IDs of the fragments on either side.
+// The lengths have to be within the fragments' min and max junction lengths.
+func (c *Config) SetJunctionOverlaps(spec string) error {
+	c.JunctionOverlaps = make(map[string]int)
+
+	for _, junction := range strings.Split(spec, ",") {
+		junction = strings.TrimSpace(junction)
+		if junction == "" {
+			continue
+		}
+
+		eq := strings.LastIndex(junction, "=")
+		slash := strings.Index(junction, "/")
+		if eq < 0 || slash < 1 || slash > eq-2 {
+			return fmt.Errorf("failed to parse junction overlap %s, expected left/right=length", junction)
+		}
+
+		length, err := strconv.Atoi(strings.TrimSpace(junction[eq+1:]))
+		if err != nil {
+			return fmt.Errorf("failed to parse the length of junction overlap %s: %v", junction, err)
+		}
+		if length < c.FragmentsMinHomology || length > c.FragmentsMaxHomology {
+			return fmt.Errorf(
+				"junction overlap %s is outside the junction length range: %d-%d",
+				junction, c.FragmentsMinHomology, c.FragmentsMaxHomology,
+			)
+		}
+
+		left := strings.TrimSpace(junction[:slash])
+		right := strings.TrimSpace(junction[slash+1 : eq])
+		c.JunctionOverlaps[left+"/"+right] = length
+	}
+
+	return nil
+}
+
+// JunctionOverlap returns the overlap length set for the junction between the fragments
+// with the left and right IDs, and whether one was set.
+func (c *Config) JunctionOverlap(left, right string) (length int, set bool) {
+	length, set = c.JunctionOverlaps[left+"/"+right]
+	return
+}
+
+// SetJunctionScars parses a comma separated list of junctions with the sequences to
+// put between their fragments, like "left/right=GGTGGCGGTTCA", where left and right
+// are the IDs of the fragments on either side.
+func (c *Config) SetJunctionScars(spec string) error {
+	c.JunctionScars = make(map[string]string)
+
+	for _, junction := range strings.Split(spec, ",") {
+		junction = strings.TrimSpace(junction)
+		if junction == "" {
+			continue
+		}
+
+		eq := strings.LastIndex(junction, "=")
+		slash := strings.Index(junction, "/")
+		if eq < 0 || slash < 1 || slash > eq-2 {
+			return fmt.Errorf("failed to parse junction scar %s, expected left/right=sequence", junction)
+		}
+
+		scar := strings.ToUpper(strings.TrimSpace(junction[eq+1:]))
+		if scar == "" || strings.Trim(scar, "ACGT") != "" {
+			return fmt.Errorf("failed to parse the sequence of junction scar %s, expected only A, C, G and T", junction)
+		}
+
+		left := strings.TrimSpace(junction[:slash])
+		right := strings.TrimSpace(junction[slash+1 : eq])
+		c.JunctionScars[left+"/"+right] = scar
+	}
+
+	return nil
+}
+
+// JunctionScar returns the sequence pinned between the fragments with the left and
+// right IDs, and whether one was set.
+func (c *Config) JunctionScar(left, right string) (scar string, set bool) {
+	scar, set = c.JunctionScars[left+"/"+right]
+	return
+}
+
+// MonovalentConc returns the concentration of monovalent cations (mM) in the PCR, or
+// primer3's default of 50 if it's unset. Tms are -Inf without the cations.
+func (c *Config) MonovalentConc() float64 {
+	if c.PCRMonovalentConc <= 0 {
+		return 50
+	}
+	return c.PCRMonovalentConc
+}
+
+// PrimerConc returns the concentration of each primer (nM) in the PCR, or primer3's
+// default of 50 if it's unset. Tms are -Inf without the primers.
+func (c *Config) PrimerConc() float64 {
+	if c.PCRPrimerConc <= 0 {
+		return 50
+	}
+	return c.PCRPrimerConc
+}
+
+// JunctionCriteriaOf returns the criteria of a type of junction, "synthetic", "pcr" or "mixed",
+// and the name of the set applied. Types without their own use the fragments' junction GC
+// range, named "default".
+func (c *Config) JunctionCriteriaOf(seam string) (criteria JunctionCriteria, name string) {
+	if criteria, set := c.JunctionCriteria[seam]; set {
+		return criteria, seam
+	}
+
+	return JunctionCriteria{MinGC: c.FragmentsMinJunctionGC, MaxGC: c.FragmentsMaxJunctionGC}, "default"
+}
+
+// DBWeight returns the multiplier on the estimated cost of using a fragment from the
+// database at the path. It's 1 for databases without a weight.
+func (c *Config) DBWeight(db string) float64 {
+	if db == "" {
+		return 1
+	}
+
+	name := filepath.Base(db)
+	name = strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name))) // viper lower cases keys
+	if weight, set := c.DBWeights[name]; set {
+		return weight
+	}
+	return 1
+}
+
+// GenomicDB returns whether the database at the path is one of genomic DNA.
+func (c *Config) GenomicDB(db string) bool {
+	if db == "" {
+		return false
+	}
+
+	name := filepath.Base(db)
+	name = strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	for _, genomic := range c.GenomicDBs {
+		if strings.ToLower(genomic) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DBAlias returns the path of the database with the alias: one from the settings file or,
+// if there isn't one, the name of a repository's database, "addgene", "igem" or "dnasu".
+func (c *Config) DBAlias(alias string) (path string, set bool) {
+	alias = strings.ToLower(strings.TrimSpace(alias)) // viper lower cases keys
+	if path, set = c.DBAliases[alias]; set {
+		if expanded, err := homedir.Expand(path); err == nil {
+			path = expanded // a path in the home directory, ex: ~/dbs/lab_parts
+		}
+		return path, true
+	}
+
+	switch alias {
+	case "addgene":
+		return AddgeneDB, true
+	case "igem":
+		return IGEMDB, true
+	case "dnasu":
+		return DNASUDB, true
+	}
+	return "", false
+}
+
+// FormatCost returns a cost, rounded to the cost-decimals, with the currency symbol.
+// Ex: $142.50, or -$5.00 for a negative cost.
+func (c *Config) FormatCost(cost float64) string {
+	decimals := c.CostDecimals
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	sign := ""
+	if math.Round(cost*math.Pow10(decimals)) < 0 {
+		sign = "-"
+	}
+
+	return fmt.Sprintf("%s%s%.*f", sign, c.CostCurrency, decimals, math.Abs(cost))
+}
+
+// FormatCostChange returns a change in cost like FormatCost, with a "+" if it's not negative.
+// Ex: +$7.50
+func (c *Config) FormatCostChange(change float64) string {
+	formatted := c.FormatCost(change)
+	if strings.HasPrefix(formatted, "-") {
+		return formatted
+	}
+	return "+" + formatted
+}
+
+// synthVendorNames returns the sorted names of the built-in and user-defined vendor presets
+func (c *Config) synthVendorNames() (names []string) {
+	for name := range synthVendors {
+		names = append(names, name)
+	}
+	for name := range c.SynthVendors {
+		if _, builtIn := synthVendors[name]; !builtIn {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return
+}
+
 // SynthFragmentCost returns the cost of synthesizing a linear stretch of DNA
 func (c Config) SynthFragmentCost(fragLength int) float64 {
 	// by default, we try to synthesize the whole thing in one piece
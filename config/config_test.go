@@ -1,6 +1,10 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -74,3 +78,371 @@ func TestConfig_SynthCost(t *testing.T) {
 		})
 	}
 }
+
+func Test_envName(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			"top level setting",
+			"fragments-max-count",
+			"REPP_FRAGMENTS_MAX_COUNT",
+		},
+		{
+			"nested setting",
+			"synthetic-fragment-cost.500.cost",
+			"REPP_SYNTHETIC_FRAGMENT_COST_500_COST",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envName(tt.key); got != tt.want {
+				t.Errorf("envName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_UseSynthVendor(t *testing.T) {
+	userVendor := SynthVendor{
+		MinLength:    200,
+		MaxLength:    2000,
+		FragmentCost: map[int]SynthCost{2000: SynthCost{Fixed: false, Cost: 0.06}},
+	}
+
+	tests := []struct {
+		name          string
+		vendor        string
+		wantMinLength int
+		wantMaxLength int
+		wantErr       bool
+	}{
+		{
+			"built-in vendor",
+			"twist",
+			300,
+			5000,
+			false,
+		},
+		{
+			"built-in vendor, any case",
+			"IDT",
+			125,
+			3000,
+			false,
+		},
+		{
+			"user-defined vendor",
+			"my-vendor",
+			200,
+			2000,
+			false,
+		},
+		{
+			"unknown vendor",
+			"acme",
+			100,
+			1000,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				SyntheticMinLength: 100,
+				SyntheticMaxLength: 1000,
+				SynthVendors:       map[string]SynthVendor{"my-vendor": userVendor},
+			}
+
+			if err := c.UseSynthVendor(tt.vendor); (err != nil) != tt.wantErr {
+				t.Errorf("Config.UseSynthVendor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if c.SyntheticMinLength != tt.wantMinLength || c.SyntheticMaxLength != tt.wantMaxLength {
+				t.Errorf("Config.UseSynthVendor() lengths = %d-%d, want %d-%d", c.SyntheticMinLength, c.SyntheticMaxLength, tt.wantMinLength, tt.wantMaxLength)
+			}
+		})
+	}
+}
+
+func TestConfig_ReadPrimer3Settings(t *testing.T) {
+	file, err := ioutil.TempFile("", "primer3-settings-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	settings := "Primer3 File - http://primer3.org\nP3_FILE_TYPE=settings\n\nPRIMER_OPT_TM=62.0\nPRIMER_MAX_POLY_X=4\n=\n"
+	if _, err = file.WriteString(settings); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	c := &Config{PCRPrimer3Settings: map[string]string{"PRIMER_OPT_TM": "60.0", "PRIMER_MAX_GC": "60"}}
+	if err = c.ReadPrimer3Settings(file.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"PRIMER_OPT_TM": "62.0", "PRIMER_MAX_POLY_X": "4", "PRIMER_MAX_GC": "60"}
+	if !reflect.DeepEqual(c.PCRPrimer3Settings, want) {
+		t.Errorf("Config.ReadPrimer3Settings() = %v, want %v", c.PCRPrimer3Settings, want)
+	}
+
+	if err = c.ReadPrimer3Settings(file.Name() + ".missing"); err == nil {
+		t.Error("Config.ReadPrimer3Settings() error = nil, want an error for a missing file")
+	}
+}
+
+func TestConfig_SetJunctionOverlaps(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         string
+		wantOverlaps map[string]int
+		wantErr      bool
+	}{
+		{
+			"two junctions",
+			"pSB1A3/BBa_E0040=40, BBa_E0040/pSB1A3=25",
+			map[string]int{"pSB1A3/BBa_E0040": 40, "BBa_E0040/pSB1A3": 25},
+			false,
+		},
+		{
+			"missing the right fragment",
+			"pSB1A3/=40",
+			nil,
+			true,
+		},
+		{
+			"length isn't a number",
+			"pSB1A3/BBa_E0040=long",
+			nil,
+			true,
+		},
+		{
+			"shorter than the min homology",
+			"pSB1A3/BBa_E0040=10",
+			nil,
+			true,
+		},
+		{
+			"longer than the max homology",
+			"pSB1A3/BBa_E0040=121",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{FragmentsMinHomology: 15, FragmentsMaxHomology: 120}
+
+			err := c.SetJunctionOverlaps(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetJunctionOverlaps() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for junction, wantLength := range tt.wantOverlaps {
+				ids := strings.Split(junction, "/")
+				if length, set := c.JunctionOverlap(ids[0], ids[1]); !set || length != wantLength {
+					t.Errorf("Config.JunctionOverlap(%s) = %d, %v, want %d", junction, length, set, wantLength)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_SetJunctionScars(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		wantScars map[string]string
+		wantErr   bool
+	}{
+		{
+			"two junctions",
+			"gfp/rfp=ggtggcggttca, rfp/pSB1A3=TCTAGA",
+			map[string]string{"gfp/rfp": "GGTGGCGGTTCA", "rfp/pSB1A3": "TCTAGA"},
+			false,
+		},
+		{
+			"missing the sequence",
+			"gfp/rfp=",
+			nil,
+			true,
+		},
+		{
+			"sequence isn't DNA",
+			"gfp/rfp=GGSGGS",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+
+			err := c.SetJunctionScars(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetJunctionScars() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for junction, wantScar := range tt.wantScars {
+				ids := strings.Split(junction, "/")
+				if scar, set := c.JunctionScar(ids[0], ids[1]); !set || scar != wantScar {
+					t.Errorf("Config.JunctionScar(%s) = %s, %v, want %s", junction, scar, set, wantScar)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_UseJunctionMethod(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		wantMethod      string
+		wantMinHomology int
+		wantErr         bool
+	}{
+		{
+			"default",
+			"",
+			"gibson",
+			15,
+			false,
+		},
+		{
+			"overlap-extension PCR",
+			"SOE",
+			"soe",
+			20,
+			false,
+		},
+		{
+			"unknown method",
+			"slic",
+			"",
+			15,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{FragmentsMinHomology: 15, FragmentsMaxHomology: 120, SOEMinHomology: 20}
+
+			if err := c.UseJunctionMethod(tt.method); (err != nil) != tt.wantErr {
+				t.Errorf("Config.UseJunctionMethod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if c.JunctionMethod != tt.wantMethod || c.FragmentsMinHomology != tt.wantMinHomology {
+				t.Errorf("Config.UseJunctionMethod() = %s, %d, want %s, %d", c.JunctionMethod, c.FragmentsMinHomology, tt.wantMethod, tt.wantMinHomology)
+			}
+		})
+	}
+}
+
+func TestConfig_FormatCost(t *testing.T) {
+	tests := []struct {
+		name       string
+		currency   string
+		decimals   int
+		cost       float64
+		want       string
+		wantChange string
+	}{
+		{
+			"dollars to the cent",
+			"$",
+			2,
+			142.5,
+			"$142.50",
+			"+$142.50",
+		},
+		{
+			"negative",
+			"$",
+			2,
+			-5,
+			"-$5.00",
+			"-$5.00",
+		},
+		{
+			"rounds to zero",
+			"$",
+			2,
+			-0.001,
+			"$0.00",
+			"+$0.00",
+		},
+		{
+			"euros in whole units",
+			"€",
+			0,
+			142.5,
+			"€142",
+			"+€142",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{CostCurrency: tt.currency, CostDecimals: tt.decimals}
+
+			if got := c.FormatCost(tt.cost); got != tt.want {
+				t.Errorf("Config.FormatCost() = %v, want %v", got, tt.want)
+			}
+			if got := c.FormatCostChange(tt.cost); got != tt.wantChange {
+				t.Errorf("Config.FormatCostChange() = %v, want %v", got, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestConfig_DBAlias(t *testing.T) {
+	c := &Config{DBAliases: map[string]string{"lab": "/dbs/lab_parts", "igem": "/dbs/igem_2019"}}
+
+	tests := []struct {
+		name     string
+		alias    string
+		wantPath string
+		wantSet  bool
+	}{
+		{"alias from the settings", "lab", "/dbs/lab_parts", true},
+		{"aliases aren't case sensitive", "LAB", "/dbs/lab_parts", true},
+		{"repository's database", "addgene", AddgeneDB, true},
+		{"settings take precedence over a repository", "igem", "/dbs/igem_2019", true},
+		{"not an alias", "./lab_parts", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path, set := c.DBAlias(tt.alias); path != tt.wantPath || set != tt.wantSet {
+				t.Errorf("Config.DBAlias() = %s, %v, want %s, %v", path, set, tt.wantPath, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestConfig_MonovalentConc(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          *Config
+		monovalent float64
+		primer     float64
+	}{
+		{"set", &Config{PCRMonovalentConc: 100, PCRPrimerConc: 250}, 100, 250},
+		{"unset", &Config{}, 50, 50},
+		{"negative", &Config{PCRMonovalentConc: -1, PCRPrimerConc: -1}, 50, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.MonovalentConc(); got != tt.monovalent {
+				t.Errorf("Config.MonovalentConc() = %v, want %v", got, tt.monovalent)
+			}
+			if got := tt.c.PrimerConc(); got != tt.primer {
+				t.Errorf("Config.PrimerConc() = %v, want %v", got, tt.primer)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package design is for using repp as a library, building plasmids from other Go tools
+package design
+
+import (
+	"github.com/jjtimmons/repp/internal/repp"
+)
+
+// Result is a single solution of a design, with accessors for its cost, fragments,
+// primers, synthetic fragments and junction Tms.
+type Result = repp.AssemblyResult
+
+// Fragment is a fragment of a solution: a PCR of a template, or a synthetic fragment.
+type Fragment = repp.Frag
+
+// Primer is a primer of a PCR fragment.
+type Primer = repp.Primer
+
+// Options are the inputs of a design, the library's equivalent of the flags of
+// 'repp make sequence'.
+type Options struct {
+	// In is the path to the FASTA or Genbank file of the target plasmid
+	In string
+
+	// Out is the path to write the design's JSON output to
+	Out string
+
+	// Backbone is the ID of a backbone, in the databases, to insert the target into
+	Backbone string
+
+	// Enzymes are the names of the enzymes to linearize the backbone with
+	Enzymes []string
+
+	// Filters are comma separated keywords for excluding fragments
+	Filters string
+
+	// DBs are the paths to local BLAST databases of fragments
+	DBs []string
+
+	// Addgene, IGEM and DNASU are whether to use those repositories' fragments
+	Addgene, IGEM, DNASU bool
+}
+
+// Sequence builds the target sequence with fragments from the databases. The output file is
+// written and a result is returned for each of its solutions, in the same order.
+func Sequence(opts Options) ([]Result, error) {
+	flags, conf, err := repp.DesignFlags(
+		opts.In,
+		opts.Out,
+		opts.Backbone,
+		opts.Filters,
+		opts.Enzymes,
+		opts.DBs,
+		opts.Addgene,
+		opts.IGEM,
+		opts.DNASU,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return repp.Design(flags, conf)
+}
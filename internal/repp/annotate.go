@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/jjtimmons/repp/config"
 	"github.com/spf13/cobra"
 )
 
@@ -40,12 +41,13 @@ func Annotate(cmd *cobra.Command, args []string) {
 			stderr.Fatalln("must pass a file with a plasmid sequence or the plasmid sequence as an argument.")
 		}
 
-		frags, err := read(in, false)
+		stripInvalid, _ := cmd.Flags().GetBool("strip-invalid")
+		frags, err := read(in, false, stripInvalid)
 		if err != nil {
 			stderr.Fatalln(err)
 		}
 		name = frags[0].ID
-		query = frags[0].Seq
+		query = frags[0].readSeq()
 	}
 
 	toCull, _ := cmd.Flags().GetBool("cull")
@@ -75,7 +77,7 @@ func Annotate(cmd *cobra.Command, args []string) {
 		stderr.Fatalf("failed to parse building fragments: %v", err)
 	}
 
-	dbs, err := p.parseDBs(dbString, addgene, igem, dnasu)
+	dbs, err := p.parseDBs(dbString, addgene, igem, dnasu, config.New())
 	if err != nil {
 		stderr.Fatalf("failed to find any fragment databases: %v", err)
 	}
@@ -83,8 +85,12 @@ func Annotate(cmd *cobra.Command, args []string) {
 	annotate(name, query, output, identity, dbs, excludeFilters, toCull, namesOnly)
 }
 
-// annotate is for executing blast against the query sequence.
+// annotate is for executing blast against the query sequence. The case of the
+// sequence is kept in the Genbank output.
 func annotate(name, seq, output string, identity int, dbs, filters []string, toCull, namesOnly bool) {
+	caseSeq := seq
+	seq = strings.ToUpper(seq)
+
 	handleErr := func(err error) {
 		if err != nil {
 			stderr.Fatalln(err)
@@ -157,7 +163,7 @@ func annotate(name, seq, output string, identity int, dbs, filters []string, toC
 		}
 		features = cleanedFeatures
 	} else {
-		features, err = blast(name, seq, false, dbs, filters, identity, blastWriter())
+		features, err = blast(name, seq, false, 0, dbs, filters, identity, nil, true, blastWriter())
 		handleErr(err)
 	}
 
@@ -184,7 +190,7 @@ func annotate(name, seq, output string, identity int, dbs, filters []string, toC
 		}
 		fmt.Println(strings.Join(featuresNames, ", "))
 	} else if output != "" {
-		writeGenbank(output, name, seq, []*Frag{}, features)
+		writeGenbank(output, name, caseSeq, []*Frag{}, features)
 	} else {
 		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
 		fmt.Fprintf(tw, "\nfeatures (%d)\tstart\tend\tdirection\t\n", len(features))
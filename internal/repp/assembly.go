@@ -20,6 +20,9 @@ type assembly struct {
 
 	// total number of synthetic nodes that will be needed to make this
 	synths int
+
+	// estimated length of the longest synthetic fragment in this
+	maxSynth int
 }
 
 // add Frag to the end of an assembly. Return a new assembly and whether it circularized
@@ -58,6 +61,10 @@ func (a *assembly) add(f *Frag, maxCount, targetLength int, features bool) (newA
 
 	assemblyEnd := lastEnd
 	if newCount > maxCount || (end-assemblyEnd < f.conf.PCRMinLength && !features) {
+		if newCount > maxCount && circularized {
+			detail := fmt.Sprintf("%d fragments to close the plasmid with %s, max is %d", newCount, f.ID, maxCount)
+			a.reject(newCount, a.cost, rejectFragmentCount, detail, f.conf)
+		}
 		return assembly{}, false, false
 	}
 
@@ -71,11 +78,13 @@ func (a *assembly) add(f *Frag, maxCount, targetLength int, features bool) (newA
 
 	// check whether the Frag is already contained in the assembly
 	// if so, the cost of procurement is not incurred twice
-	fragContained := false
+	fragContained, sourceContained := false, false
 	for _, included := range a.frags {
 		if included.ID == f.ID && included.fragType == f.fragType {
 			fragContained = true
-			break
+		}
+		if included.URL == f.URL {
+			sourceContained = true // another fragment from the same plasmid
 		}
 	}
 
@@ -86,6 +95,21 @@ func (a *assembly) add(f *Frag, maxCount, targetLength int, features bool) (newA
 		annealCost += f.cost(true)
 	}
 
+	if !sourceContained {
+		annealCost += f.sourceCost()
+	}
+
+	if !selfAnnealing && followsOrderHint(a.frags, f) {
+		annealCost -= f.conf.CostOrderHintBonus
+	}
+
+	maxSynth := a.maxSynth
+	if synths > 0 {
+		if synthLength := last.synthLength(f); synthLength > maxSynth {
+			maxSynth = synthLength
+		}
+	}
+
 	// copy over all the fragments, need to avoid referencing same frags
 	newFrags := []*Frag{}
 	for _, frag := range a.frags {
@@ -96,17 +120,117 @@ func (a *assembly) add(f *Frag, maxCount, targetLength int, features bool) (newA
 	}
 
 	return assembly{
-		frags:  newFrags,
-		cost:   a.cost + annealCost,
-		synths: a.synths + synths,
+		frags:    newFrags,
+		cost:     a.cost + annealCost,
+		synths:   a.synths + synths,
+		maxSynth: maxSynth,
 	}, created, circularized
 }
 
+// followsOrderHint returns whether the Frag, added after the frags, is in the
+// order of the hint's IDs: after every hinted frag before it, and not a repeat of
+// the last. The first hinted frag of an assembly is in the order.
+func followsOrderHint(frags []*Frag, f *Frag) bool {
+	if f.conf == nil || len(f.conf.OrderHint) == 0 {
+		return false
+	}
+
+	index := func(f *Frag) int {
+		for i, id := range f.conf.OrderHint {
+			if f.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	i := index(f)
+	if i < 0 {
+		return false
+	}
+
+	for j := len(frags) - 1; j >= 0; j-- {
+		if last := index(frags[j]); last >= 0 {
+			return last < i
+		}
+	}
+	return true
+}
+
 // len returns len(assembly.nodes) + the synthesis fragment count.
 func (a *assembly) len() int {
 	return len(a.frags) + a.synths
 }
 
+// turnaround returns the estimated turnaround, in days, of the assembly once it's filled.
+// Plasmids in it are expected to be PCR'd, and any gaps between them synthesized.
+func (a *assembly) turnaround(conf *config.Config) (days float64) {
+	for _, f := range a.frags {
+		leadTime := f.leadTime(conf)
+		if f.fragType == circular && a.len() > 1 {
+			leadTime += conf.LeadTimePCR
+		}
+		days = math.Max(days, leadTime)
+	}
+
+	if a.synths > 0 {
+		days = math.Max(days, conf.LeadTimeSynthesis)
+	}
+	if a.len() > 1 {
+		days += conf.LeadTimeAssembly
+	}
+
+	return
+}
+
+// score is what the search for solutions minimizes: the cost or, with --optimize time,
+// the turnaround and then the cost or, with --optimize max-synth-length, the length of
+// the longest synthetic fragment and then the cost.
+type score struct {
+	// days is the turnaround, 0 unless optimizing for time
+	days float64
+
+	// maxSynth is the length of the longest synthetic fragment, 0 unless optimizing for it
+	maxSynth int
+
+	// cost is the cost, or estimated cost, of the assembly
+	cost float64
+}
+
+// newScore returns the score of an assembly with the turnaround, longest synthetic
+// fragment and cost.
+func newScore(days float64, maxSynth int, cost float64, conf *config.Config) score {
+	if conf.Optimize != "time" {
+		days = 0
+	}
+	if conf.Optimize != "max-synth-length" {
+		maxSynth = 0
+	}
+	return score{days: days, maxSynth: maxSynth, cost: cost}
+}
+
+// less returns whether the score is better than the other.
+func (s score) less(other score) bool {
+	if s.days != other.days {
+		return s.days < other.days
+	}
+	if s.maxSynth != other.maxSynth {
+		return s.maxSynth < other.maxSynth
+	}
+	return s.cost < other.cost
+}
+
+// tied returns whether the scores have the same turnaround, longest synthetic fragment
+// and costs within a cent.
+func (s score) tied(other score) bool {
+	return s.days == other.days && s.maxSynth == other.maxSynth && math.Abs(s.cost-other.cost) < 0.01
+}
+
+// score returns the estimated score of the assembly before it's filled.
+func (a *assembly) score(conf *config.Config) score {
+	return newScore(a.turnaround(conf), a.maxSynth, a.cost, conf)
+}
+
 // log logs a description of the assembly (the entires in it and its cost).
 func (a *assembly) log() {
 	logString := ""
@@ -128,7 +252,7 @@ func (a *assembly) fill(target string, conf *config.Config) (frags []*Frag, err
 	// check for and error out if there are duplicate ends between fragments,
 	// ie unintended junctions between fragments that shouldn't be annealing
 	if hasDuplicate, left, right, dupSeq := a.duplicates(a.frags, min, max); hasDuplicate {
-		return nil, fmt.Errorf("duplicate junction between %s and %s: %s", left, right, dupSeq)
+		return nil, &rejectError{rejectDuplicateJunction, fmt.Errorf("duplicate junction between %s and %s: %s", left, right, dupSeq)}
 	}
 
 	// edge case where a single Frag fills the whole target plasmid. Return just a single
@@ -138,11 +262,13 @@ func (a *assembly) fill(target string, conf *config.Config) (frags []*Frag, err
 
 		return []*Frag{
 			&Frag{
-				ID:       f.ID,
-				Seq:      strings.ToUpper(f.Seq)[0:len(target)], // it may be longer
-				fragType: circular,
-				URL:      f.URL,
-				conf:     conf,
+				ID:        f.ID,
+				Seq:       strings.ToUpper(f.Seq)[0:len(target)], // it may be longer
+				fragType:  circular,
+				URL:       f.URL,
+				Inventory: f.Inventory,
+				InHand:    f.InHand,
+				conf:      conf,
 			},
 		}, nil
 	}
@@ -181,7 +307,7 @@ func (a *assembly) fill(target string, conf *config.Config) (frags []*Frag, err
 		// if the Frag has a full target from upload or
 		if needsPCR {
 			if err := f.setPrimers(last, next, target, conf); err != nil || len(f.Primers) < 2 {
-				return nil, fmt.Errorf("failed to pcr %s: %v", f.ID, err)
+				return nil, &rejectError{rejectReason(err, rejectPrimers), fmt.Errorf("failed to pcr %s: %v", f.ID, err)}
 			}
 			f.fragType = pcr // is now a pcr type
 		}
@@ -207,7 +333,7 @@ func (a *assembly) fill(target string, conf *config.Config) (frags []*Frag, err
 
 	// validate that fragments will anneal to one another
 	if err := validateJunctions(frags, conf); err != nil {
-		return nil, err
+		return nil, &rejectError{rejectJunction, err}
 	}
 
 	return frags, nil
@@ -285,11 +411,15 @@ func createAssemblies(frags []*Frag, target string, targetLength int, features b
 		}
 
 		// create a starting assembly for each fragment containing just it
+		cost := f.costTo(f) + f.sourceCost() // just PCR and its source
+		if followsOrderHint(nil, f) {
+			cost -= conf.CostOrderHintBonus
+		}
 		frags[i].assemblies = []assembly{
 			assembly{
 				frags:  []*Frag{f.copy()}, // just self
-				cost:   f.costTo(f),       // just PCR,
-				synths: 0,                 // no synthetic frags at start
+				cost:   cost,
+				synths: 0, // no synthetic frags at start
 			},
 		}
 	}
@@ -319,9 +449,10 @@ func createAssemblies(frags []*Frag, target string, targetLength int, features b
 	mockEnd := &Frag{start: len(target), end: len(target), conf: conf}
 	synths := mockStart.synthTo(mockEnd, target)
 	assemblies = append(assemblies, assembly{
-		frags:  synths,
-		cost:   mockStart.costTo(mockEnd),
-		synths: len(synths),
+		frags:    synths,
+		cost:     mockStart.costTo(mockEnd),
+		synths:   len(synths),
+		maxSynth: maxSynthLength(synths),
 	})
 
 	if conf.Verbose {
@@ -331,6 +462,155 @@ func createAssemblies(frags []*Frag, target string, targetLength int, features b
 	return assemblies
 }
 
+// requireFrags returns the assemblies that include every required fragment (by ID).
+// An error is returned if none of the assemblies do.
+func requireFrags(assemblies []assembly, required []string) ([]assembly, error) {
+	if len(required) == 0 {
+		return assemblies, nil
+	}
+
+	var kept []assembly
+	for _, a := range assemblies {
+		ids := make(map[string]bool)
+		for _, f := range a.frags {
+			ids[f.ID] = true
+		}
+
+		hasAll := true
+		for _, id := range required {
+			if !ids[id] {
+				hasAll = false
+				break
+			}
+		}
+
+		if hasAll {
+			kept = append(kept, a)
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no assemblies include the required fragments: %s", strings.Join(required, ", "))
+	}
+
+	return kept, nil
+}
+
+// primersPenalty returns the summed primer3 penalty of the fragments' primers.
+func primersPenalty(frags []*Frag) (penalty float64) {
+	for _, f := range frags {
+		for _, p := range f.Primers {
+			penalty += p.Penalty
+		}
+	}
+
+	return
+}
+
+// preference returns the bonuses of a filled assembly that were in its estimated cost
+// but aren't a cost of the solution: the order hint bonus of each fragment in the hint's
+// order, the weight of each fragment's database on its cost, and the bonus of each junction
+// on the boundary of a shared feature. It's added to the solution's cost when filled
+// assemblies are compared.
+func preference(frags []*Frag, targetLength int, conf *config.Config) (bonus float64) {
+	for i, f := range frags {
+		if followsOrderHint(frags[:i], f) {
+			bonus -= conf.CostOrderHintBonus
+		}
+		if weight := conf.DBWeight(f.db); weight != 1 {
+			bonus += (weight - 1) * f.cost(false)
+		}
+		if len(frags) > 1 && f.junctionFeature(nextFrag(frags, i, targetLength)) != "" {
+			bonus -= conf.CostFeatureJunctionBonus
+		}
+	}
+
+	return
+}
+
+// nextFrag returns the fragment after the one at i in a filled assembly. The one after the
+// last is the first, mocked up to the right of it across the zero index.
+func nextFrag(frags []*Frag, i, targetLength int) *Frag {
+	if i < len(frags)-1 {
+		return frags[i+1]
+	}
+
+	first := frags[0]
+	return &Frag{
+		ID:       first.ID,
+		Seq:      first.Seq,
+		start:    first.start + targetLength,
+		end:      first.end + targetLength,
+		fragType: first.fragType,
+		conf:     first.conf,
+	}
+}
+
+// hasShortFrag returns whether any of the PCR or synthetic fragments are shorter than
+// minLength. PCR fragments' lengths include the bp added by their primers.
+func hasShortFrag(frags []*Frag, minLength int) bool {
+	for _, f := range frags {
+		if f.fragType != pcr && f.fragType != synthetic {
+			continue
+		}
+
+		length := len(f.Seq)
+		if f.PCRSeq != "" {
+			length = len(f.PCRSeq)
+		}
+		if length < minLength {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shortFragWarning returns a warning if the min-fragment-length excluded a solution
+// cheaper than the cheapest one found, or "" if it didn't.
+func shortFragWarning(trace *searchTrace, solutions [][]*Frag, conf *config.Config) string {
+	if trace.shortCost == 0 || len(solutions) == 0 {
+		return ""
+	}
+
+	cheapest := cheapestCost(solutions)
+	if cheapest <= trace.shortCost {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"warning: min-fragment-length excluded a %s solution. The cheapest without short fragments is %s\n",
+		conf.FormatCost(trace.shortCost), conf.FormatCost(cheapest),
+	)
+}
+
+// synthFraction returns the fraction of the target sequence's length that is synthesized.
+func synthFraction(frags []*Frag, targetLength int) float64 {
+	if targetLength < 1 {
+		return 0
+	}
+
+	synthLength := 0
+	for _, f := range frags {
+		if f.fragType == synthetic {
+			synthLength += len(f.Seq)
+		}
+	}
+
+	return float64(synthLength) / float64(targetLength)
+}
+
+// maxSynthLength returns the length of the longest synthetic fragment, 0 if there are none.
+func maxSynthLength(frags []*Frag) (length int) {
+	for _, f := range frags {
+		if f.fragType == synthetic && len(f.Seq) > length {
+			length = len(f.Seq)
+		}
+	}
+
+	return
+}
+
 // groupAssembliesByCount returns a map from the number of fragments in a build
 // to a slice of builds with that number of fragments, sorted by their cost.
 func groupAssembliesByCount(assemblies []assembly) ([]int, map[int][]assembly) {
@@ -359,16 +639,68 @@ func groupAssembliesByCount(assemblies []assembly) ([]int, map[int][]assembly) {
 }
 
 // fillAssemblies fills in assemblies and returns the pareto optimal solutions.
+// Solutions that synthesize more than the max synthetic fraction of the target are
+// excluded, unless there are no others.
 func fillAssemblies(target string, counts []int, countToAssemblies map[int][]assembly, conf *config.Config) (solutions [][]*Frag) {
+	trace := &searchTrace{}
+	defer func() {
+		if conf.Explain {
+			fmt.Print(trace.explain(solutions, conf))
+		}
+		if warning := shortFragWarning(trace, solutions, conf); warning != "" {
+			stderr.Print(warning)
+		}
+	}()
+
+	// try the best assemblies of each count first when optimizing for time or synthesis
+	if conf.Optimize == "time" || conf.Optimize == "max-synth-length" {
+		for _, count := range counts {
+			as := countToAssemblies[count]
+			sort.SliceStable(as, func(i, j int) bool {
+				return as[i].score(conf).less(as[j].score(conf))
+			})
+		}
+	}
+
+	maxFraction := conf.SyntheticMaxFraction
+	if maxFraction <= 0 || maxFraction >= 1 {
+		return fillAssembliesUnder(target, counts, countToAssemblies, 1, conf, trace)
+	}
+
+	if solutions = fillAssembliesUnder(target, counts, countToAssemblies, maxFraction, conf, trace); len(solutions) > 0 {
+		return solutions
+	}
+
+	stderr.Printf(
+		"warning: no solutions synthesize less than %.0f%% of the plasmid. Ignoring synthetic-max-fraction\n",
+		maxFraction*100,
+	)
+	trace = &searchTrace{}
+	return fillAssembliesUnder(target, counts, countToAssemblies, 1, conf, trace)
+}
+
+// fillAssembliesUnder fills in the assemblies and returns the pareto optimal solutions
+// that synthesize, at most, maxFraction of the target sequence. The search is recorded
+// in the trace.
+func fillAssembliesUnder(target string, counts []int, countToAssemblies map[int][]assembly, maxFraction float64, conf *config.Config, trace *searchTrace) (solutions [][]*Frag) {
 	// append a fully synthetic solution at first, nothing added should cost more than this (single plasmid)
 	filled := make(map[int][]*Frag)
-	minCostAssembly := math.MaxFloat64
+	minScore := score{days: math.MaxFloat64, maxSynth: math.MaxInt32, cost: math.MaxFloat64}
 
 	for _, count := range counts {
-		for _, assemblyToFill := range countToAssemblies[count] {
-			if assemblyToFill.cost > minCostAssembly {
+		ct := countTrace{count: count, candidates: len(countToAssemblies[count])}
+		if ct.candidates > 0 {
+			ct.estimate = countToAssemblies[count][0].cost
+		}
+
+		for i, assemblyToFill := range countToAssemblies[count] {
+			if minScore.less(assemblyToFill.score(conf)) {
 				// skip this and the rest with this count, there's another
-				// cheaper option with the same number or fewer fragments (estimated)
+				// cheaper (or faster) option with the same number or fewer fragments (estimated)
+				ct.skipped = ct.candidates - i
+				for _, skipped := range countToAssemblies[count][i:] {
+					skipped.reject(count, skipped.cost, rejectCost, "estimate is above a solution with as few, or fewer, fragments", conf)
+				}
 				break
 			}
 
@@ -377,15 +709,57 @@ func fillAssemblies(target string, counts []int, countToAssemblies map[int][]ass
 				// assemblyToFill.log()
 				// fmt.Println("error", err.Error())
 				// stderr.Fatal(err)
+				detail := ""
+				if err != nil {
+					detail = err.Error()
+				}
+				assemblyToFill.reject(count, assemblyToFill.cost, rejectReason(err, rejectPrimers), detail, conf)
+				ct.failed++
 				continue
 			}
 
 			newAssemblyCost := fragsCost(filledFragments)
+			newAssemblyScore := newScore(turnaround(filledFragments, conf), maxSynthLength(filledFragments), newAssemblyCost+preference(filledFragments, len(target), conf), conf)
+			if fraction := synthFraction(filledFragments, len(target)); fraction > maxFraction {
+				detail := fmt.Sprintf("synthesizes %.0f%% of the plasmid", fraction*100)
+				assemblyToFill.reject(count, newAssemblyCost, rejectSynthFraction, detail, conf)
+				ct.synthesized++
+				continue // synthesizes too much of the plasmid
+			}
 
-			if newAssemblyCost >= minCostAssembly || len(filledFragments) > conf.FragmentsMaxCount {
+			if hasShortFrag(filledFragments, conf.FragmentsMinLength) {
+				assemblyToFill.reject(count, newAssemblyCost, rejectShort, "", conf)
+				ct.short++
+				if trace.shortCost == 0 || newAssemblyCost < trace.shortCost {
+					trace.shortCost = newAssemblyCost
+				}
+				continue // has a fragment that's too short to handle
+			}
+			ct.filled++
+			if ct.best == 0 || newAssemblyCost < ct.best {
+				ct.best = newAssemblyCost
+			}
+
+			// break ties in fragment count and cost with the primers' summed primer3 penalty
+			if existing, exists := filled[len(filledFragments)]; exists && newAssemblyScore.tied(minScore) {
+				if primersPenalty(filledFragments) < primersPenalty(existing) {
+					filled[len(filledFragments)] = filledFragments
+					trace.tiebreaks++
+				} else {
+					assemblyToFill.reject(count, newAssemblyCost, rejectPenalty, "", conf)
+				}
+				continue
+			}
+
+			if !newAssemblyScore.less(minScore) || len(filledFragments) > conf.FragmentsMaxCount {
+				reason := rejectCost
+				if len(filledFragments) > conf.FragmentsMaxCount {
+					reason = rejectFragmentCount
+				}
+				assemblyToFill.reject(count, newAssemblyCost, reason, "", conf)
 				continue // wasn't actually cheaper, keep trying
 			}
-			minCostAssembly = newAssemblyCost // store this as the new cheapest assembly
+			minScore = newAssemblyScore // store this as the new cheapest assembly
 
 			// delete all assemblies with more fragments that cost more
 			for filledCount, existingFilledFragments := range filled {
@@ -393,8 +767,8 @@ func fillAssemblies(target string, counts []int, countToAssemblies map[int][]ass
 					continue
 				}
 
-				existingCost := fragsCost(existingFilledFragments)
-				if existingCost >= newAssemblyCost {
+				existingScore := newScore(turnaround(existingFilledFragments, conf), maxSynthLength(existingFilledFragments), fragsCost(existingFilledFragments)+preference(existingFilledFragments, len(target), conf), conf)
+				if !existingScore.less(newAssemblyScore) {
 					delete(filled, filledCount)
 				}
 			}
@@ -402,6 +776,8 @@ func fillAssemblies(target string, counts []int, countToAssemblies map[int][]ass
 			// set this is as the new cheapest of this length
 			filled[len(filledFragments)] = filledFragments
 		}
+
+		trace.counts = append(trace.counts, ct)
 	}
 
 	for _, frags := range filled {
@@ -410,3 +786,117 @@ func fillAssemblies(target string, counts []int, countToAssemblies map[int][]ass
 
 	return solutions
 }
+
+// searchTrace records the search for the pareto optimal solutions in fillAssembliesUnder.
+type searchTrace struct {
+	// counts are the fragment counts of the assemblies considered, in increasing order
+	counts []countTrace
+
+	// tiebreaks is the number of solutions that replaced another with the same fragment
+	// count and cost because their primers had a lower primer3 penalty
+	tiebreaks int
+
+	// shortCost is the cost of the cheapest solution with a fragment under the
+	// min-fragment-length, 0 if there were none
+	shortCost float64
+}
+
+// countTrace records the assemblies of one estimated fragment count that were considered.
+type countTrace struct {
+	// count of fragments, including synthetic ones, estimated for the assemblies
+	count int
+
+	// candidates is the number of assemblies with this fragment count
+	candidates int
+
+	// estimate is the estimated cost of the cheapest assembly
+	estimate float64
+
+	// filled is the number of assemblies filled into solutions
+	filled int
+
+	// failed is the number of assemblies that couldn't be filled, ex: no primers
+	failed int
+
+	// synthesized is the number of assemblies that synthesized too much of the target
+	synthesized int
+
+	// short is the number of assemblies with a fragment under the min-fragment-length
+	short int
+
+	// skipped is the number of assemblies not filled because their estimated cost was
+	// above that of a solution with as few, or fewer, fragments
+	skipped int
+
+	// best is the cost of the cheapest filled assembly, 0 if none were
+	best float64
+}
+
+// explain returns a description of the search and why the cheapest solution was chosen
+// over the runner-up, the next cheapest.
+func (t *searchTrace) explain(solutions [][]*Frag, conf *config.Config) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "search:\n")
+	for _, c := range t.counts {
+		fmt.Fprintf(
+			&sb,
+			"  %d fragments: %d assemblies, cheapest estimate %s, %d filled, %d failed, %d over synthetic-max-fraction, %d skipped",
+			c.count, c.candidates, conf.FormatCost(c.estimate), c.filled, c.failed, c.synthesized, c.skipped,
+		)
+		if c.short > 0 {
+			fmt.Fprintf(&sb, ", %d under min-fragment-length", c.short)
+		}
+		if c.filled > 0 {
+			fmt.Fprintf(&sb, ", best %s", conf.FormatCost(c.best))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(solutions) == 0 {
+		sb.WriteString("no solutions were found\n")
+		return sb.String()
+	}
+
+	sorted := append([][]*Frag{}, solutions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if fragsCost(sorted[i]) == fragsCost(sorted[j]) {
+			return len(sorted[i]) < len(sorted[j])
+		}
+		return fragsCost(sorted[i]) < fragsCost(sorted[j])
+	})
+
+	chosen := sorted[0]
+	chosenCost := fragsCost(chosen)
+	fmt.Fprintf(&sb, "chosen: %d fragments, %s\n", len(chosen), conf.FormatCost(chosenCost))
+
+	if len(sorted) < 2 {
+		sb.WriteString("runner-up: none, no other solution was cheaper for its fragment count\n")
+	} else {
+		runnerUp := sorted[1]
+		runnerUpCost := fragsCost(runnerUp)
+		fmt.Fprintf(
+			&sb,
+			"runner-up: %d fragments, %s (%s)\n",
+			len(runnerUp), conf.FormatCost(runnerUpCost), conf.FormatCostChange(runnerUpCost-chosenCost),
+		)
+
+		tied := math.Abs(runnerUpCost-chosenCost) < 0.01
+		switch {
+		case tied && len(chosen) == len(runnerUp) && primersPenalty(chosen) < primersPenalty(runnerUp):
+			sb.WriteString("decided by: tiebreaker, the chosen solution's primers have a lower primer3 penalty\n")
+		case tied && len(chosen) < len(runnerUp):
+			sb.WriteString("decided by: fewest fragments, the solutions cost the same and the chosen solution has fewer fragments\n")
+		case len(chosen) <= len(runnerUp):
+			sb.WriteString("decided by: fewest fragments and cost, the chosen solution has as few fragments and is cheaper\n")
+		default:
+			sb.WriteString("decided by: cost, the chosen solution has more fragments but is cheaper\n")
+		}
+	}
+
+	if t.tiebreaks > 0 {
+		fmt.Fprintf(&sb, "%d ties in fragment count and cost were broken by primer3 penalty\n", t.tiebreaks)
+	}
+
+	return sb.String()
+}
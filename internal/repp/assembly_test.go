@@ -2,6 +2,7 @@ package repp
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jjtimmons/repp/config"
@@ -95,9 +96,10 @@ func Test_assembly_add(t *testing.T) {
 				n: n3,
 			},
 			assembly{
-				frags:  []*Frag{n1, n3},
-				cost:   10.0 + n1.costTo(n3),
-				synths: 1,
+				frags:    []*Frag{n1, n3},
+				cost:     10.0 + n1.costTo(n3),
+				synths:   1,
+				maxSynth: 125, // the synthetic-min-length
 			},
 			true,
 			true,
@@ -137,9 +139,10 @@ func Test_assembly_add(t *testing.T) {
 				},
 			},
 			assembly{
-				frags:  []*Frag{n1, n2},
-				cost:   16.4,
-				synths: 1,
+				frags:    []*Frag{n1, n2},
+				cost:     16.4,
+				synths:   1,
+				maxSynth: 125, // the synthetic-min-length
 			},
 			true,
 			true,
@@ -318,6 +321,165 @@ func Test_countMaps(t *testing.T) {
 	}
 }
 
+func Test_requireFrags(t *testing.T) {
+	a1 := assembly{
+		frags: []*Frag{&Frag{ID: "pSB1C3"}, &Frag{ID: "GFP"}},
+	}
+	a2 := assembly{
+		frags: []*Frag{&Frag{ID: "pSB1A3"}, &Frag{ID: "GFP"}},
+	}
+
+	type args struct {
+		assemblies []assembly
+		required   []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []assembly
+		wantErr bool
+	}{
+		{
+			"no required fragments",
+			args{
+				assemblies: []assembly{a1, a2},
+			},
+			[]assembly{a1, a2},
+			false,
+		},
+		{
+			"keep assemblies with the required fragment",
+			args{
+				assemblies: []assembly{a1, a2},
+				required:   []string{"pSB1C3"},
+			},
+			[]assembly{a1},
+			false,
+		},
+		{
+			"error if no assembly has every required fragment",
+			args{
+				assemblies: []assembly{a1, a2},
+				required:   []string{"pSB1C3", "pSB1A3"},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := requireFrags(tt.args.assemblies, tt.args.required)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("requireFrags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requireFrags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_followsOrderHint(t *testing.T) {
+	c := &config.Config{OrderHint: []string{"p1", "p2", "p3"}}
+	frag := func(id string) *Frag { return &Frag{ID: id, conf: c} }
+
+	tests := []struct {
+		name  string
+		frags []*Frag
+		f     *Frag
+		want  bool
+	}{
+		{"first hinted frag", []*Frag{frag("x")}, frag("p2"), true},
+		{"after an earlier hinted frag", []*Frag{frag("p1"), frag("x")}, frag("p3"), true},
+		{"before an earlier hinted frag", []*Frag{frag("p3")}, frag("p2"), false},
+		{"repeat of the last hinted frag", []*Frag{frag("p2")}, frag("p2"), false},
+		{"not in the hint", []*Frag{frag("p1")}, frag("x"), false},
+		{"no hint", []*Frag{}, &Frag{ID: "p1", conf: &config.Config{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := followsOrderHint(tt.frags, tt.f); got != tt.want {
+				t.Errorf("followsOrderHint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_preference(t *testing.T) {
+	c := &config.Config{
+		OrderHint:          []string{"p1", "p2"},
+		CostOrderHintBonus: 5,
+		DBWeights:          map[string]float64{"lab": 0.5},
+		CostPCR:            20,
+
+		FeatureJunctions:         []config.Range{{Start: 10, End: 29, Name: "lacO", Seq: "AATTGTGAGCGGATAACAAT"}},
+		CostFeatureJunctionBonus: 2,
+	}
+	frag := func(id string) *Frag { return &Frag{ID: id, conf: c} }
+	lab := &Frag{ID: "x", db: "/dbs/lab.fa", fragType: pcr, Primers: []Primer{Primer{}, Primer{}}, conf: c}
+	lacO := &Frag{ID: "a", Seq: "GGGGGGGGGGAATTGTGAGCGGATAACAATGG", start: 0, end: 31, conf: c}
+	next := &Frag{ID: "b", Seq: "GGCCCCCCCCCCCCC", start: 25, end: 59, conf: c}
+
+	tests := []struct {
+		name  string
+		frags []*Frag
+		want  float64
+	}{
+		{"in the hint's order", []*Frag{frag("p1"), frag("x"), frag("p2")}, -10},
+		{"out of the hint's order", []*Frag{frag("p2"), frag("p1")}, -5},
+		{"not in the hint", []*Frag{frag("x"), frag("y")}, 0},
+		{"from a weighted database", []*Frag{lab, frag("y")}, -10},
+		{"a junction on a shared feature's boundary", []*Frag{lacO, next}, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preference(tt.frags, 60, c); got != tt.want {
+				t.Errorf("preference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_synthFraction(t *testing.T) {
+	type args struct {
+		frags        []*Frag
+		targetLength int
+	}
+	tests := []struct {
+		name string
+		args args
+		want float64
+	}{
+		{
+			"no synthetic fragments",
+			args{
+				[]*Frag{&Frag{Seq: "ATGCATGCAT", fragType: pcr}},
+				10,
+			},
+			0,
+		},
+		{
+			"half synthesized",
+			args{
+				[]*Frag{
+					&Frag{Seq: "ATGCA", fragType: pcr},
+					&Frag{Seq: "TGCAT", fragType: synthetic},
+				},
+				10,
+			},
+			0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := synthFraction(tt.args.frags, tt.args.targetLength); got != tt.want {
+				t.Errorf("synthFraction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_assembly_duplicates(t *testing.T) {
 	type fields struct {
 		frags  []*Frag
@@ -453,3 +615,163 @@ func Test_assembly_duplicates(t *testing.T) {
 		})
 	}
 }
+
+func Test_searchTrace_explain(t *testing.T) {
+	conf := &config.Config{CostAddgene: 50, CostCurrency: "$", CostDecimals: 2}
+	plasmid := func() *Frag { return &Frag{URL: "https://www.addgene.org/1", fragType: linear, conf: conf} }
+	one := []*Frag{plasmid()}
+	two := []*Frag{plasmid(), plasmid()}
+	twoAsCheap := []*Frag{plasmid(), &Frag{fragType: linear, conf: conf}}
+
+	trace := &searchTrace{
+		counts: []countTrace{
+			countTrace{count: 1, candidates: 1, estimate: 50, filled: 1, best: 50},
+			countTrace{count: 2, candidates: 3, estimate: 100, failed: 1, filled: 1, skipped: 1, best: 100},
+		},
+	}
+	search := "search:\n" +
+		"  1 fragments: 1 assemblies, cheapest estimate $50.00, 1 filled, 0 failed, 0 over synthetic-max-fraction, 0 skipped, best $50.00\n" +
+		"  2 fragments: 3 assemblies, cheapest estimate $100.00, 1 filled, 1 failed, 0 over synthetic-max-fraction, 1 skipped, best $100.00\n"
+
+	tests := []struct {
+		name      string
+		solutions [][]*Frag
+		want      string
+	}{
+		{
+			"fewer fragments and cheaper",
+			[][]*Frag{two, one},
+			search +
+				"chosen: 1 fragments, $50.00\n" +
+				"runner-up: 2 fragments, $100.00 (+$50.00)\n" +
+				"decided by: fewest fragments and cost, the chosen solution has as few fragments and is cheaper\n",
+		},
+		{
+			"as cheap with fewer fragments isn't a tiebreak",
+			[][]*Frag{twoAsCheap, one},
+			search +
+				"chosen: 1 fragments, $50.00\n" +
+				"runner-up: 2 fragments, $50.00 (+$0.00)\n" +
+				"decided by: fewest fragments, the solutions cost the same and the chosen solution has fewer fragments\n",
+		},
+		{
+			"no runner-up",
+			[][]*Frag{one},
+			search +
+				"chosen: 1 fragments, $50.00\n" +
+				"runner-up: none, no other solution was cheaper for its fragment count\n",
+		},
+		{
+			"no solutions",
+			nil,
+			search + "no solutions were found\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trace.explain(tt.solutions, conf); got != tt.want {
+				t.Errorf("searchTrace.explain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_maxSynthLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		frags []*Frag
+		want  int
+	}{
+		{
+			"no synthetic fragments",
+			[]*Frag{&Frag{Seq: "ATGCATGCATGCATGC", fragType: pcr}},
+			0,
+		},
+		{
+			"longest synthetic fragment",
+			[]*Frag{
+				&Frag{Seq: "ATGCATGCATGCATGC", fragType: pcr},
+				&Frag{Seq: "TGCAT", fragType: synthetic},
+				&Frag{Seq: "ATGCATGC", fragType: synthetic},
+			},
+			8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxSynthLength(tt.frags); got != tt.want {
+				t.Errorf("maxSynthLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hasShortFrag(t *testing.T) {
+	tests := []struct {
+		name  string
+		frags []*Frag
+		want  bool
+	}{
+		{
+			"no short fragments",
+			[]*Frag{
+				&Frag{Seq: strings.Repeat("A", 50), fragType: synthetic},
+				&Frag{Seq: strings.Repeat("A", 30), PCRSeq: strings.Repeat("A", 60), fragType: pcr},
+			},
+			false,
+		},
+		{
+			"short synthetic fragment",
+			[]*Frag{&Frag{Seq: strings.Repeat("A", 40), fragType: synthetic}},
+			true,
+		},
+		{
+			"short plasmid isn't a PCR or synthetic fragment",
+			[]*Frag{&Frag{Seq: strings.Repeat("A", 40), fragType: linear}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasShortFrag(tt.frags, 50); got != tt.want {
+				t.Errorf("hasShortFrag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_score_less(t *testing.T) {
+	cheap := assembly{frags: []*Frag{{URL: "https://www.addgene.org/85472/", fragType: circular}, {fragType: linear}}, synths: 1, maxSynth: 1800, cost: 80}
+	fast := assembly{frags: []*Frag{{fragType: linear}}, synths: 1, maxSynth: 400, cost: 200}
+	conf := &config.Config{LeadTimeAddgene: 14, LeadTimeSynthesis: 7, LeadTimePCR: 2, LeadTimeAssembly: 1}
+
+	tests := []struct {
+		name     string
+		optimize string
+		want     bool
+	}{
+		{
+			"cheaper when optimizing cost",
+			"cost",
+			true,
+		},
+		{
+			"slower when optimizing time",
+			"time",
+			false,
+		},
+		{
+			"longer synthetic fragment when optimizing max-synth-length",
+			"max-synth-length",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.Optimize = tt.optimize
+			if got := cheap.score(conf).less(fast.score(conf)); got != tt.want {
+				t.Errorf("score.less() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// biobrickPrefix is the RFC10 prefix upstream of a part: EcoRI, NotI and XbaI
+	biobrickPrefix = "GAATTCGCGGCCGCTTCTAGAG"
+
+	// biobrickCDSPrefix is the RFC10 prefix upstream of a coding part starting with ATG
+	biobrickCDSPrefix = "GAATTCGCGGCCGCTTCTAG"
+
+	// biobrickSuffix is the RFC10 suffix downstream of a part: SpeI, NotI and PstI
+	biobrickSuffix = "TACTAGTAGCGGCCGCTGCAG"
+
+	// biobrickScar is the mixed XbaI/SpeI site left between two parts by 3A assembly
+	biobrickScar = "TACTAGAG"
+
+	// biobrickCDSScar is the scar upstream of a coding part starting with ATG
+	biobrickCDSScar = "TACTAG"
+)
+
+// biobrickSites are the RFC10 enzymes' sites that can't be in a part. Each is
+// its own reverse complement.
+var biobrickSites = []struct {
+	name, site string
+}{
+	{"EcoRI", "GAATTC"},
+	{"XbaI", "TCTAGA"},
+	{"SpeI", "ACTAGT"},
+	{"PstI", "CTGCAG"},
+	{"NotI", "GCGGCCGC"},
+}
+
+// BiobrickOutput is the composite part, and the 3A assembly to make it, of 'repp make biobrick'
+type BiobrickOutput struct {
+	// Target is the composite part's name, its parts' IDs joined by "+"
+	Target string `json:"target"`
+
+	// Seq is the composite part with the prefix and suffix, followed by the backbone if there's one
+	Seq string `json:"seq"`
+
+	// Backbone is the ID of the backbone the composite part is cloned into
+	Backbone string `json:"backbone,omitempty"`
+
+	// Parts are the composite's parts in order
+	Parts []BiobrickPart `json:"parts"`
+
+	// Steps are the 3A assemblies, in rounds, that make the composite part
+	Steps []BiobrickStep `json:"steps"`
+
+	// Time is when the output was written
+	Time string `json:"time"`
+
+	// Build is the build of repp that wrote the output
+	Build BuildInfo `json:"build"`
+}
+
+// BiobrickPart is a part of the composite without the prefix and suffix.
+type BiobrickPart struct {
+	// ID is the part's name, ex: "BBa_B0034"
+	ID string `json:"id"`
+
+	// URL is the part's page in its repository
+	URL string `json:"url,omitempty"`
+
+	// Start is the 1-based index of the part's first bp in the output's seq
+	Start int `json:"start"`
+
+	// End is the 1-based index of the part's last bp in the output's seq
+	End int `json:"end"`
+
+	// Scar is the scar between the part and the next part, empty for the last part
+	Scar string `json:"scar,omitempty"`
+}
+
+// BiobrickStep is a 3A assembly of two parts, or composites of parts. The upstream plasmid
+// is cut with EcoRI and SpeI, the downstream with XbaI and PstI, and both are ligated into
+// a destination backbone cut with EcoRI and PstI.
+type BiobrickStep struct {
+	// Round is the 1-based round of assemblies the step is in. Steps in a round are independent
+	Round int `json:"round"`
+
+	// Upstream is the part, or composite from an earlier round, that's upstream in the product
+	Upstream string `json:"upstream"`
+
+	// Downstream is the part, or composite from an earlier round, that's downstream in the product
+	Downstream string `json:"downstream"`
+
+	// Product is the composite made by the step
+	Product string `json:"product"`
+}
+
+// BiobrickCmd composes the parts passed as arguments into a composite BioBrick part and
+// writes the 3A assembly steps to make it.
+func BiobrickCmd(cmd *cobra.Command, args []string) {
+	p := &inputParser{}
+
+	partIDs := p.parseCommaList(p.parseFeatureInput(args))
+	if len(partIDs) == 0 {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass the parts to compose.")
+	}
+
+	dbList, _ := cmd.Flags().GetString("dbs")
+	addgene, _ := cmd.Flags().GetBool("addgene")
+	igem, _ := cmd.Flags().GetBool("igem")
+	dnasu, _ := cmd.Flags().GetBool("dnasu")
+	dbs, err := p.parseDBs(dbList, addgene, igem, dnasu, config.New())
+	if err != nil {
+		stderr.Fatal(err)
+	}
+
+	parts := make([]*Frag, len(partIDs))
+	for i, id := range partIDs {
+		if parts[i], err = queryBiobrick(id, dbs); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	var backbone *Frag
+	if bbName, _ := cmd.Flags().GetString("backbone"); bbName != "" {
+		if backbone, err = queryBiobrick(bbName, dbs); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	output, err := biobrick(parts, backbone)
+	if err != nil {
+		stderr.Fatal(err)
+	}
+
+	out, _ := cmd.Flags().GetString("out")
+	if out == "" {
+		out = output.Target + ".biobrick.json" // not *.output.json, it isn't the output's schema
+	}
+
+	output.Time = time.Now().Format("2006/01/02 15:04:05")
+	output.Build = Build()
+	outputJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		stderr.Fatalf("failed to serialize output: %v", err)
+	}
+	if err = ioutil.WriteFile(out, outputJSON, 0666); err != nil {
+		stderr.Fatalf("failed to write the output: %v", err)
+	}
+}
+
+// queryBiobrick returns the part or backbone with the entry name from a local file or one of the dbs.
+func queryBiobrick(entry string, dbs []string) (*Frag, error) {
+	f, err := queryDatabases(entry, dbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s: %v", entry, err)
+	}
+	if f.fragType == circular {
+		f.Seq = f.Seq[:len(f.Seq)/2]
+	}
+	f.Seq = strings.ToUpper(f.Seq)
+	f.URL = parseURL(f.ID, f.db)
+	return f, nil
+}
+
+// biobrick composes the parts, in order, into a composite part with a scar at each
+// junction and plans the 3A assembly of it. Parts' prefixes and suffixes are removed
+// if they're included. It's an error if a part has a site of an RFC10 enzyme. If there's
+// a backbone, the composite replaces the backbone's insert between its EcoRI and PstI sites.
+func biobrick(parts []*Frag, backbone *Frag) (*BiobrickOutput, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no parts to compose")
+	}
+
+	seqs := make([]string, len(parts))
+	var siteErrs []string
+	for i, part := range parts {
+		seqs[i] = biobrickTrim(part.Seq)
+		for _, site := range biobrickSiteIndexes(seqs[i]) {
+			siteErrs = append(siteErrs, fmt.Sprintf("%s has %s", part.ID, site))
+		}
+	}
+	if len(siteErrs) > 0 {
+		return nil, fmt.Errorf("parts have sites of the RFC10 enzymes: %s", strings.Join(siteErrs, ", "))
+	}
+
+	output := &BiobrickOutput{}
+	var sb strings.Builder
+	if strings.HasPrefix(seqs[0], "ATG") {
+		sb.WriteString(biobrickCDSPrefix)
+	} else {
+		sb.WriteString(biobrickPrefix)
+	}
+
+	ids := make([]string, len(parts))
+	for i, part := range parts {
+		ids[i] = part.ID
+		bp := BiobrickPart{ID: part.ID, URL: part.URL, Start: sb.Len() + 1}
+		sb.WriteString(seqs[i])
+		bp.End = sb.Len()
+
+		if i < len(parts)-1 {
+			bp.Scar = biobrickScar
+			if strings.HasPrefix(seqs[i+1], "ATG") {
+				bp.Scar = biobrickCDSScar
+			}
+			sb.WriteString(bp.Scar)
+		}
+		output.Parts = append(output.Parts, bp)
+	}
+	sb.WriteString(biobrickSuffix)
+
+	if backbone != nil {
+		vector, err := biobrickVector(backbone)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(vector)
+		output.Backbone = backbone.ID
+	}
+
+	output.Target = strings.Join(ids, "+")
+	output.Seq = sb.String()
+	output.Steps = biobrickSteps(ids)
+	return output, nil
+}
+
+// biobrickTrim removes the RFC10 prefix and suffix from a part, if they're included.
+func biobrickTrim(seq string) string {
+	seq = strings.ToUpper(seq)
+	if strings.HasPrefix(seq, biobrickPrefix) {
+		seq = seq[len(biobrickPrefix):]
+	} else if strings.HasPrefix(seq, biobrickCDSPrefix+"ATG") {
+		seq = seq[len(biobrickCDSPrefix):]
+	}
+	return strings.TrimSuffix(seq, biobrickSuffix)
+}
+
+// biobrickSiteIndexes returns the RFC10 enzymes' sites in the sequence with their 1-based indexes.
+func biobrickSiteIndexes(seq string) (sites []string) {
+	for _, s := range biobrickSites {
+		for i := 0; i+len(s.site) <= len(seq); i++ {
+			if seq[i:i+len(s.site)] == s.site {
+				sites = append(sites, fmt.Sprintf("%s at bp %d", s.name, i+1))
+			}
+		}
+	}
+	return
+}
+
+// biobrickVector returns the backbone without its insert: from after its PstI site
+// to before its EcoRI site. It's an error if the backbone doesn't have exactly one of each.
+func biobrickVector(backbone *Frag) (string, error) {
+	seq := strings.ToUpper(backbone.Seq)
+	ecoRI, pstI := strings.Index(seq, "GAATTC"), strings.Index(seq, "CTGCAG")
+	if strings.Count(seq, "GAATTC") != 1 || strings.Count(seq, "CTGCAG") != 1 {
+		return "", fmt.Errorf("backbone %s must have one EcoRI and one PstI site to clone the composite into", backbone.ID)
+	}
+
+	start, end := pstI+len("CTGCAG"), ecoRI
+	if end < start {
+		end += len(seq)
+	}
+	return (seq + seq)[start:end], nil
+}
+
+// biobrickSteps plans the 3A assemblies of the parts in rounds. Each round joins adjacent
+// pairs of the parts, or of the composites from the last round, so the number of rounds
+// is the log2 of the number of parts.
+func biobrickSteps(ids []string) (steps []BiobrickStep) {
+	for round := 1; len(ids) > 1; round++ {
+		var products []string
+		for i := 0; i < len(ids); i += 2 {
+			if i+1 == len(ids) {
+				products = append(products, ids[i]) // carried to the next round
+				continue
+			}
+
+			product := ids[i] + "+" + ids[i+1]
+			steps = append(steps, BiobrickStep{
+				Round:      round,
+				Upstream:   ids[i],
+				Downstream: ids[i+1],
+				Product:    product,
+			})
+			products = append(products, product)
+		}
+		ids = products
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package repp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_biobrick(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []*Frag
+		backbone *Frag
+		wantSeq  string
+		wantErr  bool
+	}{
+		{
+			"scars between parts",
+			[]*Frag{{ID: "rbs", Seq: "aaagag"}, {ID: "term", Seq: "CCAGGC"}},
+			nil,
+			biobrickPrefix + "AAAGAG" + biobrickScar + "CCAGGC" + biobrickSuffix,
+			false,
+		},
+		{
+			"short scar and prefix before coding parts",
+			[]*Frag{{ID: "gfp", Seq: "ATGCGT"}, {ID: "rfp", Seq: "ATGGCA"}},
+			nil,
+			biobrickCDSPrefix + "ATGCGT" + biobrickCDSScar + "ATGGCA" + biobrickSuffix,
+			false,
+		},
+		{
+			"prefix and suffix of parts are removed",
+			[]*Frag{{ID: "rbs", Seq: biobrickPrefix + "AAAGAG" + biobrickSuffix}, {ID: "gfp", Seq: biobrickCDSPrefix + "ATGCGT" + biobrickSuffix}},
+			nil,
+			biobrickPrefix + "AAAGAG" + biobrickCDSScar + "ATGCGT" + biobrickSuffix,
+			false,
+		},
+		{
+			"backbone's insert is replaced",
+			[]*Frag{{ID: "rbs", Seq: "AAAGAG"}},
+			&Frag{ID: "pSB1C3", Seq: "CCCCGAATTCTTTTCTGCAGGGGG"},
+			biobrickPrefix + "AAAGAG" + biobrickSuffix + "GGGGCCCC",
+			false,
+		},
+		{
+			"part with an internal XbaI site",
+			[]*Frag{{ID: "rbs", Seq: "AAAGAG"}, {ID: "term", Seq: "CCTCTAGAGG"}},
+			nil,
+			"",
+			true,
+		},
+		{
+			"backbone without a PstI site",
+			[]*Frag{{ID: "rbs", Seq: "AAAGAG"}},
+			&Frag{ID: "pSB1C3", Seq: "CCCCGAATTCTTTTGGGGG"},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := biobrick(tt.parts, tt.backbone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("biobrick() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Seq != tt.wantSeq {
+				t.Errorf("biobrick() seq = %v, want %v", got.Seq, tt.wantSeq)
+			}
+			for _, p := range got.Parts {
+				if part := got.Seq[p.Start-1 : p.End]; len(part) != 6 {
+					t.Errorf("biobrick() part %s = %v, want 6 bp", p.ID, part)
+				}
+			}
+		})
+	}
+}
+
+func Test_biobrickSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []BiobrickStep
+	}{
+		{
+			"single part",
+			[]string{"A"},
+			nil,
+		},
+		{
+			"three parts in two rounds",
+			[]string{"A", "B", "C"},
+			[]BiobrickStep{
+				{Round: 1, Upstream: "A", Downstream: "B", Product: "A+B"},
+				{Round: 2, Upstream: "A+B", Downstream: "C", Product: "A+B+C"},
+			},
+		},
+		{
+			"four parts in two rounds",
+			[]string{"A", "B", "C", "D"},
+			[]BiobrickStep{
+				{Round: 1, Upstream: "A", Downstream: "B", Product: "A+B"},
+				{Round: 1, Upstream: "C", Downstream: "D", Product: "C+D"},
+				{Round: 2, Upstream: "A+B", Downstream: "C+D", Product: "A+B+C+D"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := biobrickSteps(tt.ids); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("biobrickSteps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,17 +1,23 @@
 package repp
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
 )
 
 // mismatchResults is a map from primer key to mismatch check results
@@ -60,6 +66,12 @@ type match struct {
 
 	// forward if the match is along the sequence strand versus the reverse complement strand
 	forward bool
+
+	// inventory if the match is from one of the user's inventory databases
+	inventory bool
+
+	// inHand if the match is from a fragment the user already has (--have)
+	inHand bool
 }
 
 // blastExec is a small utility object for executing BLAST.
@@ -73,6 +85,9 @@ type blastExec struct {
 	// whether to circularize the queries sequence in the input file
 	circular bool
 
+	// bp of a circular query's start to add again after its end, all of it if 0
+	padding int
+
 	// the path to the database we're BLASTing against
 	db string
 
@@ -96,6 +111,10 @@ type blastExec struct {
 
 	// the expect value of a BLAST query (defaults to 10)
 	evalue int
+
+	// whether to mask low-complexity regions of the query with DUST: "yes", "no",
+	// or blastn's default if empty
+	dust string
 }
 
 // mismatchResults are the results of a seqMismatch check. saved
@@ -132,6 +151,8 @@ func (m *match) copyWithQueryRange(start, end int) match {
 		circular:     m.circular,
 		mismatching:  m.mismatching,
 		internal:     m.internal,
+		inventory:    m.inventory,
+		inHand:       m.inHand,
 	}
 }
 
@@ -140,12 +161,17 @@ func (m *match) log() {
 	fmt.Printf("%s %d %d\n", m.entry, m.queryStart, m.queryEnd)
 }
 
-// blast the seq against all dbs and acculate matches.
+// blast the seq against all dbs and acculate matches. Each db is BLAST'ed at its
+// identity in dbIdentity, if it has one, or the identity otherwise. A circular seq is
+// padded with the padding bp of its start, or all of it if 0.
 func blast(
 	name, seq string,
 	circular bool,
+	padding int,
 	dbs, filters []string,
 	identity int,
+	dbIdentity map[string]int,
+	dust bool,
 	tw *tabwriter.Writer,
 ) ([]match, error) {
 	in, err := ioutil.TempFile("", "blast-in-*")
@@ -171,11 +197,16 @@ func blast(
 			name:     name,
 			seq:      seq,
 			circular: circular,
+			padding:  padding,
 			db:       db,
 			in:       in,
 			out:      out,
 			internal: internal,
-			identity: identity,
+			identity: identityOf(db, identity, dbIdentity),
+			dust:     "yes",
+		}
+		if !dust {
+			b.dust = "no"
 		}
 
 		// make sure the db exists
@@ -208,6 +239,14 @@ func blast(
 	return matches, nil
 }
 
+// identityOf returns the db's identity in dbIdentity, or the identity if it has none.
+func identityOf(db string, identity int, dbIdentity map[string]int) int {
+	if dbIdentity, set := dbIdentity[db]; set {
+		return dbIdentity
+	}
+	return identity
+}
+
 // blast an entry against a pre-made subject database
 func blastAgainst(
 	name, seq, subject string,
@@ -277,11 +316,11 @@ func blastWriter() *tabwriter.Writer {
 // input creates an input query file (FASTA) for blastn.
 func (b *blastExec) input() error {
 	// create the query sequence file.
-	// if circular, add the sequence to itself because it's circular
+	// if circular, add the start of the sequence to its end because it's circular
 	// and we want to find matches across the zero-index
 	querySeq := b.seq
 	if b.circular {
-		querySeq = querySeq + b.seq
+		querySeq = querySeq + b.seq[:circularPadding(len(b.seq), b.padding)]
 	}
 
 	_, err := b.in.WriteString(fmt.Sprintf(">%s\n%s\n", b.name, querySeq))
@@ -289,6 +328,16 @@ func (b *blastExec) input() error {
 	return err
 }
 
+// circularPadding returns the bp of a circular query's start to BLAST again after its end.
+// Matches across the zero index are found up to that many bp past it. It's the whole
+// query, so any match across the zero index is found, if the padding is 0 or longer.
+func circularPadding(seqLength, padding int) int {
+	if padding <= 0 || padding > seqLength {
+		return seqLength
+	}
+	return padding
+}
+
 // run calls the external blastn binary on the input file.
 func (b *blastExec) run() (err error) {
 	threads := runtime.NumCPU() - 1
@@ -341,6 +390,10 @@ func (b *blastExec) run() (err error) {
 		)
 	}
 
+	if b.dust != "" {
+		flags = append(flags, "-dust", b.dust)
+	}
+
 	if b.evalue != 0 {
 		flags = append(flags, "-evalue", strconv.Itoa(b.evalue))
 	} else if b.identity < 90 {
@@ -350,14 +403,61 @@ func (b *blastExec) run() (err error) {
 	}
 
 	// https://www.ncbi.nlm.nih.gov/books/NBK279682/
-	blastCmd := exec.Command("blastn", flags...)
+	return runBlastn(flags, b.db)
+}
 
-	// execute BLAST and wait on it to finish
-	if output, err := blastCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to execute blastn against %s: %v: %s", b.db, err, string(output))
+// blastnRetries is the number of times blastn is retried after a transient failure.
+const blastnRetries = 2
+
+// runBlastn executes blastn and waits on it to finish. It's retried, with backoff, after
+// transient failures like running out of memory.
+func runBlastn(flags []string, against string) error {
+	if _, err := exec.LookPath("blastn"); err != nil {
+		return fmt.Errorf("failed to find a blastn executable in PATH, try `make install`: %v", err)
 	}
 
-	return
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		output, err := exec.Command("blastn", flags...).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+
+		blastErr, transient := blastnError(against, err, string(output))
+		if !transient || attempt >= blastnRetries {
+			return blastErr
+		}
+
+		stderr.Printf("warning: %v. retrying in %s\n", blastErr, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// blastnError wraps a blastn failure with its output and returns whether it's transient.
+// Invalid databases are distinguished from failures while blastn is running.
+func blastnError(against string, err error, output string) (blastErr error, transient bool) {
+	output = strings.TrimSpace(output)
+	lowerOutput := strings.ToLower(output)
+
+	for _, invalid := range []string{"blast database error", "no alias or index file found", "could not find volume or alias file"} {
+		if strings.Contains(lowerOutput, invalid) {
+			return fmt.Errorf("invalid BLAST database %s: %s", against, output), false
+		}
+	}
+
+	for _, temporary := range []string{"resource temporarily unavailable", "cannot allocate memory", "bad_alloc", "out of memory"} {
+		if strings.Contains(lowerOutput, temporary) {
+			return fmt.Errorf("blastn ran out of resources against %s: %v: %s", against, err, output), true
+		}
+	}
+
+	// killed, eg by the OOM killer
+	if strings.Contains(err.Error(), "signal: killed") {
+		return fmt.Errorf("blastn was killed running against %s: %v: %s", against, err, output), true
+	}
+
+	return fmt.Errorf("failed to execute blastn against %s: %v: %s", against, err, output), false
 }
 
 // parse reads the output of blastn into matches.
@@ -532,6 +632,36 @@ func cull(matches []match, targetLength, minSize, limit int) (culled []match) {
 	return culled
 }
 
+// removeWrapped removes matches that are part of another match of the same entry that
+// spans the target's zero index. BLAST finds the part of a spanning match that's at the
+// start of the target, from the first copy of the doubled query, as a separate match.
+// It would become another node for the same stretch of the fragment, that could be
+// used twice in one assembly.
+func removeWrapped(matches []match, targetLength int) (kept []match) {
+	spanning := make(map[string][]match)
+	for _, m := range matches {
+		if m.queryStart < targetLength && m.queryEnd >= targetLength {
+			spanning[m.entry] = append(spanning[m.entry], m)
+		}
+	}
+
+	for _, m := range matches {
+		wrapped := false
+		for _, s := range spanning[m.entry] {
+			if m.queryStart+targetLength >= s.queryStart && m.queryEnd+targetLength <= s.queryEnd {
+				wrapped = true // m is the end of s, across the zero index
+				break
+			}
+		}
+
+		if !wrapped {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept
+}
+
 // properize remove matches that are entirely contained within others
 func properize(matches []match, limit int) []match {
 	sortMatches(matches)
@@ -568,7 +698,7 @@ func sortMatches(matches []match) {
 // queryDatabases is for finding a fragment/plasmid with the entry name in one of the dbs
 func queryDatabases(entry string, dbs []string) (f *Frag, err error) {
 	// first try to get the entry out of a local file
-	if frags, err := read(entry, false); err == nil && len(frags) > 0 {
+	if frags, err := readTemplate(entry); err == nil && len(frags) > 0 {
 		return frags[0], nil // it was a local file
 	}
 
@@ -600,7 +730,7 @@ func queryDatabases(entry string, dbs []string) (f *Frag, err error) {
 		}
 		defer os.Remove(outFile)
 
-		if frags, err := read(outFile, false); err == nil {
+		if frags, err := readTemplate(outFile); err == nil {
 			targetFrag := frags[0]
 
 			// fix the ID, don't want titles in the ID (bug)
@@ -654,8 +784,9 @@ func seqMismatch(primers []Primer, parentID, parentSeq string, conf *config.Conf
 }
 
 // parentMismatch both searches for a the parent fragment in its source DB and queries for
-// any mismatches in the seq before returning
-func parentMismatch(primers []Primer, parent, db string, conf *config.Config) mismatchResult {
+// any mismatches in the seq before returning. strand is that of the parent the fragment is
+// on: 1 if top, -1 if bottom, or 0 if unknown.
+func parentMismatch(primers []Primer, parent, db string, strand int, conf *config.Config) mismatchResult {
 	// try and query for the parent in the source DB and write to a file
 	parentFile, parentSeq, err := blastdbcmd(parent, db)
 
@@ -676,14 +807,13 @@ func parentMismatch(primers []Primer, parent, db string, conf *config.Config) mi
 		defer os.Remove(parentFile.Name())
 
 		for i, primer := range primers {
-			// confirm that the 3' end of the primer is in the parent seq
-			primerEnd := primer.Seq[len(primer.Seq)-10:]
-			if !strings.Contains(parentSeq, primerEnd) && !strings.Contains(parentSeq, reverseComplement(primerEnd)) {
+			// confirm that the 3' end of the primer is on the parent's strand it's from
+			if !primerBindsSource(primer, strand, parentSeq) {
 				dir := "FWD"
 				if i > 0 {
 					dir = "REV"
 				}
-				return mismatchResult{false, match{}, fmt.Errorf("does not contain end of %s primer: %s", dir, primerEnd)}
+				return mismatchResult{false, match{}, fmt.Errorf("%s does not contain end of %s primer on the fragment's strand: %s", parent, dir, primer.Seq)}
 			}
 
 			// check for a mismatch in the parent sequence
@@ -739,7 +869,7 @@ func blastdbcmd(entry, db string) (output *os.File, parentSeq string, err error)
 	}
 
 	// read in the results as fragments. set their sequence to the full one returned from blastdbcmd
-	fragments, err := read(output.Name(), false)
+	fragments, err := readTemplate(output.Name())
 	if err == nil && len(fragments) >= 1 {
 		for _, f := range fragments {
 			f.fullSeq = f.Seq // set fullSeq, faster to check for primer off-targets later
@@ -750,36 +880,373 @@ func blastdbcmd(entry, db string) (output *os.File, parentSeq string, err error)
 	return nil, "", fmt.Errorf("warning: failed to query %s from %s", entry, db)
 }
 
+// fastaDBs makes BLAST dbs from FASTA files and returns their paths. Each db is
+// cached by the hash of its FASTA file and only remade if the FASTA file changes.
+func fastaDBs(fastas []string) (paths []string, err error) {
+	for _, fasta := range fastas {
+		contents, err := ioutil.ReadFile(fasta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FASTA file for BLAST db: %v", err)
+		}
+
+		dbPath, err := fastaDBPath(fasta, contents)
+		if err != nil {
+			return nil, err
+		}
+
+		// already made from this version of the FASTA file
+		if blastDBExists(dbPath) {
+			paths = append(paths, dbPath)
+			continue
+		}
+
+		if err = makeblastdb(fasta, dbPath, contents); err != nil {
+			return nil, err
+		}
+		paths = append(paths, dbPath)
+	}
+
+	return paths, nil
+}
+
+// blastDBIndexes are the extensions of the files makeblastdb makes for a nucleotide db
+var blastDBIndexes = []string{".nhr", ".nin", ".nsq"}
+
+// blastDBExists returns whether every index file of the BLAST db at the path exists.
+func blastDBExists(dbPath string) bool {
+	for _, ext := range blastDBIndexes {
+		if _, err := os.Stat(dbPath + ext); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// fastaDBPath returns the path to the cached BLAST db of a FASTA file. The db's directory
+// is named after the FASTA file, the hash of its path, and the hash of its contents.
+func fastaDBPath(fasta string, contents []byte) (string, error) {
+	abs, err := filepath.Abs(fasta)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the path to FASTA file for BLAST db: %v", err)
+	}
+
+	hash := sha1.Sum(contents)
+	name := filepath.Base(fasta)
+	dir := fmt.Sprintf("%s%s", fastaDBPrefix(abs), hex.EncodeToString(hash[:])[:12])
+
+	return filepath.Join(config.DBCacheDir, dir, name), nil
+}
+
+// fastaDBPrefix returns the prefix of the cache directories of the dbs made from
+// every version of the FASTA file at the absolute path.
+func fastaDBPrefix(abs string) string {
+	pathHash := sha1.Sum([]byte(abs))
+	return fmt.Sprintf("%s-%s-", filepath.Base(abs), hex.EncodeToString(pathHash[:])[:8])
+}
+
+// staleFastaDBs returns the cache directories of the dbs made from earlier versions of
+// the FASTA file of the db at dbPath. Dbs of other FASTA files with the same name aren't.
+func staleFastaDBs(fasta, dbPath string) (stale []string) {
+	abs, err := filepath.Abs(fasta)
+	if err != nil {
+		return nil
+	}
+
+	prefix := fastaDBPrefix(abs)
+	dirs, _ := ioutil.ReadDir(config.DBCacheDir)
+	for _, d := range dirs {
+		path := filepath.Join(config.DBCacheDir, d.Name())
+		if d.IsDir() && strings.HasPrefix(d.Name(), prefix) && path != filepath.Dir(dbPath) {
+			stale = append(stale, path)
+		}
+	}
+
+	return
+}
+
+// makeblastdb makes a BLAST db at dbPath from the contents of a FASTA file. Dbs made
+// from earlier versions of the same FASTA file are removed from the cache.
+func makeblastdb(fasta, dbPath string, contents []byte) error {
+	if _, err := exec.LookPath("makeblastdb"); err != nil {
+		return fmt.Errorf("no makeblastdb executable in PATH, needed for --db-fasta: %v", err)
+	}
+
+	// remove stale dbs of the same FASTA file
+	for _, staleDir := range staleFastaDBs(fasta, dbPath) {
+		os.RemoveAll(staleDir)
+	}
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create BLAST db cache directory: %v", err)
+	}
+
+	// copy the FASTA file in, it's queried for the fragments' sequences later
+	if err := ioutil.WriteFile(dbPath, contents, 0644); err != nil {
+		return fmt.Errorf("failed to copy FASTA file to BLAST db cache: %v", err)
+	}
+
+	makeCmd := exec.Command(
+		"makeblastdb",
+		"-in", dbPath,
+		"-dbtype", "nucl",
+		"-title", filepath.Base(dbPath),
+		"-parse_seqids",
+	)
+
+	if output, err := makeCmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir) // don't leave a partial db in the cache
+		return fmt.Errorf("failed to make a BLAST db from %s: %v: %s", fasta, err, string(output))
+	}
+
+	return nil
+}
+
+// DatabaseFindCmd logs the BLAST databases that can be searched: the repositories',
+// those made from FASTA files (--db-fasta), and any passed with --dbs. Each is logged
+// with its path, number of sequences, and whether it's a valid BLAST db.
+func DatabaseFindCmd(cmd *cobra.Command, args []string) {
+	p := inputParser{}
+	c := config.New()
+
+	type db struct{ source, path string }
+	var dbs []db
+	for _, repository := range []string{"addgene", "igem", "dnasu"} {
+		path, _ := c.DBAlias(repository)
+		dbs = append(dbs, db{repository, path})
+	}
+
+	cached, _ := filepath.Glob(filepath.Join(config.DBCacheDir, "*", "*.nsq"))
+	for _, nsq := range cached {
+		dbs = append(dbs, db{"db-fasta", strings.TrimSuffix(nsq, ".nsq")})
+	}
+
+	dbList, _ := cmd.Flags().GetString("dbs")
+	local, err := p.dbPaths(dbList, c)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+	for _, path := range local {
+		dbs = append(dbs, db{"local", path})
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+	fmt.Fprintf(writer, "database\tsource\tpath\tsequences\tstatus\t\n")
+	for _, d := range dbs {
+		count, status := "-", "ok"
+		if info, err := blastdbInfo(d.path); err != nil {
+			status = err.Error()
+		} else if n, err := dbSequenceCount(info); err != nil {
+			status = err.Error()
+		} else {
+			count = strconv.Itoa(n)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t\n", filepath.Base(d.path), d.source, d.path, count, status)
+	}
+	writer.Flush()
+}
+
+// blastdbInfo returns the output of blastdbcmd's summary of a BLAST db. An error is
+// returned if the db doesn't exist or isn't a valid BLAST db.
+func blastdbInfo(db string) (string, error) {
+	if _, err := os.Stat(db + ".nsq"); err != nil {
+		if _, err := os.Stat(db + ".nal"); err != nil {
+			return "", fmt.Errorf("missing")
+		}
+	}
+
+	output, err := exec.Command("blastdbcmd", "-db", db, "-dbtype", "nucl", "-info").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("invalid BLAST db: %s", strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// dbSequenceCount returns the number of sequences in a BLAST db from blastdbcmd's summary,
+// like "54,797 sequences; 350,267,338 total bases".
+func dbSequenceCount(info string) (int, error) {
+	count := regexp.MustCompile(`([\d,]+) sequences`).FindStringSubmatch(info)
+	if count == nil {
+		return 0, fmt.Errorf("failed to find the number of sequences in the BLAST db")
+	}
+
+	return strconv.Atoi(strings.Replace(count[1], ",", "", -1))
+}
+
+// targetMismatch BLASTs a PCR fragment's primers against the target plasmid for
+// binding sites other than the primers' own. Each is returned with the size of the
+// spurious amplicon it would make with the fragment's other primer.
+func targetMismatch(f *Frag, targetSeq string, conf *config.Config) (mispriming []Mispriming, err error) {
+	tL := len(targetSeq)
+	if len(f.Primers) != 2 || tL < 1 {
+		return nil, nil
+	}
+
+	// add to self to catch binding sites across the zero-index
+	doubled := strings.ToUpper(targetSeq + targetSeq)
+
+	// find the primers' own binding sites
+	fwdSeq := strings.ToUpper(f.Primers[0].Seq)
+	revSeq := strings.ToUpper(f.Primers[1].Seq)
+	fwdStart := strings.Index(doubled, fwdSeq)
+	revEnd := strings.Index(doubled, reverseComplement(revSeq))
+	if fwdStart < 0 || revEnd < 0 {
+		return nil, fmt.Errorf("failed to find the binding sites of %s's primers in the plasmid", f.ID)
+	}
+	fwdStart %= tL
+	revEnd = (revEnd + len(revSeq)) % tL
+
+	targetFile, err := ioutil.TempFile("", "target-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(targetFile.Name())
+
+	if _, err = targetFile.WriteString(fmt.Sprintf(">target\n%s\n", doubled)); err != nil {
+		return nil, fmt.Errorf("failed to write target sequence to subject FASTA file: %v", err)
+	}
+
+	// whether the binding site is the primer's own
+	ownSite := func(index, ownIndex, primerLength int) bool {
+		distance := (index - ownIndex + tL) % tL
+		return distance < primerLength || tL-distance < primerLength
+	}
+
+	seen := make(map[string]bool)
+	for i, primer := range f.Primers {
+		dir := "FWD"
+		if i > 0 {
+			dir = "REV"
+		}
+
+		matches, err := primerMatches(primer.Seq, targetFile, conf)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range matches {
+			start := m.subjectStart % tL
+			end := (m.subjectEnd + 1) % tL
+
+			// forward matches extend to the right, toward the reverse primer's binding site
+			amplicon := 0
+			if m.forward {
+				if i == 0 && ownSite(start, fwdStart, len(primer.Seq)) {
+					continue
+				}
+				amplicon = (revEnd - start + tL) % tL
+			} else {
+				if i == 1 && ownSite(end, revEnd, len(primer.Seq)) {
+					continue
+				}
+				amplicon = (end - fwdStart + tL) % tL
+			}
+
+			key := fmt.Sprintf("%s-%d", dir, start)
+			if seen[key] {
+				continue // second copy in the doubled target
+			}
+			seen[key] = true
+
+			mispriming = append(mispriming, Mispriming{
+				Primer:   dir,
+				Start:    start,
+				Amplicon: amplicon,
+			})
+		}
+	}
+
+	return mispriming, nil
+}
+
+// primerBindsSource returns whether the 3' end of a primer is on the strand of the source
+// that it's copied from. A fragment on the bottom strand of its source (strand -1) has a
+// FWD primer from the bottom strand and a REV primer from the top strand. Either strand
+// is accepted if the fragment's strand is unknown (0).
+func primerBindsSource(primer Primer, strand int, source string) bool {
+	end := primerEnd(primer)
+	source = strings.ToUpper(source)
+
+	top, bottom := strings.Contains(source, end), strings.Contains(source, reverseComplement(end))
+	switch {
+	case strand == 0:
+		return top || bottom
+	case primer.Strand == (strand > 0):
+		return top
+	default:
+		return bottom
+	}
+}
+
+// primerEnd returns the 3' end of a primer that has to bind its template for extension.
+func primerEnd(primer Primer) string {
+	end := primer.Seq
+	if len(end) > 10 {
+		end = end[len(end)-10:]
+	}
+	return strings.ToUpper(end)
+}
+
 // mismatch finds mismatching sequences between the query sequence and
 // the parent sequence (in the parent file)
 //
 // The fragment to query against is stored in parentFile
 func mismatch(primer string, parentFile *os.File, c *config.Config) (wasMismatch bool, m match, err error) {
+	matches, err := primerMatches(primer, parentFile, c)
+	if err != nil {
+		return false, match{}, err
+	}
+
+	// parse the results and check whether any are cause for concern (by Tm)
+	primerCount := 1 // number of times we expect to see the primer itself
+	parentFileContents, err := ioutil.ReadFile(parentFile.Name())
+	if err != nil {
+		return false, match{}, err
+	}
+
+	if strings.Contains(string(parentFileContents), "circular") {
+		// if the match is against a circular fragment, we expect to see the primer's binding location
+		// twice because circular fragments' sequences are doubled in the DBs
+		primerCount++
+	}
+
+	if len(matches) > primerCount {
+		return true, matches[primerCount], nil
+	}
+
+	return false, match{}, nil
+}
+
+// primerMatches BLASTs a primer against the sequence in the subject file and returns
+// the matches with a high enough Tm to be an off-target binding site
+func primerMatches(primer string, subjectFile *os.File, c *config.Config) (strong []match, err error) {
 	// path to the entry batch file to hold the entry accession
 	in, err := ioutil.TempFile("", "primer3-in-*")
 	if err != nil {
-		return false, match{}, err
+		return nil, err
 	}
 	defer os.Remove(in.Name())
 
 	// path to the output sequence file from querying the entry's sequence from the BLAST db
 	out, err := ioutil.TempFile("", "primer3-out-*")
 	if err != nil {
-		return false, match{}, err
+		return nil, err
 	}
 	defer os.Remove(out.Name())
 
 	// create input file
 	inContent := fmt.Sprintf(">primer\n%s\n", primer)
 	if _, err = in.WriteString(inContent); err != nil {
-		return false, m, fmt.Errorf("failed to write primer sequence to query FASTA file: %v", err)
+		return nil, fmt.Errorf("failed to write primer sequence to query FASTA file: %v", err)
 	}
 
-	// BLAST the query sequence against the parentFile sequence
+	// BLAST the query sequence against the subjectFile sequence
 	b := blastExec{
 		in:       in,
 		out:      out,
-		subject:  parentFile.Name(),
+		subject:  subjectFile.Name(),
 		seq:      primer,
 		identity: 65,    // see Primer-BLAST https://www.ncbi.nlm.nih.gov/pmc/articles/PMC3412702/
 		evalue:   30000, // see Primer-BLAST
@@ -787,60 +1254,35 @@ func mismatch(primer string, parentFile *os.File, c *config.Config) (wasMismatch
 
 	// execute BLAST
 	if err = b.runAgainst(); err != nil {
-		return false, m, fmt.Errorf("failed to run blast against parent: %v", err)
+		return nil, fmt.Errorf("failed to run blast against parent: %v", err)
 	}
 
 	// get the BLAST matches
 	matches, err := b.parse([]string{})
 	if err != nil {
-		return false, match{}, fmt.Errorf("failed to parse matches from %s: %v", out.Name(), err)
-	}
-
-	// parse the results and check whether any are cause for concern (by Tm)
-	primerCount := 1 // number of times we expect to see the primer itself
-	parentFileContents, err := ioutil.ReadFile(parentFile.Name())
-	if err != nil {
-		return false, match{}, err
-	}
-
-	if strings.Contains(string(parentFileContents), "circular") {
-		// if the match is against a circular fragment, we expect to see the primer's binding location
-		// twice because circular fragments' sequences are doubled in the DBs
-		primerCount++
+		return nil, fmt.Errorf("failed to parse matches from %s: %v", out.Name(), err)
 	}
 
 	for _, m := range matches {
 		if isMismatch(primer, m, c) {
-			primerCount--
-		}
-
-		if primerCount < 0 {
-			return true, m, nil
+			strong = append(strong, m)
 		}
 	}
 
-	return false, match{}, nil
+	return strong, nil
 }
 
 // runs blast on the query file against another subject file (rather than blastdb)
 func (b *blastExec) runAgainst() (err error) {
 	// create the blast command
 	// https://www.ncbi.nlm.nih.gov/books/NBK279682/
-	blastCmd := exec.Command(
-		"blastn",
+	return runBlastn([]string{
 		"-task", "blastn",
 		"-query", b.in.Name(),
 		"-subject", b.subject,
 		"-out", b.out.Name(),
 		"-outfmt", "7 sseqid qstart qend sstart send sseq mismatch gaps stitle",
-	)
-
-	// execute BLAST and wait on it to finish
-	if output, err := blastCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to execute blastn against %s: %v: %s", b.subject, err, string(output))
-	}
-
-	return
+	}, b.subject)
 }
 
 // isMismatch returns whether the match constitutes a mismatch
@@ -855,14 +1297,14 @@ func isMismatch(primer string, m match, c *config.Config) bool {
 		ectopic = reverseComplement(ectopic)
 	}
 
-	ntthalCmd := exec.Command(
-		"ntthal",
+	args := append([]string{
 		"-a", "END1", // end of primer sequence
 		"-s1", primer,
 		"-s2", ectopic,
 		"-path", config.Primer3Config,
 		"-r", // temperature only
-	)
+	}, ntthalConcentrations(c)...)
+	ntthalCmd := exec.Command("ntthal", args...)
 
 	ntthalOut, err := ntthalCmd.CombinedOutput()
 	if err != nil {
@@ -1,9 +1,13 @@
 package repp
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jjtimmons/repp/config"
@@ -22,7 +26,7 @@ func Test_BLAST(t *testing.T) {
 	seq := "GGCCGCAATAAAATATCTTTATTTTCATTACATCTGTGTGTTGGTTTTTTGTGTGAATCGATAGTACTAACATGACCACCTTGATCTTCATGGTCTGGGTGCCCTCGTAGGGCTTGCCTTCGCCCTCGGATGTGCACTTGAAGTGGTGGTTGTTCACGGTGCCCTCCATGTACAGCTTCATGTGCATGTTCTCCTTGATCAGCTCGCTCATAGGTCCAGGGTTCTCCTCCACGTCTCCAGCCTGCTTCAGCAGGCTGAAGTTAGTAGCTCCGCTTCCGGATCCCCCGGGGAGCATGTCAAGGTCAAAATCGTCAAGAGCGTCAGCAGGCAGCATATCAAGGTCAAAGTCGTCAAGGGCATCGGCTGGGAgCATGTCTAAgTCAAAATCGTCAAGGGCGTCGGCCGGCCCGCCGCTTTcgcacGCCCTGGCAATCGAGATGCTGGACAGGCATCATACCCACTTCTGCCCCCTGGAAGGCGAGTCATGGCAAGACTTTCTGCGGAACAACGCCAAGTCATTCCGCTGTGCTCTCCTCTCACATCGCGACGGGGCTAAAGTGCATCTCGGCACCCGCCCAACAGAGAAACAGTACGAAACCCTGGAAAATCAGCTCGCGTTCCTGTGTCAGCAAGGCTTCTCCCTGGAGAACGCACTGTACGCTCTGTCCGCCGTGGGCCACTTTACACTGGGCTGCGTATTGGAGGATCAGGAGCATCAAGTAGCAAAAGAGGAAAGAGAGACACCTACCACCGATTCTATGCCTGACTGTGGCGGGTGAGCTTAGGGGGCCTCCGCTCCAGCTCGACACCGGGCAGCTGCTGAAGATCGCGAAGAGAGGGGGAGTAACAGCGGTAGAGGCAGTGCACGCCTGGCGCAATGCGCTCACCGGGGCCCCCTTGAACCTGACCCCAGACCAGGTAGTCGCAATCGCGAACAATAATGGGGGAAAGCAAGCCCTGGAAACCGTGCAAAGGTTGTTGCCGGTCCTTTGTCAAGACCACGGCCTTACACCGGAGCAAGTCGTGGCCATTGCAAGCAATGGGGGTGGCAAACAGGCTCTTGAGACGGTTCAGAGACTTCTCCCAGTTCTCTGTCAAGCCGTTGGAGTCCACGTTCTTTAATAGTGGACTCTTGTTCCAAACTGGAACAACACTCAACCCTATCTCGGTCTATTCTTTTGATTTATAAGGGATTTTGCCGATTTCGGCCTATTGGTTAAAAAATGAGCTGATTTAACAAAAATTTAACGCGAATTTTAACAAAATATTAACGCTTACAATTTAGGTGGCACTTTTCGGGGAAATGTGCGCGGAACCCCTATTTGTTTATTTTTCTAAATACATTCAAATATGTATCCGCTCATGAGACAATAACCCTGATAAATGCTTCAATAATATTGAAAAAGGAAGAGTATGAGTATTCAACATTTCCGTGTCGCCCTTATTCCCTTTTTTGCGGCATTTTGCCTTCCTGTTTTTGCTCACCCAGAAACGCTGGTGAAAGTAAAAGATGCTGAAGATCAGTTGGGTGCACGAGTGGGTTACATCGAACTGGATCTCAACAGCGGTAAGATCCTTGAGAGTTTTCGCCCCGAAGAACGTTTTCCAATGATGAGCACTTTTAAAGTTCTGCTATGTGGCGCGGTATTATCCCGTATTGACGCCGGGCAAGAGCAACTCGGTCGCCGCATACACTATTCTCAGAATGACTTGGTTGAGTACTCACCAGTCACAGAAAAGCATCTTACGGATGGCATGACAGTAAGAGAATTATGCAGTGCTGCCATAACCATGAGTGATAACACTGCGGCCAACTTACTTCTGACAACGATCGGAGGACCGAAGGAGCTAACCGCTTTTTTGCACAACATGGGGGATCATGTAACTCGCCTTGATCGTTGGGAACCGGAGCTGAATGAAGCCATACCAAACGACGAGCGTGACACCACGATGCCTGTAGCAATGGCAACAACGTTGCGCAAACTATTAACTGGCGAACTACTTACTCTAGCTTCCCGGCAACAATTAATAGACTGGATGGAGGCGGATAAAGTTGCAGGACCACTTCTGCGCTCGGCCCTTCCGGCTGGCTGGTTTATTGCTGATAAATCTGGAGCCGGTGAGCGTGGGTCTCGCGGTATCATTGCAGCACTGGGGCCAGATGGTAAGCCCTCCCGTATCGTAGTTATCTACACGACGGGGAGTCAGGCAACTATGGATGAACGAAATAGACAGATCGCTGAGATAGGTGCCTCACTGATTAAGCATTGGTAACTGTCAGACCAAGTTTACTCATATATACTTTAGATTGATTTAAAACTTCATTTTTAATTTAAAAGGATCTAGGTGAAGATCCTTTTTGATAATCTCATGACCAAAATCCCTTAACGTGAGTTTTCGTTCCACTGAGCGTCAGACCCCGTAGAA"
 
 	// run blast
-	matches, err := blast(id, seq, true, []string{testDB}, []string{}, 10, blastWriter()) // any match over 10 bp
+	matches, err := blast(id, seq, true, 0, []string{testDB}, []string{}, 10, nil, true, blastWriter()) // any match over 10 bp
 
 	// check if it fails
 	if err != nil {
@@ -102,6 +106,96 @@ func Test_cull(t *testing.T) {
 	}
 }
 
+func Test_removeWrapped(t *testing.T) {
+	// a 100 bp circular target, doubled for BLAST
+	matches := []match{
+		// the end of "wrap" at the start of the target, should be removed
+		match{entry: "wrap", uniqueID: "wrap0", queryStart: 0, queryEnd: 10},
+		// another fragment at the start of the target
+		match{entry: "other", uniqueID: "other0", queryStart: 0, queryEnd: 10},
+		match{entry: "other", uniqueID: "other0", queryStart: 100, queryEnd: 110},
+		// "wrap" across the zero index
+		match{entry: "wrap", uniqueID: "wrap95", queryStart: 95, queryEnd: 110},
+		// a stretch of "wrap" that isn't in the match across the zero index
+		match{entry: "wrap", uniqueID: "wrap40", queryStart: 40, queryEnd: 60},
+	}
+
+	var gotIDs []string
+	for _, m := range removeWrapped(matches, 100) {
+		gotIDs = append(gotIDs, m.uniqueID)
+	}
+
+	wantIDs := []string{"other0", "other0", "wrap95", "wrap40"}
+	if !reflect.DeepEqual(gotIDs, wantIDs) {
+		t.Errorf("removeWrapped() = %v, want %v", gotIDs, wantIDs)
+	}
+}
+
+func Test_blastExec_input(t *testing.T) {
+	tests := []struct {
+		name     string
+		circular bool
+		padding  int
+		want     string
+	}{
+		{"linear query isn't padded", false, 4, ">target\nACGTACCCGG\n"},
+		{"circular query is doubled by default", true, 0, ">target\nACGTACCCGGACGTACCCGG\n"},
+		{"circular query padded with its start", true, 4, ">target\nACGTACCCGGACGT\n"},
+		{"padding past the query's length is all of it", true, 40, ">target\nACGTACCCGGACGTACCCGG\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := ioutil.TempFile("", "blast-in-*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(in.Name())
+
+			b := &blastExec{name: "target", seq: "ACGTACCCGG", circular: tt.circular, padding: tt.padding, in: in}
+			if err := b.input(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _ := ioutil.ReadFile(in.Name()); string(got) != tt.want {
+				t.Errorf("blastExec.input() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dbSequenceCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    string
+		want    int
+		wantErr bool
+	}{
+		{
+			"blastdbcmd summary",
+			"Database: addgene\n\t54,797 sequences; 350,267,338 total bases\n\nDate: Jun 3, 2019  1:47 PM\n",
+			54797,
+			false,
+		},
+		{
+			"no sequence count",
+			"BLAST Database error: No alias or index file found for nucleotide database [igem]\n",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dbSequenceCount(tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("dbSequenceCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("dbSequenceCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_isMismatch(t *testing.T) {
 	c := config.New()
 	c.PCRMaxOfftargetTm = 40.0
@@ -147,6 +241,161 @@ func Test_isMismatch(t *testing.T) {
 	}
 }
 
+func Test_targetMismatch(t *testing.T) {
+	conf := config.New()
+	conf.PCRMaxOfftargetTm = 35.0
+	target := "CCGTAATGCCTTTCCCTAACAGAGTTTTTCGAACTCGTGTTGTCGAGCGACGGAATTAGATCAGTTAAATGGCAGAAAACTGGCAGGGCTTTTAGTCGTGGGATGATCAGTGGGTAAAGGTGGCGCGGGGTAACGCGCGCTAAGGCTCAGCTGCAACGCGGAGCTGGTGTGTTATCCATTCATGGCAGACAACTAATACGCATAAGCGTAGCCAACCGCATTAGCGTATGAACAAAATAATGCGAGTTGGCGGAATTAGATCAGTTAAATGGTACCGATCTCAGGGATATAGAATCCTAAATCAGAAATGGAACAAAGCACCCTTGGTGTATCTCTTCTCCATTTCCGCCGCGTGCGAGTTCCGCGTCTTCTATATATCCACGCCGCCAGCAGCTAAAAG"
+
+	tests := []struct {
+		name    string
+		primers []Primer
+		want    []Mispriming
+		wantErr bool
+	}{
+		{
+			"second binding site of the forward primer",
+			[]Primer{
+				{Seq: "CGGAATTAGATCAGTTAAATGG"},
+				{Seq: "ACACACCAGCTCCGCGTTGCAG"},
+			},
+			[]Mispriming{
+				{Primer: "FWD", Start: 250, Amplicon: 322},
+			},
+			false,
+		},
+		{
+			"primers not in the plasmid",
+			[]Primer{
+				{Seq: "TTTTTTTTTTTTTTTTTTTTTT"},
+				{Seq: "ACACACCAGCTCCGCGTTGCAG"},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := targetMismatch(&Frag{ID: "frag", Primers: tt.primers}, target, conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("targetMismatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("targetMismatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fastaDBPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fasta-db-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fasta := filepath.Join(dir, "parts.fa")
+	path, err := fastaDBPath(fasta, []byte(">part\nATGC\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Base(path) != "parts.fa" || !strings.HasPrefix(filepath.Base(filepath.Dir(path)), "parts.fa-") {
+		t.Errorf("fastaDBPath() = %s, want a parts.fa db in a parts.fa-<hash> dir", path)
+	}
+
+	if samePath, _ := fastaDBPath(fasta, []byte(">part\nATGC\n")); samePath != path {
+		t.Errorf("fastaDBPath() = %s for an unchanged FASTA, want %s", samePath, path)
+	}
+
+	if changedPath, _ := fastaDBPath(fasta, []byte(">part\nATGCATGC\n")); changedPath == path {
+		t.Errorf("fastaDBPath() = %s for a changed FASTA, want a new path", changedPath)
+	}
+
+	if otherPath, _ := fastaDBPath(filepath.Join(dir, "lab", "parts.fa"), []byte(">part\nATGC\n")); otherPath == path {
+		t.Errorf("fastaDBPath() = %s for another FASTA with the same name, want a new path", otherPath)
+	}
+
+	if _, err := fastaDBs([]string{filepath.Join(dir, "missing.fa")}); err == nil {
+		t.Error("fastaDBs() expected an error for a missing FASTA")
+	}
+}
+
+func Test_staleFastaDBs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fasta-db-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cacheDir := config.DBCacheDir
+	config.DBCacheDir = filepath.Join(dir, "cache")
+	defer func() { config.DBCacheDir = cacheDir }()
+
+	fasta := filepath.Join(dir, "parts.fa")
+	old, _ := fastaDBPath(fasta, []byte(">part\nATGC\n"))
+	current, _ := fastaDBPath(fasta, []byte(">part\nATGCATGC\n"))
+	other, _ := fastaDBPath(filepath.Join(dir, "lab", "parts.fa"), []byte(">part\nATGC\n"))
+	for _, db := range []string{old, current, other} {
+		os.MkdirAll(filepath.Dir(db), 0755)
+	}
+
+	want := []string{filepath.Dir(old)}
+	if got := staleFastaDBs(fasta, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("staleFastaDBs() = %v, want %v", got, want)
+	}
+}
+
+func Test_blastnError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		output        string
+		wantMessage   string
+		wantTransient bool
+	}{
+		{
+			"invalid database",
+			fmt.Errorf("exit status 2"),
+			"BLAST Database error: No alias or index file found for nucleotide database [addgene]",
+			"invalid BLAST database",
+			false,
+		},
+		{
+			"out of memory",
+			fmt.Errorf("exit status 3"),
+			"Error: std::bad_alloc\n",
+			"ran out of resources",
+			true,
+		},
+		{
+			"killed",
+			fmt.Errorf("signal: killed"),
+			"",
+			"was killed",
+			true,
+		},
+		{
+			"other runtime failure",
+			fmt.Errorf("exit status 1"),
+			"Error: Unknown argument: -foo",
+			"Unknown argument: -foo",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr, gotTransient := blastnError("addgene", tt.err, tt.output)
+			if !strings.Contains(gotErr.Error(), tt.wantMessage) {
+				t.Errorf("blastnError() = %v, want it to contain %s", gotErr, tt.wantMessage)
+			}
+			if gotTransient != tt.wantTransient {
+				t.Errorf("blastnError() transient = %v, want %v", gotTransient, tt.wantTransient)
+			}
+		})
+	}
+}
+
 func Test_parentMismatch(t *testing.T) {
 	testDB, _ := filepath.Abs(path.Join("..", "..", "test", "db", "db"))
 
@@ -196,7 +445,7 @@ func Test_parentMismatch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mismatchResult := parentMismatch([]Primer{Primer{Seq: tt.args.primer}}, tt.args.parent, testDB, conf)
+			mismatchResult := parentMismatch([]Primer{Primer{Seq: tt.args.primer}}, tt.args.parent, testDB, -1, conf)
 			gotMismatch := mismatchResult.wasMismatch
 			gotMatch := mismatchResult.m
 			err := mismatchResult.err
@@ -223,6 +472,46 @@ func Test_parentMismatch(t *testing.T) {
 	}
 }
 
+func Test_primerBindsSource(t *testing.T) {
+	// addgene:107006 in the test db, its fragment at 101-400 is on the bottom strand
+	frags, err := read(path.Join("..", "..", "test", "db", "db"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := ""
+	for _, f := range frags {
+		if strings.HasPrefix(f.ID, "gnl|addgene|107006") {
+			source = f.Seq
+		}
+	}
+	if source == "" {
+		t.Fatal("failed to find gnl|addgene|107006 in the test db")
+	}
+	region := strings.ToUpper(source[100:400])
+	fragSeq := reverseComplement(region)
+
+	tests := []struct {
+		name   string
+		primer Primer
+		strand int
+		want   bool
+	}{
+		{"FWD primer from the bottom strand", Primer{Seq: fragSeq[:20], Strand: true}, -1, true},
+		{"REV primer from the top strand", Primer{Seq: reverseComplement(fragSeq[len(fragSeq)-20:]), Strand: false}, -1, true},
+		{"FWD primer as if the fragment were on the top strand", Primer{Seq: region[:20], Strand: true}, -1, false},
+		{"REV primer as if the fragment were on the top strand", Primer{Seq: reverseComplement(region[len(region)-20:]), Strand: false}, -1, false},
+		{"top strand FWD primer", Primer{Seq: region[:20], Strand: true}, 1, true},
+		{"unknown strand", Primer{Seq: region[:20], Strand: true}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := primerBindsSource(tt.primer, tt.strand, source); got != tt.want {
+				t.Errorf("primerBindsSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_queryDatabases(t *testing.T) {
 	type args struct {
 		entry string
@@ -0,0 +1,178 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd logs the differences between a solution in each of two output JSON files:
+// the fragments added and removed, the change in cost, and the changed junctions.
+func DiffCmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass two output JSON files to diff.")
+	}
+
+	index, err := cmd.Flags().GetInt("solution")
+	if err != nil {
+		index = 1
+	}
+
+	oldSolution, err := readSolution(args[0], index)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	newSolution, err := readSolution(args[1], index)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	fmt.Print(diffSolutions(oldSolution, newSolution, config.New()))
+}
+
+// readSolution reads the solution at the 1-based index from an output JSON file.
+func readSolution(filename string, index int) (Solution, error) {
+	out, err := readOutput(filename)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	if index < 1 || index > len(out.Solutions) {
+		return Solution{}, fmt.Errorf("no solution %d in %s, it has %d", index, filename, len(out.Solutions))
+	}
+
+	return out.Solutions[index-1], nil
+}
+
+// readOutput reads an output JSON file.
+func readOutput(filename string) (Output, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Output{}, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+
+	out := Output{}
+	if err = json.Unmarshal(contents, &out); err != nil {
+		return Output{}, fmt.Errorf("failed to parse %s: %v", filename, err)
+	}
+
+	return out, nil
+}
+
+// diffKey returns the key used to match a fragment between solutions. PCR fragments match by
+// their template and its amplified region and others by their URL or ID. Synthetic fragments'
+// IDs depend on their neighbors so they match by sequence.
+func diffKey(f *Frag) string {
+	switch {
+	case f.SourceID != "":
+		return fmt.Sprintf("%s %s:%d-%d", f.Type, f.SourceID, f.SourceStart, f.SourceEnd)
+	case f.Type != synthetic.String() && f.URL != "":
+		return f.Type + " " + f.URL
+	case f.Type != synthetic.String() && f.ID != "":
+		return f.Type + " " + f.ID
+	default:
+		return f.Type + " " + strings.ToUpper(f.Seq)
+	}
+}
+
+// diffName returns a short, human readable name for a fragment in a diff.
+func diffName(f *Frag) string {
+	name := f.ID
+	if f.SourceID != "" {
+		name = fmt.Sprintf("%s:%d-%d", f.SourceID, f.SourceStart, f.SourceEnd)
+	} else if name == "" {
+		name = f.URL
+	}
+
+	if f.Type == synthetic.String() {
+		return fmt.Sprintf("%s (%s, %d bp)", name, f.Type, len(f.Seq))
+	}
+	return fmt.Sprintf("%s (%s)", name, f.Type)
+}
+
+// diffSolutions returns a human readable diff of two solutions. Fragments that were removed
+// from the old solution are prefixed with "-" and those added in the new one with "+".
+func diffSolutions(oldSolution, newSolution Solution, conf *config.Config) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(
+		&sb,
+		"cost: %s -> %s (%s)\n",
+		conf.FormatCost(oldSolution.Cost), conf.FormatCost(newSolution.Cost), conf.FormatCostChange(newSolution.Cost-oldSolution.Cost),
+	)
+	fmt.Fprintf(&sb, "fragments: %d -> %d (%+d)\n", oldSolution.Count, newSolution.Count, newSolution.Count-oldSolution.Count)
+
+	// fragments in one solution and not the other
+	oldFrags, newFrags := diffKeys(oldSolution.Fragments), diffKeys(newSolution.Fragments)
+	for _, f := range oldSolution.Fragments {
+		if !newFrags[diffKey(f)] {
+			fmt.Fprintf(&sb, "- %s\n", diffName(f))
+		}
+	}
+	for _, f := range newSolution.Fragments {
+		if !oldFrags[diffKey(f)] {
+			fmt.Fprintf(&sb, "+ %s\n", diffName(f))
+		}
+	}
+
+	// fragments in both, but with different costs
+	for _, f := range newSolution.Fragments {
+		for _, old := range oldSolution.Fragments {
+			if diffKey(f) == diffKey(old) && f.Cost != old.Cost {
+				fmt.Fprintf(&sb, "~ %s cost: %s -> %s\n", diffName(f), conf.FormatCost(old.Cost), conf.FormatCost(f.Cost))
+			}
+		}
+	}
+
+	// junctions, between each fragment and the next, in one solution and not the other
+	oldJunctions, newJunctions := diffJunctions(oldSolution.Fragments), diffJunctions(newSolution.Fragments)
+	for _, j := range oldJunctions {
+		if !containsJunction(newJunctions, j) {
+			fmt.Fprintf(&sb, "- junction %s -> %s\n", diffName(j[0]), diffName(j[1]))
+		}
+	}
+	for _, j := range newJunctions {
+		if !containsJunction(oldJunctions, j) {
+			fmt.Fprintf(&sb, "+ junction %s -> %s\n", diffName(j[0]), diffName(j[1]))
+		}
+	}
+
+	return sb.String()
+}
+
+// diffKeys returns the set of fragments' keys.
+func diffKeys(frags []*Frag) map[string]bool {
+	keys := make(map[string]bool)
+	for _, f := range frags {
+		keys[diffKey(f)] = true
+	}
+	return keys
+}
+
+// diffJunctions returns each fragment paired with the next in the circular assembly.
+func diffJunctions(frags []*Frag) (junctions [][2]*Frag) {
+	if len(frags) < 2 {
+		return nil
+	}
+
+	for i, f := range frags {
+		junctions = append(junctions, [2]*Frag{f, frags[(i+1)%len(frags)]})
+	}
+	return
+}
+
+// containsJunction returns whether the junction, between the same fragments, is in the list.
+func containsJunction(junctions [][2]*Frag, junction [2]*Frag) bool {
+	for _, j := range junctions {
+		if diffKey(j[0]) == diffKey(junction[0]) && diffKey(j[1]) == diffKey(junction[1]) {
+			return true
+		}
+	}
+	return false
+}
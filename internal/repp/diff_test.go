@@ -0,0 +1,51 @@
+package repp
+
+import (
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_diffSolutions(t *testing.T) {
+	pcrFrag := &Frag{Type: "pcr", Cost: 94.67, SourceID: "103998", SourceStart: 1, SourceEnd: 1200}
+	synthFrag := &Frag{ID: "103998-synthesis-1", Type: "synthetic", Cost: 141.98, Seq: "ACGTACGTAC"}
+	newSynthFrag := &Frag{ID: "103998-synthesis-1", Type: "synthetic", Cost: 89, Seq: "ACGTAC"}
+	backbone := &Frag{ID: "pSB1A3", Type: "linear", Cost: 0}
+	conf := &config.Config{CostCurrency: "$", CostDecimals: 2}
+
+	tests := []struct {
+		name        string
+		oldSolution Solution
+		newSolution Solution
+		want        string
+	}{
+		{
+			"same solution",
+			Solution{Count: 2, Cost: 236.65, Fragments: []*Frag{pcrFrag, synthFrag}},
+			Solution{Count: 2, Cost: 236.65, Fragments: []*Frag{pcrFrag, synthFrag}},
+			"cost: $236.65 -> $236.65 (+$0.00)\nfragments: 2 -> 2 (+0)\n",
+		},
+		{
+			"fragment replaced and one added",
+			Solution{Count: 2, Cost: 236.65, Fragments: []*Frag{pcrFrag, synthFrag}},
+			Solution{Count: 3, Cost: 183.67, Fragments: []*Frag{pcrFrag, newSynthFrag, backbone}},
+			"cost: $236.65 -> $183.67 (-$52.98)\n" +
+				"fragments: 2 -> 3 (+1)\n" +
+				"- 103998-synthesis-1 (synthetic, 10 bp)\n" +
+				"+ 103998-synthesis-1 (synthetic, 6 bp)\n" +
+				"+ pSB1A3 (linear)\n" +
+				"- junction 103998:1-1200 (pcr) -> 103998-synthesis-1 (synthetic, 10 bp)\n" +
+				"- junction 103998-synthesis-1 (synthetic, 10 bp) -> 103998:1-1200 (pcr)\n" +
+				"+ junction 103998:1-1200 (pcr) -> 103998-synthesis-1 (synthetic, 6 bp)\n" +
+				"+ junction 103998-synthesis-1 (synthetic, 6 bp) -> pSB1A3 (linear)\n" +
+				"+ junction pSB1A3 (linear) -> 103998:1-1200 (pcr)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffSolutions(tt.oldSolution, tt.newSolution, conf); got != tt.want {
+				t.Errorf("diffSolutions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
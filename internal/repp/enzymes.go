@@ -7,6 +7,7 @@ import (
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -46,10 +47,25 @@ type Backbone struct {
 
 	// Strands of each cut direction. True if fwd, False if rev direction
 	Strands []bool `json:"strands"`
+
+	// Overhangs are the single stranded ends of the backbone after digestion (top strand)
+	Overhangs []string `json:"overhangs,omitempty"`
+
+	// OverhangLengths are the bp in each of the overhangs
+	OverhangLengths []int `json:"overhangLengths,omitempty"`
+
+	// OverhangEnds of each overhang, "5'" or "3'". 3' overhangs are kept in the digested backbone
+	OverhangEnds []string `json:"overhangEnds,omitempty"`
 }
 
 // parses a recognition sequence into a hangInd, cutInd for overhang calculation.
 func newEnzyme(name, recogSeq string) enzyme {
+	if strings.Contains(recogSeq, "(") {
+		if offsetSeq, err := parseCutOffsets(recogSeq); err == nil {
+			recogSeq = offsetSeq
+		}
+	}
+
 	cutIndex := strings.Index(recogSeq, "^")
 	hangIndex := strings.Index(recogSeq, "_")
 
@@ -70,17 +86,44 @@ func newEnzyme(name, recogSeq string) enzyme {
 	}
 }
 
+// linearBackbone returns a backbone that's already linear, like a PCR product or a plasmid
+// that was cut beforehand, as it is: without digesting it. It has no overhangs.
+func linearBackbone(frag *Frag) (linearized *Frag, backbone *Backbone, err error) {
+	seq := strings.ToUpper(frag.Seq)
+	if half := len(seq) / 2; seq[:half] == seq[half:] {
+		seq = seq[:half] // undo the doubling of sequence for circular parts
+	}
+	if seq == "" {
+		return &Frag{}, &Backbone{}, fmt.Errorf("backbone %s has no sequence", frag.ID)
+	}
+
+	return &Frag{
+			ID:       frag.ID,
+			uniqueID: "backbone",
+			Seq:      seq,
+			fragType: linear,
+			db:       frag.db,
+		},
+		&Backbone{
+			URL:      parseURL(frag.ID, frag.db),
+			Seq:      seq,
+			Enzymes:  []string{},
+			Cutsites: []int{},
+			Strands:  []bool{},
+		},
+		nil
+}
+
 // digest a Frag (backbone) with an enzyme's first recogition site
 //
-// remove the 5' end of the fragment post-cleaving. it will be degraded.
-// keep exposed 3' ends. good visual explanation:
+// by default, remove the 5' overhangs of the fragment post-cleaving. they're degraded
+// by the exonuclease in a Gibson assembly. keep exposed 3' ends. good visual explanation:
 // https://warwick.ac.uk/study/csde/gsp/eportfolio/directory/pg/lsujcw/gibsonguide/
-func digest(frag *Frag, enzymes []enzyme) (digested *Frag, backbone *Backbone, err error) {
-	wrappedBp := 38 // largest current recognition site in the list of enzymes
-	if len(frag.Seq) < wrappedBp {
-		return &Frag{}, &Backbone{}, fmt.Errorf("%s is too short for digestion", frag.ID)
-	}
-
+//
+// if keep5 is true, the 5' overhangs are kept, as they are for ligation. the digested
+// fragment then spans from the 5' end of the top strand to that of the bottom strand.
+// blunt cuts have no overhang and are the same either way.
+func digest(frag *Frag, enzymes []enzyme, keep5 bool) (digested *Frag, backbone *Backbone, err error) {
 	frag.Seq = strings.ToUpper(frag.Seq)
 	firstHalf := frag.Seq[:len(frag.Seq)/2]
 	secondHalf := frag.Seq[len(frag.Seq)/2:]
@@ -89,6 +132,11 @@ func digest(frag *Frag, enzymes []enzyme) (digested *Frag, backbone *Backbone, e
 		frag.Seq = frag.Seq[:len(frag.Seq)/2] // undo the doubling of sequence for circular parts
 	}
 
+	wrappedBp := 38 // largest current recognition site in the list of enzymes
+	if err := checkLength("backbone", frag, wrappedBp); err != nil {
+		return &Frag{}, &Backbone{}, err
+	}
+
 	// find all the cutsites
 	cuts, lengths := cutsites(frag.Seq, enzymes)
 
@@ -111,25 +159,37 @@ func digest(frag *Frag, enzymes []enzyme) (digested *Frag, backbone *Backbone, e
 		if overhangLength >= 0 {
 			cutIndex := (cut.index + cut.enzyme.seqCutIndex) % len(frag.Seq)
 			digestedSeq = frag.Seq[cutIndex:] + frag.Seq[:cutIndex]
+		} else if keep5 {
+			// from the top strand's 5' overhang to the bottom's, across the zero index
+			seqIndex := (cut.index + cut.enzyme.seqCutIndex) % len(frag.Seq)
+			doubled := frag.Seq + frag.Seq
+			digestedSeq = doubled[seqIndex : seqIndex+len(frag.Seq)-overhangLength]
 		} else {
 			bottomIndex := (cut.index + cut.enzyme.seqCutIndex) % len(frag.Seq)
 			topIndex := (cut.index + cut.enzyme.compCutIndex) % len(frag.Seq)
 			digestedSeq = frag.Seq[topIndex:] + frag.Seq[:bottomIndex]
 		}
 
+		hangs, hangLengths, hangEnds := overhangs(frag.Seq, cuts[:1])
 		return &Frag{
-				ID:       frag.ID,
-				uniqueID: "backbone",
-				Seq:      digestedSeq,
-				fragType: linear,
-				db:       frag.db,
+				ID:              frag.ID,
+				uniqueID:        "backbone",
+				Seq:             digestedSeq,
+				Overhangs:       hangs,
+				OverhangLengths: hangLengths,
+				OverhangEnds:    hangEnds,
+				fragType:        linear,
+				db:              frag.db,
 			},
 			&Backbone{
-				URL:      parseURL(frag.ID, frag.db),
-				Seq:      frag.Seq,
-				Enzymes:  []string{cut.enzyme.name},
-				Cutsites: []int{cut.index},
-				Strands:  []bool{cut.strand},
+				URL:             parseURL(frag.ID, frag.db),
+				Seq:             frag.Seq,
+				Enzymes:         []string{cut.enzyme.name},
+				Cutsites:        []int{cut.index},
+				Strands:         []bool{cut.strand},
+				Overhangs:       hangs,
+				OverhangLengths: hangLengths,
+				OverhangEnds:    hangEnds,
 			},
 			nil
 	}
@@ -153,7 +213,7 @@ func digest(frag *Frag, enzymes []enzyme) (digested *Frag, backbone *Backbone, e
 	}
 
 	cut2Index := cut2.index + cut2.enzyme.compCutIndex
-	if cut2.enzyme.seqCutIndex-cut2.enzyme.compCutIndex < 0 {
+	if cut2.enzyme.seqCutIndex-cut2.enzyme.compCutIndex < 0 && !keep5 {
 		cut2Index = cut2.index + cut2.enzyme.seqCutIndex
 	}
 
@@ -162,30 +222,178 @@ func digest(frag *Frag, enzymes []enzyme) (digested *Frag, backbone *Backbone, e
 	}
 
 	digestedSeq := doubled[cut1Index:cut2Index]
+	hangs, hangLengths, hangEnds := overhangs(frag.Seq, []cut{cut1, cut2})
 
 	return &Frag{
-			ID:       frag.ID,
-			uniqueID: "backbone",
-			Seq:      digestedSeq,
-			fragType: linear,
-			db:       frag.db,
+			ID:              frag.ID,
+			uniqueID:        "backbone",
+			Seq:             digestedSeq,
+			Overhangs:       hangs,
+			OverhangLengths: hangLengths,
+			OverhangEnds:    hangEnds,
+			fragType:        linear,
+			db:              frag.db,
 		},
 		&Backbone{
-			URL:      parseURL(frag.ID, frag.db),
-			Seq:      frag.Seq,
-			Enzymes:  []string{cut1.enzyme.name, cut2.enzyme.name},
-			Cutsites: []int{cut1Index, cut2Index},
-			Strands:  []bool{cut1.strand, cut2.strand},
+			URL:             parseURL(frag.ID, frag.db),
+			Seq:             frag.Seq,
+			Enzymes:         []string{cut1.enzyme.name, cut2.enzyme.name},
+			Cutsites:        []int{cut1Index, cut2Index},
+			Strands:         []bool{cut1.strand, cut2.strand},
+			Overhangs:       hangs,
+			OverhangLengths: hangLengths,
+			OverhangEnds:    hangEnds,
 		},
 		nil
 }
 
+// overhangs returns the top strand sequence of the single stranded overhang left by each
+// cut in the circular sequence, its length, and whether it's a 5' or 3' overhang. The
+// enzyme leaves a 3' overhang if it cuts its recognition site's strand after the complement.
+// Blunt cuts have no overhang and are skipped.
+func overhangs(seq string, cuts []cut) (hangs []string, lengths []int, ends []string) {
+	doubled := strings.ToUpper(seq + seq)
+	for _, c := range cuts {
+		first, last := c.enzyme.seqCutIndex, c.enzyme.compCutIndex
+		end := "5'"
+		if first > last {
+			first, last = last, first
+			end = "3'"
+		}
+		if first == last {
+			continue
+		}
+
+		lengths = append(lengths, last-first)
+		ends = append(ends, end)
+
+		if c.strand {
+			hangs = append(hangs, doubled[c.index+first:c.index+last])
+			continue
+		}
+
+		// the site is on the bottom strand, so are the cut indexes
+		siteEnd := c.index + len(c.enzyme.recog) + len(seq)
+		hangs = append(hangs, doubled[siteEnd-last:siteEnd-first])
+	}
+
+	return
+}
+
+// product is a fragment of a circular sequence after it's digested by one or more enzymes.
+type product struct {
+	// start and end (exclusive) of the product's top strand, end may be past the zero-index
+	start, end int
+
+	// whether the product is the whole, uncut, circular sequence
+	circular bool
+
+	// whether it's a product of complete, rather than partial, digestion
+	complete bool
+}
+
+// digestProducts returns every product from digesting a circular sequence with enzymes.
+// Products of complete digestion are between neighboring cutsites. Partial products span
+// uncut sites between them and the uncut sequence itself is circular. Complete products
+// are first and each set is sorted by length, largest first.
+func digestProducts(seq string, enzymes []enzyme) (products []product) {
+	seq = strings.ToUpper(seq)
+	cuts, _ := cutsites(seq, enzymes)
+
+	// the top strand index of each cut, a site may be found by more than one enzyme
+	seen := make(map[int]bool)
+	cutIndexes := []int{}
+	for _, c := range cuts {
+		index := c.index + c.enzyme.seqCutIndex
+		if !c.strand {
+			index = c.index + len(c.enzyme.recog) - c.enzyme.compCutIndex
+		}
+		index = (index + len(seq)) % len(seq)
+
+		if !seen[index] {
+			seen[index] = true
+			cutIndexes = append(cutIndexes, index)
+		}
+	}
+	sort.Ints(cutIndexes)
+
+	if len(cutIndexes) == 0 {
+		return nil
+	}
+
+	// span every number of neighboring bands, up to the full length linearized sequence
+	for span := 1; span <= len(cutIndexes); span++ {
+		for i, start := range cutIndexes {
+			end := cutIndexes[(i+span)%len(cutIndexes)]
+			if end <= start {
+				end += len(seq)
+			}
+			products = append(products, product{start: start, end: end, complete: span == 1})
+		}
+	}
+	products = append(products, product{start: 0, end: len(seq), circular: true})
+
+	sort.SliceStable(products, func(i, j int) bool {
+		if products[i].complete != products[j].complete {
+			return products[i].complete
+		}
+		return products[i].end-products[i].start > products[j].end-products[j].start
+	})
+
+	return
+}
+
+// Products writes the products from digesting the backbone with its enzymes to stdout.
+// It's a diagnostic for picking the band to gel-purify.
+func Products(flags *Flags) {
+	if flags.backboneMeta == nil || flags.backboneMeta.Seq == "" || len(flags.backboneMeta.Enzymes) == 0 {
+		stderr.Fatalln("must pass a backbone and enzymes to list the products of its digestion")
+	}
+
+	// the same enzyme may be on both sides of the backbone
+	enzymes := []enzyme{}
+	seen := make(map[string]bool)
+	for _, e := range backboneEnzymes(flags.backboneMeta) {
+		if !seen[e.name] {
+			seen[e.name] = true
+			enzymes = append(enzymes, e)
+		}
+	}
+
+	seq := flags.backboneMeta.Seq
+	products := digestProducts(seq, enzymes)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+	fmt.Fprintf(tw, "\n%s products (%d)\tstart\tend\tlength\ttopology\tdigestion\t\n", flags.backbone.ID, len(products))
+	for _, p := range products {
+		topology, digestion := "linear", "partial"
+		if p.circular {
+			topology = "circular"
+		}
+		if p.complete {
+			digestion = "complete"
+		}
+
+		end := p.end % len(seq)
+		if end == 0 {
+			end = len(seq)
+		}
+
+		fmt.Fprintf(tw, "\t%d\t%d\t%d\t%s\t%s\t\n", p.start+1, end, p.end-p.start, topology, digestion)
+	}
+	tw.Flush()
+}
+
 // cutsites finds all the cutsites of a list of enzymes against a target sequence
 // also returns the lengths of each "band" of DNA after digestion. Each band length
 // corresponds to the band formed with the start of the enzyme at the same index in cuts
 func cutsites(seq string, enzymes []enzyme) (cuts []cut, lengths []int) {
+	return cutsitesRC(seq, reverseComplement(seq), enzymes)
+}
+
+// cutsitesRC is cutsites with the reverse complement of the sequence, for a sequence
+// that's searched for the cutsites of each of many enzymes, like in autoEnzyme
+func cutsitesRC(seq, rcs string, enzymes []enzyme) (cuts []cut, lengths []int) {
 	s := seq
-	rcs := reverseComplement(seq)
 
 	for _, enzyme := range enzymes {
 		regexRecognition := recogRegex(enzyme.recog)
@@ -197,13 +405,19 @@ func cutsites(seq string, enzymes []enzyme) (cuts []cut, lengths []int) {
 		}
 
 		// if it's a palindrome enzyme, don't scan over it again
-		if reverseComplement(regexRecognition) == regexRecognition {
+		if palindromic(enzyme.recog) {
 			continue
 		}
 
 		for _, submatch := range reg.FindAllStringSubmatchIndex(rcs, -1) {
 			index := submatch[0]
 			index = len(seq) - index - len(enzyme.recog)
+
+			// a site that's self-complementary at this locus was already found on the top strand
+			if seenCut(cuts, index, enzyme) {
+				continue
+			}
+
 			cuts = append(cuts, cut{index: index, enzyme: enzyme, strand: false})
 		}
 	}
@@ -221,6 +435,272 @@ func cutsites(seq string, enzymes []enzyme) (cuts []cut, lengths []int) {
 	return
 }
 
+// enzymeRejection is a candidate enzyme that can't linearize a backbone on its own, and why
+type enzymeRejection struct {
+	// name of the enzyme
+	name string
+
+	// reason is "no site", "cuts backbone" or "cuts insert"
+	reason string
+
+	// detail is the number of the backbone's sites or the sites in the inserts
+	detail string
+}
+
+// autoEnzyme returns the first of the candidate enzymes that cuts the backbone once and
+// none of the inserts, so it linearizes the backbone with a single clean cut. The others
+// are returned with why they were rejected. It's an error if none of them can be used.
+func autoEnzyme(backbone *Frag, candidates []enzyme, inserts []*Frag, keep5 bool) (chosen enzyme, rejected []enzymeRejection, err error) {
+	seq := strings.ToUpper(backbone.Seq)
+	if half := len(seq) / 2; seq[:half] == seq[half:] {
+		seq = seq[:half] // undo the doubling of circular sequences
+	}
+
+	// each sequence's reverse complement is searched for every candidate
+	seqRC := reverseComplement(seq)
+	insertSeqs := make([]string, len(inserts))
+	insertRCs := make([]string, len(inserts))
+	for i, insert := range inserts {
+		insertSeqs[i] = strings.ToUpper(insert.Seq)
+		insertRCs[i] = reverseComplement(insertSeqs[i])
+	}
+
+	found := false
+	for _, e := range candidates {
+		cuts, _ := cutsitesRC(seq, seqRC, []enzyme{e})
+		if len(cuts) == 0 {
+			rejected = append(rejected, enzymeRejection{e.name, "no site", ""})
+			continue
+		}
+		if len(cuts) > 1 {
+			rejected = append(rejected, enzymeRejection{e.name, "cuts backbone", strconv.Itoa(len(cuts))})
+			continue
+		}
+
+		var insertSites []string
+		for i, insert := range inserts {
+			insertCuts, _ := cutsitesRC(insertSeqs[i], insertRCs[i], []enzyme{e})
+			for _, c := range insertCuts {
+				insertSites = append(insertSites, fmt.Sprintf("%s at bp %d", insert.ID, c.index+1))
+			}
+		}
+		if len(insertSites) > 0 {
+			rejected = append(rejected, enzymeRejection{e.name, "cuts insert", strings.Join(insertSites, ", ")})
+			continue
+		}
+
+		if _, _, err := digest(&Frag{ID: backbone.ID, Seq: seq}, []enzyme{e}, keep5); err != nil {
+			rejected = append(rejected, enzymeRejection{e.name, "no site", ""})
+			continue
+		}
+
+		if !found {
+			chosen, found = e, true
+		}
+	}
+
+	if !found {
+		return enzyme{}, rejected, fmt.Errorf("none of the %d candidate enzymes cut %s once without cutting the inserts", len(candidates), backbone.ID)
+	}
+	return chosen, rejected, nil
+}
+
+// logAutoEnzyme logs the enzyme chosen to linearize the backbone and, by reason, the
+// candidates that were rejected. Enzymes without a site in the backbone are only counted.
+func logAutoEnzyme(backboneID string, chosen enzyme, rejected []enzymeRejection) {
+	if chosen.name != "" {
+		stderr.Printf("chose %s to linearize %s: it cuts %s once and none of the inserts\n", chosen.name, backboneID, backboneID)
+	}
+
+	var noSite int
+	var cutsBackbone, cutsInsert []string
+	for _, r := range rejected {
+		switch r.reason {
+		case "no site":
+			noSite++
+		case "cuts backbone":
+			cutsBackbone = append(cutsBackbone, fmt.Sprintf("%s (%s sites)", r.name, r.detail))
+		case "cuts insert":
+			cutsInsert = append(cutsInsert, fmt.Sprintf("%s (%s)", r.name, r.detail))
+		}
+	}
+
+	if len(cutsBackbone) > 0 {
+		stderr.Printf("rejected %d enzymes that cut %s more than once: %s\n", len(cutsBackbone), backboneID, strings.Join(cutsBackbone, ", "))
+	}
+	if len(cutsInsert) > 0 {
+		stderr.Printf("rejected %d enzymes that cut the inserts: %s\n", len(cutsInsert), strings.Join(cutsInsert, ", "))
+	}
+	if noSite > 0 {
+		stderr.Printf("rejected %d enzymes without a site in %s\n", noSite, backboneID)
+	}
+}
+
+// backboneEnzymes returns the enzymes used to linearize the backbone. They're either
+// named in the enzyme db or are named by their recognition sequence.
+func backboneEnzymes(backbone *Backbone) (enzymes []enzyme) {
+	if backbone == nil || len(backbone.Enzymes) == 0 {
+		return nil
+	}
+
+	enzymeDB := NewEnzymeDB()
+	for _, name := range backbone.Enzymes {
+		if canonical, recogSeq, exists := enzymeDB.named(name); exists {
+			enzymes = append(enzymes, newEnzyme(canonical, recogSeq))
+		} else if recogSeq, err := validRecogSeq(name); err == nil {
+			enzymes = append(enzymes, newEnzyme(name, recogSeq))
+		}
+	}
+
+	return
+}
+
+// avoidEnzymes returns the enzymes, sorted by name, whose sites are removed from
+// synthetic fragments.
+func avoidEnzymes(conf *config.Config) (enzymes []enzyme) {
+	for name, recogSeq := range conf.AvoidSites {
+		enzymes = append(enzymes, newEnzyme(name, recogSeq))
+	}
+
+	sort.Slice(enzymes, func(i, j int) bool {
+		return enzymes[i].name < enzymes[j].name
+	})
+
+	return
+}
+
+// checkSites checks a sequence for recognition sites of the enzymes. The sites are
+// logged as a warning or, if strict, returned as an error.
+func checkSites(name, seq string, enzymes []enzyme, strict bool) error {
+	if len(enzymes) == 0 {
+		return nil
+	}
+
+	cuts, _ := cutsites(strings.ToUpper(seq), enzymes)
+	if len(cuts) == 0 {
+		return nil
+	}
+
+	sites := []string{}
+	for _, c := range cuts {
+		sites = append(sites, fmt.Sprintf("%s at bp %d", c.enzyme.name, c.index+1))
+	}
+
+	err := fmt.Errorf(
+		"%s has sites of the enzymes used to linearize the backbone: %s",
+		name,
+		strings.Join(sites, ", "),
+	)
+	if strict {
+		return err
+	}
+
+	stderr.Printf("warning: %v\n", err)
+	return nil
+}
+
+// cutOffsets is for recognition sequences with cuts written as offsets from the site
+// Eg: BsaI's GGTCTC(1/5) or BcgI's upstream cuts, (10/12)CGANNNNNNTGC
+var cutOffsets = regexp.MustCompile(`^(?:\((-?\d+)/(-?\d+)\))?([A-Z]+)(?:\((-?\d+)/(-?\d+)\))?$`)
+
+// parseCutOffsets converts a recognition sequence with its cuts as offsets, like
+// GGTCTC(1/5), to one with "^" and "_" cut sites, like GGTCTCN^NNNN_. Offsets after
+// the sequence are from its end, and offsets before it are upstream from its start.
+// The sequence is padded with Ns for cuts outside of it.
+func parseCutOffsets(seq string) (string, error) {
+	seq = strings.ToUpper(strings.Replace(seq, " ", "", -1))
+	invalidErr := fmt.Errorf("%s is not a valid enzyme recognition sequence with cut offsets. Ex: GGTCTC(1/5)", seq)
+
+	m := cutOffsets.FindStringSubmatch(seq)
+	if m == nil || (m[1] == "") == (m[4] == "") {
+		return "", invalidErr // need offsets on exactly one side of the site
+	}
+
+	recog := m[3]
+	var seqCut, compCut int
+	if m[1] != "" {
+		top, _ := strconv.Atoi(m[1])
+		bottom, _ := strconv.Atoi(m[2])
+		seqCut, compCut = -top, -bottom
+	} else {
+		top, _ := strconv.Atoi(m[4])
+		bottom, _ := strconv.Atoi(m[5])
+		seqCut, compCut = len(recog)+top, len(recog)+bottom
+	}
+
+	// pad the recognition sequence for cuts outside of it
+	if first := seqCut; first < 0 || compCut < 0 {
+		if compCut < first {
+			first = compCut
+		}
+		recog = strings.Repeat("N", -first) + recog
+		seqCut -= first
+		compCut -= first
+	}
+	if last := seqCut; last > len(recog) || compCut > len(recog) {
+		if compCut > last {
+			last = compCut
+		}
+		recog += strings.Repeat("N", last-len(recog))
+	}
+
+	// add the later of the two cut sites first so the earlier's index is unchanged
+	if seqCut > compCut {
+		return recog[:compCut] + "_" + recog[compCut:seqCut] + "^" + recog[seqCut:], nil
+	}
+	return recog[:seqCut] + "^" + recog[seqCut:compCut] + "_" + recog[compCut:], nil
+}
+
+// validRecogSeq cleans a recognition sequence and checks that it has both a cut site
+// in the template sequence, "^", and a cut site in the complement sequence, "_".
+func validRecogSeq(seq string) (string, error) {
+	seq = strings.ToUpper(seq)
+
+	if strings.Contains(seq, "(") {
+		var err error
+		if seq, err = parseCutOffsets(seq); err != nil {
+			return "", err
+		}
+	}
+
+	invalidChars := regexp.MustCompile("[^ATGCMRWYSKHDVBNX_\\^]")
+	seq = invalidChars.ReplaceAllString(seq, "")
+
+	if strings.Count(seq, "^") != 1 || strings.Count(seq, "_") != 1 {
+		return "", fmt.Errorf("%s is not a valid enzyme recognition sequence. see 'repp find enzyme --help'", seq)
+	}
+
+	return seq, nil
+}
+
+// palindromic returns whether an enzyme's recognition sequence is its own reverse complement.
+// Eg: EcoRI's GAATTC. These sites would otherwise be found once on each strand.
+func palindromic(recog string) bool {
+	return reverseComplement(recog) == strings.ToUpper(recog)
+}
+
+// siteSeq returns the bp of an enzyme's recognition site, without the Ns that pad it
+// out to cuts outside the site. Errors if the site has ambiguous bp within it.
+func siteSeq(e enzyme) (string, error) {
+	site := strings.Trim(strings.ToUpper(e.recog), "N")
+	if site == "" || strings.Trim(site, "ACGT") != "" {
+		return "", fmt.Errorf("%s's site, %s, has ambiguous bp and can't be added to a primer", e.name, e.recog)
+	}
+
+	return site, nil
+}
+
+// seenCut returns whether the enzyme already has a cut at the index.
+func seenCut(cuts []cut, index int, e enzyme) bool {
+	for _, c := range cuts {
+		if c.index == index && c.enzyme.name == e.name && c.enzyme.recog == e.recog {
+			return true
+		}
+	}
+
+	return false
+}
+
 // recogRegex turns a recognition sequence into a regex sequence for searching
 // sequence for searching the sequence for digestion sites.
 func recogRegex(recog string) (decoded string) {
@@ -255,28 +735,79 @@ func recogRegex(recog string) (decoded string) {
 type EnzymeDB struct {
 	// enzymes is a map between a enzymes name and its sequence
 	enzymes map[string]string
+
+	// aliases is a map from an enzyme's other names, like its isoschizomers, to its name
+	aliases map[string]string
 }
 
-// NewEnzymeDB returns a new copy of the enzymes db.
+// NewEnzymeDB returns a new copy of the enzymes db. Each row is an enzyme's name, its
+// recognition sequence and, optionally, a comma separated list of its aliases.
 func NewEnzymeDB() *EnzymeDB {
 	enzymeFile, err := os.Open(config.EnzymeDB)
-	if err != nil {
+	if os.IsNotExist(err) {
+		return &EnzymeDB{enzymes: make(map[string]string), aliases: make(map[string]string)} // created on first set
+	} else if err != nil {
 		stderr.Fatal(err)
 	}
 
 	// https://golang.org/pkg/bufio/#example_Scanner_lines
 	scanner := bufio.NewScanner(enzymeFile)
 	enzymes := make(map[string]string)
+	aliases := make(map[string]string)
 	for scanner.Scan() {
 		columns := strings.Split(scanner.Text(), "	")
+		if len(columns) < 2 {
+			continue
+		}
 		enzymes[columns[0]] = columns[1] // enzyme name = enzyme seq
+		if len(columns) > 2 {
+			for _, alias := range strings.Split(columns[2], ",") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					aliases[alias] = columns[0]
+				}
+			}
+		}
 	}
 
 	if err := enzymeFile.Close(); err != nil {
 		stderr.Fatal(err)
 	}
 
-	return &EnzymeDB{enzymes: enzymes}
+	return &EnzymeDB{enzymes: enzymes, aliases: aliases}
+}
+
+// named returns the name and recognition sequence of the enzyme with the name or alias.
+// Names are matched before aliases.
+func (f *EnzymeDB) named(name string) (canonical, recogSeq string, exists bool) {
+	if recogSeq, exists = f.enzymes[name]; exists {
+		return name, recogSeq, true
+	}
+	if canonical, exists = f.aliases[name]; exists {
+		recogSeq, exists = f.enzymes[canonical]
+		return canonical, recogSeq, exists
+	}
+	return "", "", false
+}
+
+// aliasesOf returns the sorted aliases of the enzyme.
+func (f *EnzymeDB) aliasesOf(name string) (aliases []string) {
+	for alias, canonical := range f.aliases {
+		if canonical == name {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return
+}
+
+// row returns the enzyme's row in the enzymes db: its name, recognition sequence
+// and aliases, if it has any, separated by tabs.
+func (f *EnzymeDB) row(name string) string {
+	row := name + "\t" + f.enzymes[name]
+	if aliases := f.aliasesOf(name); len(aliases) > 0 {
+		row += "\t" + strings.Join(aliases, ",")
+	}
+	return row
 }
 
 // ReadCmd returns enzymes that are similar in name to the enzyme name requested.
@@ -296,7 +827,7 @@ func (f *EnzymeDB) ReadCmd(cmd *cobra.Command, args []string) {
 		sort.Strings(enzymeNames)
 
 		for _, name := range enzymeNames {
-			fmt.Fprintf(w, "%s\t%s\n", name, f.enzymes[name])
+			fmt.Fprintln(w, f.row(name))
 		}
 		w.Flush()
 		return
@@ -304,32 +835,24 @@ func (f *EnzymeDB) ReadCmd(cmd *cobra.Command, args []string) {
 
 	name := args[0]
 
-	// if there's an exact match, just log that one
-	if seq, exists := f.enzymes[name]; exists {
-		fmt.Printf("%s	%s\n", name, seq)
+	// if there's an exact match, by name or alias, just log that one
+	if canonical, _, exists := f.named(name); exists {
+		fmt.Println(f.row(canonical))
 		return
 	}
 
-	ldCutoff := 2
-	containing := []string{}
-	lowDistance := []string{}
-
-	for fName, fSeq := range f.enzymes {
-		if strings.Contains(fName, name) {
-			containing = append(containing, fName+"\t"+fSeq)
-		} else if len(fName) > ldCutoff && ld(name, fName, true) <= ldCutoff {
-			lowDistance = append(lowDistance, fName+"\t"+fSeq)
-		}
+	maxDistance, err := cmd.Flags().GetInt("max-distance")
+	if err != nil {
+		maxDistance = 2
 	}
 
-	if len(containing) < 3 {
-		lowDistance = append(lowDistance, containing...)
-		containing = []string{} // clear
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		limit = 0
 	}
-	if len(containing) > 0 {
-		fmt.Fprintf(w, strings.Join(containing, "\n"))
-	} else if len(lowDistance) > 0 {
-		fmt.Fprintf(w, strings.Join(lowDistance, "\n"))
+
+	if matches := searchNames(name, f.enzymes, maxDistance, limit); len(matches) > 0 {
+		fmt.Fprintf(w, strings.Join(matches, "\n"))
 	} else {
 		fmt.Fprintf(w, fmt.Sprintf("failed to find any enzymes for %s", name))
 	}
@@ -337,6 +860,81 @@ func (f *EnzymeDB) ReadCmd(cmd *cobra.Command, args []string) {
 	w.Flush()
 }
 
+// searchNames returns "name\tseq" rows for entries whose names contain the query or are
+// beneath a levenshtein distance from it. Rows within the distance are sorted with the
+// closest match first. At most limit rows are returned, all if the limit is less than one.
+func searchNames(query string, entries map[string]string, maxDistance, limit int) (matches []string) {
+	containing := []string{}
+	type lowDistanceMatch struct {
+		name     string
+		distance int
+	}
+	lowDistance := []lowDistanceMatch{}
+
+	for name := range entries {
+		if strings.Contains(name, query) {
+			containing = append(containing, name)
+		} else if len(name) > maxDistance {
+			if distance := ld(query, name, true); distance <= maxDistance {
+				lowDistance = append(lowDistance, lowDistanceMatch{name, distance})
+			}
+		}
+	}
+	sort.Strings(containing)
+
+	// with few entries containing the query, list them with the similarly named ones
+	if len(containing) < 3 {
+		for _, name := range containing {
+			lowDistance = append(lowDistance, lowDistanceMatch{name, ld(query, name, true)})
+		}
+		containing = []string{} // clear
+	}
+
+	sort.Slice(lowDistance, func(i, j int) bool {
+		if lowDistance[i].distance == lowDistance[j].distance {
+			return lowDistance[i].name < lowDistance[j].name
+		}
+		return lowDistance[i].distance < lowDistance[j].distance
+	})
+
+	names := containing
+	if len(names) == 0 {
+		for _, m := range lowDistance {
+			names = append(names, m.name)
+		}
+	}
+
+	for _, name := range names {
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+		matches = append(matches, name+"\t"+entries[name])
+	}
+
+	return
+}
+
+// suggestNames returns a "did you mean" hint with the (at most three) entry names from
+// searchNames for a query that wasn't found. Names further than a third of the query's
+// length away aren't suggested. An empty string is returned if there are no close names.
+func suggestNames(query string, entries map[string]string) string {
+	maxDistance := len(query) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	hints := []string{}
+	for _, match := range searchNames(query, entries, maxDistance, 3) {
+		name := strings.Split(match, "\t")[0]
+		hints = append(hints, fmt.Sprintf("%s? (distance %d)", name, ld(query, name, true)))
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+
+	return "did you mean " + strings.Join(hints, ", ")
+}
+
 // SetCmd the enzyme's seq in the database (or create if it isn't in the enzyme db).
 func (f *EnzymeDB) SetCmd(cmd *cobra.Command, args []string) {
 	if len(args) < 2 {
@@ -350,20 +948,46 @@ func (f *EnzymeDB) SetCmd(cmd *cobra.Command, args []string) {
 		name = strings.Join(args[:len(args)-1], " ")
 		seq = args[len(args)-1]
 	}
-	seq = strings.ToUpper(seq)
 
-	invalidChars := regexp.MustCompile("[^ATGCMRWYSKHDVBNX_\\^]")
-	seq = invalidChars.ReplaceAllString(seq, "")
+	seq, err := validRecogSeq(seq)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
 
-	if strings.Count(seq, "^") != 1 || strings.Count(seq, "_") != 1 {
-		stderr.Fatalf("%s is not a valid enzyme recognition sequence. see 'repp find enzyme --help'\n", seq)
+	// an alias updates the enzyme it's an alias of
+	if canonical, _, exists := f.named(name); exists {
+		name = canonical
 	}
 
-	enzymeFile, err := os.Open(config.EnzymeDB)
+	// replace the enzyme's aliases if they're set, otherwise keep the old ones
+	if cmd.Flags().Changed("aliases") {
+		aliasList, _ := cmd.Flags().GetString("aliases")
+		aliases := (&inputParser{}).parseCommaList(aliasList)
+		for _, alias := range aliases {
+			if _, exists := f.enzymes[alias]; exists && alias != name {
+				stderr.Fatalf("failed to set alias %s of %s, it's the name of another enzyme", alias, name)
+			}
+			if canonical, exists := f.aliases[alias]; exists && canonical != name {
+				stderr.Fatalf("failed to set alias %s of %s, it's an alias of %s", alias, name, canonical)
+			}
+		}
+
+		for _, alias := range f.aliasesOf(name) {
+			delete(f.aliases, alias)
+		}
+		for _, alias := range aliases {
+			f.aliases[alias] = name
+		}
+	}
+
+	enzymeFile, err := openOrCreate(config.EnzymeDB)
 	if err != nil {
 		stderr.Fatal(err)
 	}
 
+	// update in memory
+	f.enzymes[name] = seq
+
 	// https://golang.org/pkg/bufio/#example_Scanner_lines
 	var output strings.Builder
 	updated := false
@@ -371,16 +995,16 @@ func (f *EnzymeDB) SetCmd(cmd *cobra.Command, args []string) {
 	for scanner.Scan() {
 		columns := strings.Split(scanner.Text(), "	")
 		if columns[0] == name {
-			output.WriteString(fmt.Sprintf("%s	%s\n", name, seq))
+			output.WriteString(f.row(name) + "\n")
 			updated = true
 		} else {
-			output.WriteString(scanner.Text())
+			output.WriteString(scanner.Text() + "\n")
 		}
 	}
 
 	// create from nothing
 	if !updated {
-		output.WriteString(fmt.Sprintf("%s	%s\n", name, seq))
+		output.WriteString(f.row(name) + "\n")
 	}
 
 	if err := enzymeFile.Close(); err != nil {
@@ -394,9 +1018,6 @@ func (f *EnzymeDB) SetCmd(cmd *cobra.Command, args []string) {
 	if updated {
 		fmt.Printf("updated %s in the enzymes database\n", name)
 	}
-
-	// update in memory
-	f.enzymes[name] = seq
 }
 
 // DeleteCmd the enzyme from the database
@@ -443,6 +1064,9 @@ func (f *EnzymeDB) DeleteCmd(cmd *cobra.Command, args []string) {
 
 	// delete from memory
 	delete(f.enzymes, name)
+	for _, alias := range f.aliasesOf(name) {
+		delete(f.aliases, alias)
+	}
 
 	if deleted {
 		fmt.Printf("deleted %s from the enzymes database\n", name)
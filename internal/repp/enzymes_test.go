@@ -1,8 +1,13 @@
 package repp
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/jjtimmons/repp/config"
 )
 
 func Test_recogRegex(t *testing.T) {
@@ -36,6 +41,342 @@ func Test_recogRegex(t *testing.T) {
 	}
 }
 
+func Test_cutsites(t *testing.T) {
+	type args struct {
+		seq     string
+		enzymes []enzyme
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCuts []int
+	}{
+		{
+			"palindromic EcoRI site is only cut once",
+			args{
+				seq:     "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTC",
+				enzymes: []enzyme{newEnzyme("EcoRI", "G^AATT_C")},
+			},
+			[]int{24},
+		},
+		{
+			"degenerate palindromic PpuMI site is only cut once",
+			args{
+				seq:     "ATGAGGTTAGCCAAAAAAGCACGTAGGACCTGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTC",
+				enzymes: []enzyme{newEnzyme("PpuMI", "RG^GWC_CY")},
+			},
+			[]int{24},
+		},
+		{
+			"non-palindromic site is cut on both strands",
+			args{
+				seq:     "ATGAGGTTAGCCCAGCAAAAAAGCACGTGCTGGGGGTGGCGCCCACCGACTGTTCCCAAACTG",
+				enzymes: []enzyme{enzyme{recog: "CCCAGC", compCutIndex: 1, seqCutIndex: 5}},
+			},
+			[]int{10, 28},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCuts, _ := cutsites(tt.args.seq, tt.args.enzymes)
+
+			gotIndexes := []int{}
+			for _, c := range gotCuts {
+				gotIndexes = append(gotIndexes, c.index)
+			}
+
+			if !reflect.DeepEqual(gotIndexes, tt.wantCuts) {
+				t.Errorf("cutsites() = %v, want %v", gotIndexes, tt.wantCuts)
+			}
+		})
+	}
+}
+
+func Test_validRecogSeq(t *testing.T) {
+	tests := []struct {
+		name    string
+		seq     string
+		want    string
+		wantErr bool
+	}{
+		{
+			"valid EcoRI",
+			"g^aatt_c",
+			"G^AATT_C",
+			false,
+		},
+		{
+			"strips invalid characters",
+			" G^AATT_C ",
+			"G^AATT_C",
+			false,
+		},
+		{
+			"missing complement cut site",
+			"G^AATTC",
+			"",
+			true,
+		},
+		{
+			"cut offsets",
+			"GGTCTC(1/5)",
+			"GGTCTCN^NNNN_",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validRecogSeq(tt.seq)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validRecogSeq() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("validRecogSeq() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseCutOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		seq     string
+		want    string
+		wantErr bool
+	}{
+		{
+			"BsaI",
+			"GGTCTC(1/5)",
+			"GGTCTCN^NNNN_",
+			false,
+		},
+		{
+			"BsmBI",
+			"cgtctc(1/5)",
+			"CGTCTCN^NNNN_",
+			false,
+		},
+		{
+			"negative offsets within the site",
+			"GAATTC(-5/-1)",
+			"G^AATT_C",
+			false,
+		},
+		{
+			"complement cut before template cut",
+			"GAATGC(1/-1)",
+			"GAATG_CN^",
+			false,
+		},
+		{
+			"cuts upstream of the site",
+			"(3/1)GAATTC",
+			"^NN_NGAATTC",
+			false,
+		},
+		{
+			"missing complement offset",
+			"GGTCTC(1)",
+			"",
+			true,
+		},
+		{
+			"offsets on both sides",
+			"(10/12)CGANNNNNNTGC(12/10)",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCutOffsets(tt.seq)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCutOffsets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseCutOffsets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// offsets should be parsed like their equivalent cut sites
+	if got, want := newEnzyme("BsaI", "GGTCTC(1/5)"), newEnzyme("BsaI", "GGTCTCN^NNNN_"); got != want {
+		t.Errorf("newEnzyme() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_checkSites(t *testing.T) {
+	ecoRI := newEnzyme("EcoRI", "G^AATT_C")
+
+	tests := []struct {
+		name    string
+		seq     string
+		enzymes []enzyme
+		strict  bool
+		wantErr bool
+	}{
+		{
+			"no enzymes",
+			"ATGGAATTCATG",
+			[]enzyme{},
+			true,
+			false,
+		},
+		{
+			"no sites",
+			"ATGCATGCATGC",
+			[]enzyme{ecoRI},
+			true,
+			false,
+		},
+		{
+			"site, not strict",
+			"ATGGAATTCATG",
+			[]enzyme{ecoRI},
+			false,
+			false,
+		},
+		{
+			"site, strict",
+			"atggaattcatg",
+			[]enzyme{ecoRI},
+			true,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSites("insert", tt.seq, tt.enzymes, tt.strict); (err != nil) != tt.wantErr {
+				t.Errorf("checkSites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_autoEnzyme(t *testing.T) {
+	ecoRI := newEnzyme("EcoRI", "G^AATT_C")
+	notI := newEnzyme("NotI", "GC^GGCC_GC")
+	pstI := newEnzyme("PstI", "C_TGCA^G")
+	speI := newEnzyme("SpeI", "A^CTAG_T")
+	xbaI := newEnzyme("XbaI", "T^CTAG_A")
+
+	backbone := &Frag{
+		ID:  "backbone",
+		Seq: "GAATTCAAAAAAAAAACTGCAGCCCCCCCCCCCTGCAGTTTTTTTTTTTCTAGAGGGGGGGGGGACTAGTATATATATAT",
+	}
+	inserts := []*Frag{{ID: "insert", Seq: "ATGGAATTCATGTCTAGATAA"}}
+
+	chosen, rejected, err := autoEnzyme(backbone, []enzyme{notI, pstI, xbaI, ecoRI, speI}, inserts, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chosen.name != "SpeI" {
+		t.Errorf("autoEnzyme() = %s, want SpeI", chosen.name)
+	}
+
+	wantRejected := []enzymeRejection{
+		{"NotI", "no site", ""},
+		{"PstI", "cuts backbone", "2"},
+		{"XbaI", "cuts insert", "insert at bp 13"},
+		{"EcoRI", "cuts insert", "insert at bp 4"},
+	}
+	if !reflect.DeepEqual(rejected, wantRejected) {
+		t.Errorf("autoEnzyme() rejected = %+v, want %+v", rejected, wantRejected)
+	}
+
+	// it's an error if none of the candidates cut cleanly
+	if _, _, err = autoEnzyme(backbone, []enzyme{notI, pstI}, inserts, false); err == nil {
+		t.Error("autoEnzyme() error = nil, want an error without a clean cut")
+	}
+}
+
+func Test_searchNames(t *testing.T) {
+	enzymes := map[string]string{
+		"EcoRI": "G^AATT_C",
+		"EcoRV": "GAT^_ATC",
+		"PstI":  "C_TGCA^G",
+		"BsaI":  "GGTCTCN^NNNN_",
+		"BsmBI": "CGTCTCN^NNNN_",
+		"BsmI":  "GAATG_CN^",
+	}
+
+	type args struct {
+		query       string
+		maxDistance int
+		limit       int
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantMatches []string
+	}{
+		{
+			"closest match first",
+			args{"BsaJ", 2, 0},
+			[]string{"BsaI\tGGTCTCN^NNNN_", "BsmI\tGAATG_CN^"},
+		},
+		{
+			"smaller max distance",
+			args{"BsaJ", 1, 0},
+			[]string{"BsaI\tGGTCTCN^NNNN_"},
+		},
+		{
+			"limit results",
+			args{"BsaJ", 2, 1},
+			[]string{"BsaI\tGGTCTCN^NNNN_"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotMatches := searchNames(tt.args.query, enzymes, tt.args.maxDistance, tt.args.limit); !reflect.DeepEqual(gotMatches, tt.wantMatches) {
+				t.Errorf("searchNames() = %v, want %v", gotMatches, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func Test_suggestNames(t *testing.T) {
+	enzymes := map[string]string{
+		"EcoRI": "G^AATT_C",
+		"EcoRV": "GAT^_ATC",
+		"PstI":  "C_TGCA^G",
+		"BsaI":  "GGTCTCN^NNNN_",
+		"BsmBI": "CGTCTCN^NNNN_",
+		"BsmI":  "GAATG_CN^",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			"closest match first",
+			"BsaJ",
+			"did you mean BsaI? (distance 1), BsmI? (distance 2)",
+		},
+		{
+			"at most three suggestions",
+			"BsxI",
+			"did you mean BsaI? (distance 1), BsmI? (distance 1), BsmBI? (distance 2)",
+		},
+		{
+			"no close names",
+			"HindIII",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestNames(tt.query, enzymes); got != tt.want {
+				t.Errorf("suggestNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_digest(t *testing.T) {
 	type args struct {
 		frag *Frag
@@ -60,6 +401,19 @@ func Test_digest(t *testing.T) {
 			&Backbone{},
 			true,
 		},
+		{
+			"fail on a backbone shorter than the largest recognition site",
+			args{
+				&Frag{
+					ID:  "pSB1A3",
+					Seq: "ATGAGGTTAGCCAAAAGAATTCGCACGTGGTGGCGCC",
+				},
+				[]enzyme{enzyme{recog: "GAATTC", compCutIndex: 5, seqCutIndex: 1}},
+			},
+			&Frag{},
+			&Backbone{},
+			true,
+		},
 		{
 			"digest in sequence no overhang",
 			args{
@@ -111,15 +465,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{recog: "GAATTC", compCutIndex: 1, seqCutIndex: 5}},
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "CGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGAATT",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "CGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGAATT",
+				Overhangs:       []string{"AATT"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			&Backbone{
-				Seq:      "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
-				Cutsites: []int{24},
-				Strands:  []bool{true},
-				Enzymes:  []string{""},
+				Seq:             "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
+				Cutsites:        []int{24},
+				Strands:         []bool{true},
+				Enzymes:         []string{""},
+				Overhangs:       []string{"AATT"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			false,
 		},
@@ -132,15 +492,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{recog: "GAATTC", compCutIndex: 5, seqCutIndex: 1}},
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "CGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTG",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "CGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTG",
+				Overhangs:       []string{"AATT"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"5'"},
 			},
 			&Backbone{
-				Seq:      "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
-				Cutsites: []int{24},
-				Strands:  []bool{true},
-				Enzymes:  []string{""},
+				Seq:             "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
+				Cutsites:        []int{24},
+				Strands:         []bool{true},
+				Enzymes:         []string{""},
+				Overhangs:       []string{"AATT"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"5'"},
 			},
 			false,
 		},
@@ -153,15 +519,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{recog: "CCCAGC", compCutIndex: 1, seqCutIndex: 5}}, // rev comp = GCTGGG
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "GGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGCTGG",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "GGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGCTGG",
+				Overhangs:       []string{"CTGG"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			&Backbone{
-				Seq:      "ATGAGGTTAGCCAAAAAAGCACGTGCTGGGGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
-				Cutsites: []int{24},
-				Strands:  []bool{false},
-				Enzymes:  []string{""},
+				Seq:             "ATGAGGTTAGCCAAAAAAGCACGTGCTGGGGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
+				Cutsites:        []int{24},
+				Strands:         []bool{false},
+				Enzymes:         []string{""},
+				Overhangs:       []string{"CTGG"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			false,
 		},
@@ -174,15 +546,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{recog: "CCCAGC", compCutIndex: 5, seqCutIndex: 1}}, // rev comp = GCTGGG
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "GGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTG",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "GGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTG",
+				Overhangs:       []string{"CTGG"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"5'"},
 			},
 			&Backbone{
-				Seq:      "ATGAGGTTAGCCAAAAAAGCACGTGCTGGGGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
-				Cutsites: []int{24},
-				Strands:  []bool{false},
-				Enzymes:  []string{""},
+				Seq:             "ATGAGGTTAGCCAAAAAAGCACGTGCTGGGGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA",
+				Cutsites:        []int{24},
+				Strands:         []bool{false},
+				Enzymes:         []string{""},
+				Overhangs:       []string{"CTGG"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"5'"},
 			},
 			false,
 		},
@@ -195,15 +573,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{recog: "CTGCAG", seqCutIndex: 5, compCutIndex: 1}}, // rev comp = CTGCAG
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "GTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAGTACTAGTAGCGGCCGCTGCA",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "GTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAGTACTAGTAGCGGCCGCTGCA",
+				Overhangs:       []string{"TGCA"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			&Backbone{
-				Strands:  []bool{true},
-				Cutsites: []int{15},
-				Seq:      "TACTAGTAGCGGCCGCTGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAG",
-				Enzymes:  []string{""},
+				Strands:         []bool{true},
+				Cutsites:        []int{15},
+				Seq:             "TACTAGTAGCGGCCGCTGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAG",
+				Enzymes:         []string{""},
+				Overhangs:       []string{"TGCA"},
+				OverhangLengths: []int{4},
+				OverhangEnds:    []string{"3'"},
 			},
 			false,
 		},
@@ -216,15 +600,21 @@ func Test_digest(t *testing.T) {
 				[]enzyme{enzyme{name: "PstI", recog: "CTGCAG", seqCutIndex: 5, compCutIndex: 1}, enzyme{name: "EcoRI", recog: "GAATTC", seqCutIndex: 1, compCutIndex: 5}}, // rev comp = CTGCAG
 			},
 			&Frag{
-				uniqueID: "backbone",
-				fragType: linear,
-				Seq:      "TGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGG",
+				uniqueID:        "backbone",
+				fragType:        linear,
+				Seq:             "TGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGG",
+				Overhangs:       []string{"TGCA", "AATT"},
+				OverhangLengths: []int{4, 4},
+				OverhangEnds:    []string{"3'", "5'"},
 			},
 			&Backbone{
-				Strands:  []bool{true, true},
-				Cutsites: []int{16, 2134},
-				Seq:      "TACTAGTAGCGGCCGCTGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAG",
-				Enzymes:  []string{"PstI", "EcoRI"},
+				Strands:         []bool{true, true},
+				Cutsites:        []int{16, 2134},
+				Seq:             "TACTAGTAGCGGCCGCTGCAGTCCGGCAAAAAAGGGCAAGGTGTCACCACCCTGCCCTTTTTCTTTAAAACCGAAAAGATTACTTCGCGTTATGCAGGCTTCCTCGCTCACTGACTCGCTGCGCTCGGTCGTTCGGCTGCGGCGAGCGGTATCAGCTCACTCAAAGGCGGTAATACGGTTATCCACAGAATCAGGGGATAACGCAGGAAAGAACATGTGAGCAAAAGGCCAGCAAAAGGCCAGGAACCGTAAAAAGGCCGCGTTGCTGGCGTTTTTCCACAGGCTCCGCCCCCCTGACGAGCATCACAAAAATCGACGCTCAAGTCAGAGGTGGCGAAACCCGACAGGACTATAAAGATACCAGGCGTTTCCCCCTGGAAGCTCCCTCGTGCGCTCTCCTGTTCCGACCCTGCCGCTTACCGGATACCTGTCCGCCTTTCTCCCTTCGGGAAGCGTGGCGCTTTCTCATAGCTCACGCTGTAGGTATCTCAGTTCGGTGTAGGTCGTTCGCTCCAAGCTGGGCTGTGTGCACGAACCCCCCGTTCAGCCCGACCGCTGCGCCTTATCCGGTAACTATCGTCTTGAGTCCAACCCGGTAAGACACGACTTATCGCCACTGGCAGCAGCCACTGGTAACAGGATTAGCAGAGCGAGGTATGTAGGCGGTGCTACAGAGTTCTTGAAGTGGTGGCCTAACTACGGCTACACTAGAAGAACAGTATTTGGTATCTGCGCTCTGCTGAAGCCAGTTACCTTCGGAAAAAGAGTTGGTAGCTCTTGATCCGGCAAACAAACCACCGCTGGTAGCGGTGGTTTTTTTGTTTGCAAGCAGCAGATTACGCGCAGAAAAAAAGGATCTCAAGAAGATCCTTTGATCTTTTCTACGGGGTCTGACGCTCAGTGGAACGAAAACTCACGTTAAGGGATTTTGGTCATGAGATTATCAAAAAGGATCTTCACCTAGATCCTTTTAAATTAAAAATGAAGTTTTAAATCAATCTAAAGTATATATGAGTAAACTTGGTCTGACAGTTACCAATGCTTAATCAGTGAGGCACCTATCTCAGCGATCTGTCTATTTCGTTCATCCATAGTTGCCTGACTCCCCGTCGTGTAGATAACTACGATACGGGAGGGCTTACCATCTGGCCCCAGTGCTGCAATGATACCGCGAGACCCACGCTCACCGGCTCCAGATTTATCAGCAATAAACCAGCCAGCCGGAAGGGCCGAGCGCAGAAGTGGTCCTGCAACTTTATCCGCCTCCATCCAGTCTATTAATTGTTGCCGGGAAGCTAGAGTAAGTAGTTCGCCAGTTAATAGTTTGCGCAACGTTGTTGCCATTGCTACAGGCATCGTGGTGTCACGCTCGTCGTTTGGTATGGCTTCATTCAGCTCCGGTTCCCAACGATCAAGGCGAGTTACATGATCCCCCATGTTGTGCAAAAAAGCGGTTAGCTCCTTCGGTCCTCCGATCGTTGTCAGAAGTAAGTTGGCCGCAGTGTTATCACTCATGGTTATGGCAGCACTGCATAATTCTCTTACTGTCATGCCATCCGTAAGATGCTTTTCTGTGACTGGTGAGTACTCAACCAAGTCATTCTGAGAATAGTGTATGCGGCGACCGAGTTGCTCTTGCCCGGCGTCAATACGGGATAATACCGCGCCACATAGCAGAACTTTAAAAGTGCTCATCATTGGAAAACGTTCTTCGGGGCGAAAACTCTCAAGGATCTTACCGCTGTTGAGATCCAGTTCGATATAACCCACTCGTGCACCCAACTGATCTTCAGCATCTTTTACTTTCACCAGCGTTTCTGGGTGAGCAAAAACAGGAAGGCAAAATGCCGCAAAAAAGGGAATAAGGGCGACACGGAAATGTTGAATACTCATACTCTTCCTTTTTCAATATTATTGAAGCATTTATCAGGGTTATTGTCTCATGAGCGGATACATATTTGAATGTATTTAGAAAAATAAACAAATAGGGGTTCCGCGCACATTTCCCCGAAAAGTGCCACCTGACGTCTAAGAAACCATTATTATCATGACATTAACCTATAAAAATAGGCGTATCACGAGGCAGAATTTCAGATAAAAAAAATCCTTAGCTTTCGCTAAGGATGATTTCTGGAATTCGCGGCCGCTTCTAGAG",
+				Enzymes:         []string{"PstI", "EcoRI"},
+				Overhangs:       []string{"TGCA", "AATT"},
+				OverhangLengths: []int{4, 4},
+				OverhangEnds:    []string{"3'", "5'"},
 			},
 			false,
 		},
@@ -232,7 +622,7 @@ func Test_digest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotDigested, gotBackbone, err := digest(tt.args.frag, tt.args.enz)
+			gotDigested, gotBackbone, err := digest(tt.args.frag, tt.args.enz, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("digest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -248,3 +638,204 @@ func Test_digest(t *testing.T) {
 		})
 	}
 }
+
+func Test_digest_keep5(t *testing.T) {
+	ecoRI := enzyme{name: "EcoRI", recog: "GAATTC", seqCutIndex: 1, compCutIndex: 5}
+	pstI := enzyme{name: "PstI", recog: "CTGCAG", seqCutIndex: 5, compCutIndex: 1}
+	ecoRV := enzyme{name: "EcoRV", recog: "GATATC", seqCutIndex: 3, compCutIndex: 3}
+
+	seq := "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA"
+	bluntSeq := "ATGAGGTTAGCCAAAAAAGCACGTGATATCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCA"
+	twoCutSeq := "TTTTCTGCAGCCAAAAAAGCACGTGGTTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACGAATTCTCGCGGCCCATTCCA"
+
+	tests := []struct {
+		name    string
+		seq     string
+		enzymes []enzyme
+		keep5   bool
+		wantSeq string
+	}{
+		{
+			"trims EcoRI's 5' overhangs",
+			seq,
+			[]enzyme{ecoRI},
+			false,
+			"CGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTG",
+		},
+		{
+			"keeps EcoRI's 5' overhangs",
+			seq,
+			[]enzyme{ecoRI},
+			true,
+			"AATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGAATT",
+		},
+		{
+			"blunt cut is the same when keeping 5' overhangs",
+			bluntSeq,
+			[]enzyme{ecoRV},
+			true,
+			"ATCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACTTTCATCGCGGCCCATTCCAATGAGGTTAGCCAAAAAAGCACGTGAT",
+		},
+		{
+			"keeps EcoRI's 5' overhang at the end of a PstI, EcoRI band",
+			twoCutSeq,
+			[]enzyme{pstI, ecoRI},
+			true,
+			"TGCAGCCAAAAAAGCACGTGGTTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAGCTCTTCGTTCCGTCAAGGCCCGACGAATT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDigested, _, err := digest(&Frag{Seq: tt.seq}, tt.enzymes, tt.keep5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if gotDigested.Seq != tt.wantSeq {
+				t.Errorf("digest() = %s, want %s", gotDigested.Seq, tt.wantSeq)
+			}
+		})
+	}
+}
+
+func Test_digest_length(t *testing.T) {
+	ecoRV := enzyme{name: "EcoRV", recog: "GATATC", seqCutIndex: 3, compCutIndex: 3}
+	seq := "ATGAGGTTAGCCAAAAGATATCGCACGTGGTGGCGCCT" // 38 bp, the largest recognition site
+
+	tests := []struct {
+		name    string
+		seq     string
+		wantErr string
+	}{
+		{"exactly the largest recognition site", seq, ""},
+		{"one bp short", seq[:37], "backbone pSB1A3 too short (len=37, need ≥38)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digested, _, err := digest(&Frag{ID: "pSB1A3", Seq: tt.seq}, []enzyme{ecoRV}, false)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("digest() error = %v, want %q", err, tt.wantErr)
+			}
+			if err == nil && len(digested.Seq) != len(tt.seq) {
+				t.Errorf("digest() = %d bp, want %d", len(digested.Seq), len(tt.seq))
+			}
+		})
+	}
+}
+
+func Test_linearBackbone(t *testing.T) {
+	seq := "ATGAGGTTAGCCAAAAAAGCACGTGAATTCGGTGGCGCCCACCGACTGTTCCCAAACTGTAG"
+
+	tests := []struct {
+		name    string
+		frag    *Frag
+		wantSeq string
+		wantErr bool
+	}{
+		{"used as it is", &Frag{ID: "pcr_backbone", Seq: seq}, seq, false},
+		{"undo the doubling of a circular part", &Frag{ID: "pSB1A3", Seq: seq + seq}, seq, false},
+		{"fail without a sequence", &Frag{ID: "empty"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linearized, backbone, err := linearBackbone(tt.frag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("linearBackbone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if linearized.Seq != tt.wantSeq || backbone.Seq != tt.wantSeq {
+				t.Errorf("linearBackbone() = %s, %s, want %s", linearized.Seq, backbone.Seq, tt.wantSeq)
+			}
+			if !tt.wantErr && (linearized.fragType != linear || len(backbone.Enzymes) > 0 || len(linearized.Overhangs) > 0) {
+				t.Errorf("linearBackbone() = %+v, want a blunt linear backbone without enzymes", linearized)
+			}
+		})
+	}
+}
+
+func Test_digestProducts(t *testing.T) {
+	ecoRI := enzyme{name: "EcoRI", recog: "GAATTC", seqCutIndex: 1, compCutIndex: 5}
+
+	tests := []struct {
+		name         string
+		seq          string
+		enzymes      []enzyme
+		wantProducts []product
+	}{
+		{
+			"no cutsites",
+			"ACGTACGTACGTACGTACGTACGTACGTACGTACGTACGT",
+			[]enzyme{ecoRI},
+			nil,
+		},
+		{
+			"one cutsite",
+			"ACGTGAATTCGTACGTACGTACGTACGTACGTACGTACGT",
+			[]enzyme{ecoRI},
+			[]product{
+				product{start: 5, end: 45, complete: true},
+				product{start: 0, end: 40, circular: true},
+			},
+		},
+		{
+			"two cutsites",
+			"GAATTCGTACGAATTCGTACGTACGTACGTACGTACGTAC",
+			[]enzyme{ecoRI},
+			[]product{
+				product{start: 11, end: 41, complete: true},
+				product{start: 1, end: 11, complete: true},
+				product{start: 1, end: 41},
+				product{start: 11, end: 51},
+				product{start: 0, end: 40, circular: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotProducts := digestProducts(tt.seq, tt.enzymes); !reflect.DeepEqual(gotProducts, tt.wantProducts) {
+				t.Errorf("digestProducts() = %+v, want %+v", gotProducts, tt.wantProducts)
+			}
+		})
+	}
+}
+
+func Test_EnzymeDB_named(t *testing.T) {
+	dir, err := ioutil.TempDir("", "enzymes-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	enzymeDB := config.EnzymeDB
+	defer func() { config.EnzymeDB = enzymeDB }()
+	config.EnzymeDB = filepath.Join(dir, "enzymes.tsv")
+	rows := "EcoRI\tG^AATT_C\nBsaI\tGGTCTCN^NNNN_N\tEco31I, BsaI-HFv2\n\nEco31I\tGGTCTCN^NNNN_N\n"
+	if err := ioutil.WriteFile(config.EnzymeDB, []byte(rows), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := NewEnzymeDB()
+
+	tests := []struct {
+		name          string
+		query         string
+		wantCanonical string
+		wantRecogSeq  string
+		wantExists    bool
+	}{
+		{"name", "EcoRI", "EcoRI", "G^AATT_C", true},
+		{"alias", "BsaI-HFv2", "BsaI", "GGTCTCN^NNNN_N", true},
+		{"name before alias", "Eco31I", "Eco31I", "GGTCTCN^NNNN_N", true},
+		{"unknown", "PstI", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, recogSeq, exists := db.named(tt.query)
+			if canonical != tt.wantCanonical || recogSeq != tt.wantRecogSeq || exists != tt.wantExists {
+				t.Errorf("named() = %v, %v, %v, want %v, %v, %v", canonical, recogSeq, exists, tt.wantCanonical, tt.wantRecogSeq, tt.wantExists)
+			}
+		})
+	}
+
+	if got, want := db.row("BsaI"), "BsaI\tGGTCTCN^NNNN_N\tBsaI-HFv2,Eco31I"; got != want {
+		t.Errorf("row() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+const (
+	// phaseInput is for failures reading the target, fragments, flags or settings
+	phaseInput = "input"
+
+	// phaseBLAST is for failures BLAST'ing against the fragment databases
+	phaseBLAST = "blast"
+
+	// phaseAssemble is for failures finding an assembly of the target
+	phaseAssemble = "assemble"
+
+	// phasePrimer is for failures designing the primers of PCR fragments
+	phasePrimer = "primer"
+
+	// phaseOutput is for failures writing the results
+	phaseOutput = "output"
+)
+
+// phaseError is a failure in one phase of a design. It's logged as a JSON object
+// with --json-errors.
+type phaseError struct {
+	// Message is the error's message
+	Message string `json:"error"`
+
+	// Phase is the phase of the design that failed: input, blast, assemble, primer or output.
+	// Empty for failures outside a design, like an unknown enzyme in 'repp ls enzyme'
+	Phase string `json:"phase,omitempty"`
+
+	// Details are extra, structured, data about the failure
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error returns the error's message.
+func (e *phaseError) Error() string {
+	return e.Message
+}
+
+// inPhase returns an error that failed in the phase with the details. Errors that
+// already have a phase keep it.
+func inPhase(phase string, err error, details map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*phaseError); ok {
+		return pe
+	}
+	return &phaseError{Message: err.Error(), Phase: phase, Details: details}
+}
+
+// errLogger logs to stderr. Fatal errors are logged as JSON objects after UseJSONErrors.
+type errLogger struct {
+	*log.Logger
+
+	// json is whether fatal errors are logged as JSON objects
+	json bool
+
+	// phase is the phase of fatal errors without one of their own
+	phase string
+}
+
+// UseJSONErrors logs the errors that end a command as JSON objects with the error's
+// message, phase and details, like {"error": "...", "phase": "blast", "details": {...}}.
+func UseJSONErrors() {
+	stderr.json = true
+	config.Fatalf = func(format string, v ...interface{}) {
+		stderr.Fatal(inPhase(phaseInput, fmt.Errorf(format, v...), nil))
+	}
+}
+
+// Fatal logs the error that ended a command, as a JSON object after UseJSONErrors,
+// and exits with a non-zero status.
+func Fatal(err error) {
+	stderr.Fatal(err)
+}
+
+// Fatal logs the error and exits with a non-zero status.
+func (l *errLogger) Fatal(v ...interface{}) {
+	l.fatal(fmt.Sprint(v...), v)
+}
+
+// Fatalf logs the formatted error and exits with a non-zero status.
+func (l *errLogger) Fatalf(format string, v ...interface{}) {
+	l.fatal(fmt.Sprintf(format, v...), v)
+}
+
+// Fatalln logs the error and exits with a non-zero status.
+func (l *errLogger) Fatalln(v ...interface{}) {
+	l.fatal(fmt.Sprintln(v...), v)
+}
+
+// fatal logs the message, as JSON if set, and exits. The phase and details of a lone
+// phaseError are kept, other errors are in the logger's phase.
+func (l *errLogger) fatal(message string, v []interface{}) {
+	if !l.json {
+		l.Output(3, message)
+		os.Exit(1)
+	}
+
+	e := &phaseError{Message: strings.TrimSpace(message), Phase: l.phase}
+	if len(v) == 1 {
+		if pe, ok := v[0].(*phaseError); ok {
+			e = pe
+		}
+	}
+
+	out, err := json.Marshal(e)
+	if err != nil {
+		out = []byte(fmt.Sprintf(`{"error": %q}`, e.Message))
+	}
+	fmt.Fprintln(os.Stderr, string(out))
+	os.Exit(1)
+}
+
+// gapsDetails returns the details of a failure to cover the target: its regions,
+// 1-based and inclusive, without a matching fragment.
+func gapsDetails(targetID string, gaps []ranged, targetLength int) map[string]interface{} {
+	regions := []map[string]int{}
+	for _, g := range gaps {
+		regions = append(regions, map[string]int{"start": g.start%targetLength + 1, "end": g.end%targetLength + 1})
+	}
+	return map[string]interface{}{"target": targetID, "gaps": regions}
+}
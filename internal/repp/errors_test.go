@@ -0,0 +1,48 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func Test_inPhase(t *testing.T) {
+	blastErr := inPhase(phaseBLAST, fmt.Errorf("failed to blast"), map[string]interface{}{"target": "p1"})
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"error without a phase",
+			inPhase(phaseAssemble, fmt.Errorf("failed to find a solution for p1"), gapsDetails("p1", []ranged{{start: 9, end: 99}}, 200)),
+			`{"error":"failed to find a solution for p1","phase":"assemble","details":{"gaps":[{"end":100,"start":10}],"target":"p1"}}`,
+		},
+		{
+			"error keeps its first phase",
+			inPhase(phaseOutput, blastErr, nil),
+			`{"error":"failed to blast","phase":"blast","details":{"target":"p1"}}`,
+		},
+		{
+			"no details",
+			inPhase(phasePrimer, fmt.Errorf("failed to make primers"), nil),
+			`{"error":"failed to make primers","phase":"primer"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("inPhase() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if inPhase(phaseInput, nil, nil) != nil {
+		t.Error("inPhase() of a nil error isn't nil")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,16 +28,31 @@ type featureMatch struct {
 
 // FeaturesCmd accepts a cobra commands and assembles a plasmid containing all the features
 func FeaturesCmd(cmd *cobra.Command, args []string) {
-	Features(parseCmdFlags(cmd, args, true))
+	flags, conf := parseCmdFlags(cmd, args, true)
+	if flags.products {
+		Products(flags)
+		return
+	}
+
+	Features(flags, conf)
 }
 
 // Features assembles a plasmid with all the Features requested with the 'repp Features [feature ...]' command
 // repp assemble Features p10 promoter, mEGFP, T7 terminator
 func Features(flags *Flags, conf *config.Config) [][]*Frag {
 	start := time.Now()
+	resetRejections(conf.RejectLog)
 
 	// turn feature names into sequences
 	insertFeats, bbFeat := queryFeatures(flags)
+
+	// the features shouldn't be cut by the enzymes that linearized the backbone
+	enzymes := backboneEnzymes(flags.backboneMeta)
+	for _, feat := range insertFeats {
+		if err := checkSites(feat[0], feat[1], enzymes, flags.strict); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
 	feats := insertFeats
 	if len(bbFeat) > 0 {
 		feats = append(feats, bbFeat)
@@ -49,11 +65,17 @@ func Features(flags *Flags, conf *config.Config) [][]*Frag {
 		for _, feat := range insertFeats {
 			featNames = append(featNames, feat[0])
 		}
-		stderr.Fatalf("failed to find fragments with the specified features: %s", strings.Join(featNames, ", "))
+		err := fmt.Errorf("failed to find fragments with the specified features: %s", strings.Join(featNames, ", "))
+		stderr.Fatalln(inPhase(phaseBLAST, err, map[string]interface{}{"features": featNames}))
 	}
 
 	// build assemblies containing the matched fragments
 	target, solutions := featureSolutions(feats, featureMatches, flags, conf)
+	if flags.noBackbone {
+		if err := closingJunctions(solutions, conf); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
 
 	// write the output file
 	insertLength := 0
@@ -65,23 +87,56 @@ func Features(flags *Flags, conf *config.Config) [][]*Frag {
 		flags.out,
 		flags.in,
 		target,
+		0,
 		solutions,
 		insertLength,
 		time.Since(start).Seconds(),
 		flags.backboneMeta,
+		flags.dbs,
 		conf,
 	)
 
+	if flags.outputFormat == "benchling" {
+		if err := writeBenchling(flags.out, target, solutions, flags.backboneMeta); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.outputFormat == "sbol" {
+		if err := writeSBOL(flags.out, flags.in, target, solutions); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(flags.out), filepath.Ext(flags.out)) // the target is named by its features
+	if flags.synthFasta != "" {
+		if err := writeSynthFasta(flags.synthFasta, synthFasta(name, len(target), solutions)); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.bom != "" {
+		if err := writeBOM(flags.bom, bomRows(name, solutions, flags.backboneMeta, conf)); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.overlapReport != "" {
+		if err := writeOverlapReport(flags.overlapReport, overlapRows(target, solutions, conf)); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
 	return solutions
 }
 
 // queryFeatures takes the list of feature names and finds them in the available databases
 func queryFeatures(flags *Flags) ([][]string, []string) {
 	var insertFeats [][]string // slice of tuples [feature name, feature sequence]
-	if readFeatures, err := read(flags.in, true); err == nil {
+	if readFeatures, err := read(flags.in, true, flags.stripInvalid); err == nil {
 		// see if the features are in a file (multi-FASTA or features in a Genbank)
 		seenFeatures := make(map[string]string) // map feature name to sequence
-		for _, f := range readFeatures {
+		for _, f := range orientFrags(readFeatures) {
 			if seq := seenFeatures[f.ID]; seq != f.Seq {
 				stderr.Fatalf("failed to parse features, %s has two different sequences:\n\t%s\n\t%s\n", f.ID, f.Seq, seq)
 			}
@@ -103,12 +158,7 @@ func queryFeatures(flags *Flags) ([][]string, []string) {
 
 		featureDB := NewFeatureDB()
 		for _, f := range featureNames {
-			fwd := true
-			if strings.Contains(f, ":") {
-				ns := strings.Split(f, ":")
-				f = ns[0]
-				fwd = !strings.Contains(strings.ToLower(ns[1]), "rev")
-			}
+			f, fwd := orientation(f)
 
 			if seq, contained := featureDB.features[f]; contained {
 				if !fwd {
@@ -124,12 +174,18 @@ func queryFeatures(flags *Flags) ([][]string, []string) {
 				insertFeats = append(insertFeats, []string{f, dbFrag.Seq})
 			} else {
 				sep := "\n\t"
+				hint := ""
+				if suggestion := suggestNames(f, featureDB.features); suggestion != "" {
+					hint = "\n" + suggestion
+				}
+
 				stderr.Fatalf(
 					"failed to find '%s' in the features database (%s) or any of:"+
-						"%s\ncheck features database with 'repp features find [feature name]'",
+						"%s\ncheck features database with 'repp features find [feature name]'%s",
 					f,
 					config.FeatureDB,
 					sep+strings.Join(flags.dbs, sep)+sep,
+					hint,
 				)
 			}
 		}
@@ -149,16 +205,16 @@ func blastFeatures(flags *Flags, feats [][]string, conf *config.Config) map[stri
 	featureMatches := make(map[string][]featureMatch) // a map from from each entry (by id) to its list of matched features
 	for i, target := range feats {
 		targetFeature := target[1]
-		matches, err := blast(target[0], targetFeature, false, flags.dbs, flags.filters, flags.identity, blastWriter())
+		matches, err := blast(target[0], targetFeature, false, 0, flags.dbs, flags.filters, flags.identity, flags.dbIdentity, flags.dust, blastWriter())
 		if err != nil {
-			stderr.Fatalln(err)
+			stderr.Fatalln(inPhase(phaseBLAST, err, map[string]interface{}{"feature": target[0], "dbs": flags.dbs}))
 		}
 
 		for _, m := range matches {
 			// needs to be at least identity % as long as the queried feature
 			mLen := float64(m.subjectEnd - m.subjectStart + 1)
 			pIdent := mLen / float64(len(targetFeature))
-			pIdentTarget := float64(flags.identity) / 100.0
+			pIdentTarget := float64(identityOf(m.db, flags.identity, flags.dbIdentity)) / 100.0
 			if pIdent < pIdentTarget {
 				continue
 			}
@@ -243,6 +299,8 @@ func featureSolutions(feats [][]string, featureMatches map[string][]featureMatch
 			frag.Seq = reverseComplement(frag.Seq)
 		}
 		frag.conf = conf
+		frag.Inventory = flags.inInventory(frag.db)
+		frag.InHand = flags.inHand(frag.db)
 
 		frag.featureStart = m.queryStart
 		frag.featureEnd = m.queryEnd
@@ -262,14 +320,36 @@ func featureSolutions(feats [][]string, featureMatches map[string][]featureMatch
 		frags = append(frags, frag)
 	}
 
+	// confirm every required fragment matched the features
+	if err := matchedRequired(frags, flags.required); err != nil {
+		stderr.Fatalln(inPhase(phaseAssemble, err, map[string]interface{}{"required": flags.required}))
+	}
+
 	// traverse the fragments, accumulate assemblies that span all the features
+	setBuildCache(frags, newBuildCache())
 	assemblies := createAssemblies(frags, target, len(feats), true, conf)
 
+	// prune the assemblies without every required fragment
+	assemblies, err := requireFrags(assemblies, flags.required)
+	if err != nil {
+		stderr.Fatalln(inPhase(phaseAssemble, err, map[string]interface{}{"required": flags.required}))
+	}
+
 	// build up a map from fragment count to a sorted list of assemblies with that number
 	assemblyCounts, countToAssemblies := groupAssembliesByCount(assemblies)
 
 	// fill each assembly and accumulate the pareto optimal solutions
 	solutions := fillAssemblies(target, assemblyCounts, countToAssemblies, conf)
+	if flags.graph != "" {
+		if err = writeGraph(flags.graph, frags, true, solutions); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+	if conf.RejectLog != "" {
+		if err = writeRejectLog(conf.RejectLog); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
 
 	// update the target to the first filled assembly
 	if len(solutions) > 0 {
@@ -358,7 +438,7 @@ func reblastFeatures(flags *Flags, feats [][]string, conf *config.Config, subjec
 		targetFeature := target[1]
 		matches, err := blastAgainst(target[0], targetFeature, subjectDB, false, flags.identity, blastWriter())
 		if err != nil {
-			stderr.Fatalln(err)
+			stderr.Fatalln(inPhase(phaseBLAST, err, map[string]interface{}{"feature": target[0]}))
 		}
 
 		for _, m := range matches {
@@ -424,7 +504,9 @@ func NewFeatureDB() *FeatureDB {
 	features := make(map[string]string)
 
 	featureFile, err := os.Open(config.FeatureDB)
-	if err != nil {
+	if os.IsNotExist(err) {
+		return &FeatureDB{features: features} // created on first set
+	} else if err != nil {
 		stderr.Fatal(err)
 	}
 
@@ -536,7 +618,7 @@ func (f *FeatureDB) SetCmd(cmd *cobra.Command, args []string) {
 		seq = args[len(args)-1]
 	}
 
-	featureFile, err := os.Open(config.FeatureDB)
+	featureFile, err := openOrCreate(config.FeatureDB)
 	if err != nil {
 		stderr.Fatal(err)
 	}
@@ -2,22 +2,65 @@ package repp
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/jinzhu/copier"
 	"github.com/jjtimmons/repp/config"
 )
 
 var (
-	// madePrimers, formerly made primers
-	madePrimers = make(map[string][]Primer)
+	// blastedMatches, matches of targets against the dbs from prior builds
+	blastedMatches = make(map[string][]match)
 
-	// primerErrs, errors found during prior builds
-	primerErrs = make(map[string]error)
+	// blastedKeys, the keys of blastedMatches from the oldest to the newest
+	blastedKeys []string
+
+	// dbHashes, hashes of the databases' files for the outputs' provenance
+	dbHashes = make(map[string]dbHash)
+
+	// dbHashesRead, whether the hashes from earlier runs are read into dbHashes
+	dbHashesRead bool
+
+	// cacheMu guards the caches above, shared by the targets of a batch built concurrently
+	cacheMu sync.Mutex
 )
 
+// buildCache holds the primers and junctions made while building one target. Each build
+// has its own, so the targets of a batch built concurrently don't share or reset them.
+type buildCache struct {
+	mu sync.Mutex
+
+	// primers, formerly made primers
+	primers map[string][]Primer
+
+	// primerErrs, errors found making primers
+	primerErrs map[string]error
+
+	// junctions, junctions between fragment ends
+	junctions map[string]string
+}
+
+// newBuildCache returns an empty cache for a build.
+func newBuildCache() *buildCache {
+	return &buildCache{
+		primers:    make(map[string][]Primer),
+		primerErrs: make(map[string]error),
+		junctions:  make(map[string]string),
+	}
+}
+
+// setBuildCache sets the cache of the build that the fragments are part of.
+func setBuildCache(frags []*Frag, cache *buildCache) {
+	for _, f := range frags {
+		f.cache = cache
+	}
+}
+
 // fragType is the Frag building type to be used in the assembly
 type fragType int
 
@@ -58,9 +101,77 @@ type Frag struct {
 	// primers necessary to create this (if pcr fragment)
 	Primers []Primer `json:"primers,omitempty"`
 
+	// AnnealingTemp is the suggested PCR annealing temperature, from the lower primer Tm
+	AnnealingTemp float64 `json:"annealingTemp,omitempty"`
+
+	// ExtensionTime is the suggested PCR extension time in seconds
+	ExtensionTime int `json:"extensionTime,omitempty"`
+
+	// AmpliconLength is the length of the PCR's product in bp, with the primers' tails
+	AmpliconLength int `json:"ampliconLength,omitempty"`
+
+	// Inventory is true if the fragment came from one of the user's inventory databases
+	Inventory bool `json:"inventory,omitempty"`
+
+	// InHand is true if the fragment is one the user already has, like a partially built vector
+	InHand bool `json:"inHand,omitempty"`
+
+	// Genomic is true if the fragment is PCR'd from one of the genomic-dbs, less reliably than from a plasmid
+	Genomic bool `json:"genomic,omitempty"`
+
+	// JunctionGC is the GC % of this fragment's junction with the next fragment
+	JunctionGC float64 `json:"junctionGC,omitempty"`
+
+	// JunctionTm is the estimated Tm (celcius) of this fragment's junction with the next fragment
+	JunctionTm float64 `json:"junctionTm,omitempty"`
+
+	// JunctionMethod is how this fragment is joined to the next: "gibson" or "soe"
+	JunctionMethod string `json:"junctionMethod,omitempty"`
+
+	// JunctionCriteria is the set of GC and Tm ranges its junction with the next fragment
+	// was held to: "synthetic", "pcr", "mixed", or "default"
+	JunctionCriteria string `json:"junctionCriteria,omitempty"`
+
+	// JunctionFeature is the feature, shared by the target and the fragment on its side, whose
+	// boundary this fragment's junction with the next is on, with --feature-junctions
+	JunctionFeature string `json:"junctionFeature,omitempty"`
+
+	// Scar is the sequence pinned between this fragment and the next with --junction-scar
+	Scar string `json:"scar,omitempty"`
+
+	// Mispriming are secondary binding sites of the primers in the target plasmid
+	Mispriming []Mispriming `json:"mispriming,omitempty"`
+
+	// SourceID is the ID of the template a PCR fragment is amplified from
+	SourceID string `json:"sourceID,omitempty"`
+
+	// SourceStart is the start of the amplified region on the template (1-indexed)
+	SourceStart int `json:"sourceStart,omitempty"`
+
+	// SourceEnd is the end of the amplified region on the template (1-indexed)
+	SourceEnd int `json:"sourceEnd,omitempty"`
+
+	// SourceStrand is the template strand matching the fragment. 1 if top, -1 if bottom
+	SourceStrand int `json:"sourceStrand,omitempty"`
+
+	// Overhangs are the single stranded ends (top strand) of a digested backbone
+	Overhangs []string `json:"overhangs,omitempty"`
+
+	// OverhangLengths are the bp in each of a digested backbone's overhangs
+	OverhangLengths []int `json:"overhangLengths,omitempty"`
+
+	// OverhangEnds of each of a digested backbone's overhangs, "5'" or "3'"
+	OverhangEnds []string `json:"overhangEnds,omitempty"`
+
+	// Mutations are the substitutions in a synthetic fragment that remove enzymes' sites
+	Mutations []Mutation `json:"mutations,omitempty"`
+
 	// fragType of this fragment. circular | pcr | synthetic | existing
 	fragType fragType
 
+	// cache of the build the Frag is part of. nil if its primers and junctions aren't cached
+	cache *buildCache
+
 	// uniqueID of a match, ID + the start index % seq-length
 	// used identified that catches nodes that cross the zero-index
 	uniqueID string
@@ -68,9 +179,16 @@ type Frag struct {
 	// fullSeq is the entire seq of the Frag/fragment as it was read in (for forward engineering)
 	fullSeq string
 
+	// caseSeq is the seq with the case it was read in with, Seq is upper case.
+	// Lower case regions, like soft-masked introns, are kept in the output
+	caseSeq string
+
 	// db that the frag came from
 	db string
 
+	// source is the BLAST match against the template the frag came from
+	source match
+
 	// start of this Frag on the target plasmid
 	start int
 
@@ -83,6 +201,9 @@ type Frag struct {
 	// end of the frag's last covered feature
 	featureEnd int
 
+	// junctionSeq is the overlap with the next fragment in its assembly, set with JunctionGC
+	junctionSeq string
+
 	// assemblies that span from this Frag to the end of the plasmid
 	assemblies []assembly
 
@@ -101,6 +222,9 @@ type ranged struct {
 
 // Primer is a single Primer used to create a PCR fragment
 type Primer struct {
+	// Name of the primer, from the pcr-primer-name template
+	Name string `json:"name,omitempty"`
+
 	// Seq of the primer (in 5' to 3' direction)
 	Seq string `json:"seq"`
 
@@ -119,10 +243,60 @@ type Primer struct {
 	// GC % max
 	GC float64 `json:"gc"`
 
+	// Order is the sequence to order, the primer's with any 5' modification (IDT syntax)
+	Order string `json:"order,omitempty"`
+
+	// TailSeq is the 5' tail added to the primer for homology with the neighboring fragment
+	TailSeq string `json:"tailSeq,omitempty"`
+
+	// AnnealSeq is the 3' region of the primer that anneals to the template
+	AnnealSeq string `json:"annealSeq,omitempty"`
+
+	// FullSeq is the primer's tail and annealing region together, without a 5' modification
+	FullSeq string `json:"fullSeq,omitempty"`
+
+	// TailHairpin is the ΔG (kcal/mol) of a hairpin pairing the primer's tail with its 3' end
+	// that shifting its junction didn't remove
+	TailHairpin float64 `json:"tailHairpin,omitempty"`
+
+	// tailLength is the bp added to the 5' end of the primer picked by primer3
+	tailLength int
+
 	// Range that the primer spans on the fragment
 	Range ranged `json:"-"`
 }
 
+// Mispriming is a secondary binding site of a PCR fragment's primer in the target plasmid.
+type Mispriming struct {
+	// Primer is the direction of the primer with the secondary binding site. FWD or REV
+	Primer string `json:"primer"`
+
+	// Start of the secondary binding site in the target plasmid (0-indexed)
+	Start int `json:"start"`
+
+	// Amplicon is the predicted size of the spurious amplicon
+	Amplicon int `json:"amplicon"`
+}
+
+// Mutation is a substitution in a synthetic fragment, relative to the target plasmid,
+// that removes a recognition site of one of the enzymes in --avoid-sites.
+type Mutation struct {
+	// Index of the substituted bp in the target plasmid (1-indexed)
+	Index int `json:"index"`
+
+	// From is the target plasmid's bp
+	From string `json:"from"`
+
+	// To is the synthetic fragment's bp
+	To string `json:"to"`
+
+	// Enzyme is the name of the enzyme whose site was removed
+	Enzyme string `json:"enzyme"`
+
+	// SynonymousFrames is the number of reading frames, of six, the substitution is synonymous in
+	SynonymousFrames int `json:"synonymousFrames"`
+}
+
 // newFrag creates a Frag from a match
 func newFrag(m match, conf *config.Config) *Frag {
 	fType := pcr
@@ -131,15 +305,135 @@ func newFrag(m match, conf *config.Config) *Frag {
 	}
 
 	return &Frag{
-		ID:       m.entry,
-		uniqueID: m.uniqueID,
-		Seq:      strings.ToUpper(m.seq),
-		start:    m.queryStart,
-		end:      m.queryEnd,
-		db:       m.db,
-		URL:      parseURL(m.entry, m.db),
-		conf:     conf,
-		fragType: fType,
+		ID:        m.entry,
+		uniqueID:  m.uniqueID,
+		Seq:       strings.ToUpper(m.seq),
+		start:     m.queryStart,
+		end:       m.queryEnd,
+		db:        m.db,
+		source:    m,
+		URL:       parseURL(m.entry, m.db),
+		conf:      conf,
+		fragType:  fType,
+		Inventory: m.inventory,
+		InHand:    m.inHand,
+	}
+}
+
+// setSource sets the region of the template that's amplified in a PCR. The frag's
+// range may have shifted from that of its BLAST match when its primers were made.
+// Primer tails beyond the match don't bind the template and aren't included.
+func (f *Frag) setSource() {
+	m := f.source
+	if m.entry == "" {
+		return
+	}
+
+	// the shift in the frag's start and end from those of the match
+	startShift := 0
+	if f.start > m.queryStart {
+		startShift = f.start - m.queryStart
+	}
+	endShift := 0
+	if f.end < m.queryEnd {
+		endShift = f.end - m.queryEnd
+	}
+
+	f.SourceID = m.entry
+	f.SourceStrand = f.sourceStrand()
+	if m.forward {
+		f.SourceStart = m.subjectStart + startShift + 1
+		f.SourceEnd = m.subjectEnd + endShift + 1
+	} else {
+		f.SourceStart = m.subjectStart - endShift + 1
+		f.SourceEnd = m.subjectEnd - startShift + 1
+	}
+}
+
+// sourceStrand returns the strand of the template that matches the frag: 1 if top,
+// -1 if bottom, or 0 if the frag isn't from a BLAST match.
+func (f *Frag) sourceStrand() int {
+	switch {
+	case f.source.entry == "":
+		return 0
+	case f.source.forward:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// setPrimerOrders sets the ordered sequence of each of a PCR frag's primers. Modifications
+// from the command line for the primer, then the frag, then every primer, take precedence
+// over one in the settings file.
+func (f *Frag) setPrimerOrders() {
+	if f.conf == nil {
+		return
+	}
+
+	for i, p := range f.Primers {
+		dir := "FWD"
+		if !p.Strand {
+			dir = "REV"
+		}
+
+		mod := f.conf.PCRPrimerModification
+		for _, key := range []string{f.ID + ":" + dir, f.ID, ""} {
+			if m, set := f.conf.PrimerModifications[key]; set {
+				mod = m
+				break
+			}
+		}
+
+		f.Primers[i].Order = mod + p.Seq
+	}
+}
+
+// setPrimerTails splits each primer into its 5' tail, added for homology with the
+// neighboring fragment, and the region that anneals to the template.
+func (f *Frag) setPrimerTails() {
+	for i, p := range f.Primers {
+		tail := p.tailLength
+		if tail > len(p.Seq) {
+			tail = len(p.Seq)
+		}
+
+		f.Primers[i].TailSeq = p.Seq[:tail]
+		f.Primers[i].AnnealSeq = p.Seq[tail:]
+		f.Primers[i].FullSeq = p.Seq
+	}
+}
+
+// setPrimerNames names the primers of an assembly from the pcr-primer-name template.
+// An index is appended to a name that's already in the assembly, ex: p1_GFP_FWD_2
+func setPrimerNames(assembly []*Frag, targetName string, conf *config.Config) {
+	if conf == nil || conf.PCRPrimerName == "" {
+		return
+	}
+
+	taken := make(map[string]bool)
+	for i, f := range assembly {
+		for j, p := range f.Primers {
+			dir := "FWD"
+			if !p.Strand {
+				dir = "REV"
+			}
+
+			name := strings.NewReplacer(
+				"{target}", targetName,
+				"{fragID}", f.ID,
+				"{dir}", dir,
+				"{index}", strconv.Itoa(i+1),
+			).Replace(conf.PCRPrimerName)
+
+			unique := name
+			for n := 2; taken[unique]; n++ {
+				unique = fmt.Sprintf("%s_%d", name, n)
+			}
+			taken[unique] = true
+
+			f.Primers[j].Name = unique
+		}
 	}
 }
 
@@ -193,28 +487,158 @@ func newFrags(matches []match, conf *config.Config) []*Frag {
 func (f *Frag) copy() (newFrag *Frag) {
 	newFrag = &Frag{}
 	copier.Copy(newFrag, f)
+	newFrag.cache = f.cache
+
+	return
+}
+
+// Reverse returns a reverse complemented copy of the Frag. Its primers are swapped and
+// their strands flipped, so the first primer is still the one binding the top strand.
+func (f *Frag) Reverse() (reversed *Frag) {
+	r := *f // shallow copy, including unexported fields
+	reversed = &r
+	reversed.Seq = reverseComplement(f.Seq)
+	reversed.PCRSeq = reverseComplement(f.PCRSeq)
+	reversed.fullSeq = reverseComplement(f.fullSeq)
+	if f.caseSeq != "" {
+		caseSeq := []byte(f.caseSeq)
+		for i, j := 0, len(caseSeq)-1; i < j; i, j = i+1, j-1 {
+			caseSeq[i], caseSeq[j] = caseSeq[j], caseSeq[i]
+		}
+		reversed.caseSeq = matchCase(reversed.Seq, string(caseSeq), 0)
+	}
+
+	reversed.Primers = nil
+	for i := len(f.Primers) - 1; i >= 0; i-- {
+		p := f.Primers[i]
+		p.Strand = !p.Strand
+		reversed.Primers = append(reversed.Primers, p)
+	}
 
 	return
 }
 
+// orientation splits a fragment or feature name from an orientation suffix and returns
+// whether it's in the forward direction. Ex: "GFP:rev" returns "GFP" and false
+func orientation(name string) (id string, fwd bool) {
+	if !strings.Contains(name, ":") {
+		return name, true
+	}
+
+	ns := strings.Split(name, ":")
+	return ns[0], !strings.Contains(strings.ToLower(ns[1]), "rev")
+}
+
+// orientFrags reverse complements the fragments with a ":rev" suffix in their ID.
+func orientFrags(frags []*Frag) []*Frag {
+	for i, f := range frags {
+		if id, fwd := orientation(f.ID); !fwd {
+			frags[i] = f.Reverse()
+			frags[i].ID = id
+		}
+	}
+
+	return frags
+}
+
 // cost returns the estimated cost of a fragment. Combination of source and preparation
 func (f *Frag) cost(procure bool) (c float64) {
 	if procure {
+		c += procureCost(f.URL, f.conf)
+	}
+
+	if f.fragType == pcr && f.Primers != nil {
+		// from the primers' real lengths, with their tails
+		c += f.pcrCost(len(f.Primers[0].Seq) + len(f.Primers[1].Seq))
+
+		// PCR from genomic DNA is less reliable, fragments from plasmids are preferred
+		if f.conf.GenomicDB(f.db) {
+			c += f.conf.CostGenomicPCR
+		}
+	} else if f.fragType == synthetic {
+		c += f.conf.SynthFragmentCost(len(f.Seq))
+	}
+
+	return
+}
+
+// procureCost returns the cost of procuring a plasmid from its repository, by its URL.
+// It's 0 if the plasmid isn't from a repository
+func procureCost(url string, conf *config.Config) float64 {
+	if strings.Contains(url, "addgene") {
+		return conf.CostAddgene
+	} else if strings.Contains(url, "igem") {
+		return conf.CostIGEM
+	} else if strings.Contains(url, "dnasu") {
+		return conf.CostDNASU
+	}
+	return 0
+}
+
+// pcrCost returns the cost of a PCR with primers of primerBP bp in total: the cost of the
+// primers' bp plus the cost of a single PCR reaction
+func (f *Frag) pcrCost(primerBP int) float64 {
+	return float64(primerBP)*f.conf.CostBP + f.conf.CostPCR
+}
+
+// sourceCost returns the fixed cost of procuring the fragment's source plasmid from a
+// repository. Fragments from local databases, including the user's inventory and
+// the fragments in hand, and synthetic fragments have no source to procure.
+func (f *Frag) sourceCost() float64 {
+	if f.URL == "" || f.Inventory || f.InHand {
+		return 0
+	}
+	return f.conf.CostSource
+}
+
+// leadTime returns the estimated days until the fragment is ready to assemble: the
+// days to receive it from its repository, or synthesize it, and then to PCR it.
+func (f *Frag) leadTime(conf *config.Config) (days float64) {
+	if f.fragType == synthetic {
+		return conf.LeadTimeSynthesis
+	}
+
+	if !f.Inventory && !f.InHand {
 		if strings.Contains(f.URL, "addgene") {
-			c += f.conf.CostAddgene
+			days += conf.LeadTimeAddgene
 		} else if strings.Contains(f.URL, "igem") {
-			c += f.conf.CostIGEM
+			days += conf.LeadTimeIGEM
 		} else if strings.Contains(f.URL, "dnasu") {
-			c += f.conf.CostDNASU
+			days += conf.LeadTimeDNASU
 		}
 	}
 
-	if f.fragType == pcr && f.Primers != nil {
-		// cost of primers plus the cost of a single PCR reaction
-		c += float64(len(f.Primers[0].Seq)+len(f.Primers[1].Seq)) * f.conf.CostBP
-		c += f.conf.CostPCR
-	} else if f.fragType == synthetic {
-		c += f.conf.SynthFragmentCost(len(f.Seq))
+	if f.fragType == pcr {
+		days += conf.LeadTimePCR
+	}
+
+	return
+}
+
+// checkLength returns an error if the fragment is shorter than min bp. The kind,
+// ex: "target" or "backbone", is what the fragment is called in the error, before
+// its ID unless it's unset or the same as the kind.
+func checkLength(kind string, f *Frag, min int) error {
+	if len(f.Seq) >= min {
+		return nil
+	}
+
+	name := kind
+	if f.ID != "" && !strings.EqualFold(f.ID, kind) {
+		name = kind + " " + f.ID
+	}
+	return fmt.Errorf("%s too short (len=%d, need ≥%d)", name, len(f.Seq), min)
+}
+
+// turnaround returns the estimated worst-case days to make a plasmid from the fragments:
+// the longest lead time of a fragment, which are made in parallel, and then the assembly.
+func turnaround(frags []*Frag, conf *config.Config) (days float64) {
+	for _, f := range frags {
+		days = math.Max(days, f.leadTime(conf))
+	}
+
+	if len(frags) > 1 {
+		days += conf.LeadTimeAssembly
 	}
 
 	return
@@ -236,7 +660,25 @@ func (f *Frag) overlapsViaPCR(other *Frag) bool {
 // overlapsViaHomology returns whether this Frag already has sufficient overlap with the
 // other Frag without any preparation like PCR
 func (f *Frag) overlapsViaHomology(other *Frag) bool {
-	return f.distTo(other) <= -f.conf.FragmentsMinHomology
+	return f.distTo(other) <= -f.overlapLength(other)
+}
+
+// overlapLength returns the length of the overlap to make between this Frag and the
+// other. It's the one requested for their junction, if any, or the min homology.
+func (f *Frag) overlapLength(other *Frag) int {
+	if length, set := f.conf.JunctionOverlap(f.ID, other.ID); set {
+		return length
+	}
+	return f.conf.FragmentsMinHomology
+}
+
+// scarTo returns the sequence pinned between this Frag and the other, if there's one.
+func (f *Frag) scarTo(other *Frag) string {
+	if f.conf == nil {
+		return ""
+	}
+	scar, _ := f.conf.JunctionScar(f.ID, other.ID)
+	return scar
 }
 
 // synthDist returns the number of synthesized fragments that would need to be created
@@ -257,6 +699,28 @@ func (f *Frag) synthDist(other *Frag) (synthCount int) {
 	return int(math.Ceil(floatDist / float64(f.conf.SyntheticMaxLength)))
 }
 
+// synthLength returns the length of each synthetic fragment to the other Frag, with
+// homology for its neighbors on either end. 0 if it's reached without synthesis.
+func (f *Frag) synthLength(other *Frag) int {
+	synCount := f.synthDist(other)
+	if synCount == 0 {
+		return 0
+	}
+
+	fL := f.distTo(other) / synCount
+	fL += f.overlapLength(other) * 2 // account for homology on either end of each synthetic fragment
+	if f.conf.SyntheticMinLength > fL {
+		// need to synthesize at least Synthesis.MinLength bps
+		fL = f.conf.SyntheticMinLength
+	}
+	if f.conf.FragmentsMinLength > fL {
+		// extend the synthesis into the neighbors rather than make a short fragment
+		fL = f.conf.FragmentsMinLength
+	}
+
+	return fL
+}
+
 // costTo estimates the $ amount needed to get from this fragment
 // to the other Frag passed, either by PCR or synthesis
 //
@@ -266,12 +730,53 @@ func (f *Frag) synthDist(other *Frag) (synthCount int) {
 // Otherwise we find the total synthesis distance between this and
 // the other fragment and divide that by the cost per bp of synthesized DNA
 //
-// This does not add in the cost of procurement, which is added to the assembly cost
-// in assembly.add()
+// A PCR's estimate is of its primers' bp, with the homology they add, and the reaction.
+// Once the primers are designed, Frag.cost uses their real lengths
+//
+// If the other Frag is from the user's inventory, the estimate is scaled
+// by the inventory cost factor so assemblies from plasmids on hand are preferred.
+// If it's from a genomic database, the genomic PCR penalty is added
+//
+// This does not add in the cost of procurement, or the fixed cost of each distinct
+// source plasmid, which are added to the assembly cost in assembly.add()
 func (f *Frag) costTo(other *Frag) (cost float64) {
+	// fragments in hand are free, so they're used ahead of any other source
+	if other != f && other.InHand && f.overlapsViaPCR(other) {
+		return 0
+	}
+
+	cost = f.costToUnscaled(other)
+	if other != f && other.Inventory {
+		cost *= f.conf.CostInventoryFactor
+	}
+
+	// fragments from preferred databases win ties
+	if other != f {
+		cost *= f.conf.DBWeight(other.db)
+	}
+
+	// junctions on the boundaries of features shared with the target are preferred
+	if other != f && f.junctionFeature(other) != "" {
+		cost = math.Max(cost-f.conf.CostFeatureJunctionBonus, 0)
+	}
+
+	// PCR from genomic DNA is less reliable, fragments from plasmids are preferred
+	if f.conf.GenomicDB(other.db) {
+		cost += f.conf.CostGenomicPCR
+	}
+
+	// overlap-extension PCR fuses each junction in its own reaction
+	if other != f && f.conf.JunctionMethod == "soe" {
+		cost += f.conf.CostPCR
+	}
+
+	return
+}
+
+// costToUnscaled is costTo without any preference for inventory fragments
+func (f *Frag) costToUnscaled(other *Frag) (cost float64) {
 	needsPCR := f.fragType == pcr || f.fragType == circular
-	pcrNoHomology := 50.0 * f.conf.CostBP // pcr no homology
-	pcrHomology := (50.0 + float64(f.conf.FragmentsMinHomology)) * f.conf.CostBP
+	pcrNoHomology := f.pcrCost(50) // pcr no homology
 
 	if other == f {
 		if needsPCR {
@@ -288,8 +793,13 @@ func (f *Frag) costTo(other *Frag) (cost float64) {
 		}
 
 		// we have to create some additional primer sequence to reach the next fragment
-		// estimating here that we'll add half of minHomology to both sides
-		return pcrHomology
+		// estimating here that we'll add the homology, split between both sides, and
+		// that each side embeds any gap between the fragments (see primer3.bpToAdd)
+		gap := f.distTo(other) + 1
+		if gap < 0 {
+			gap = 0
+		}
+		return f.pcrCost(50 + 2*gap + f.overlapLength(other))
 	}
 
 	// we need to create a new synthetic fragment to get from this fragment to the next
@@ -324,6 +834,11 @@ func (f *Frag) reach(nodes []*Frag, i int, features bool) (reachable []int) {
 			continue
 		} else if nodes[i].end < f.end {
 			continue // fully engulfed
+		} else if f.junctionForbidden(nodes[i]) {
+			if nodes[i].uniqueID == f.uniqueID {
+				break
+			}
+			continue // the junction would be in a region that can't have one
 		}
 
 		reachable = append(reachable, i)
@@ -335,8 +850,89 @@ func (f *Frag) reach(nodes []*Frag, i int, features bool) (reachable []int) {
 	return
 }
 
+// junctionForbidden returns whether the junction between this Frag and the other would
+// be in one of the regions of the target that can't have junctions. If they're joined
+// through synthetic fragments, these are the synthetic fragments' junctions with each.
+func (f *Frag) junctionForbidden(other *Frag) bool {
+	if f.conf == nil || len(f.conf.NoJunctions) == 0 {
+		return false
+	}
+
+	if f.synthDist(other) > 0 {
+		jL := f.conf.FragmentsMinHomology
+		return inNoJunction(f.end-jL, f.end, f.conf) || inNoJunction(other.start, other.start+jL, f.conf)
+	}
+
+	if other.start < f.end {
+		return inNoJunction(other.start, f.end, f.conf)
+	}
+	return inNoJunction(f.end, other.start, f.conf)
+}
+
+// junctionFeature returns the name of the target's feature whose boundary the junction
+// between this Frag and the other is on, if there's one. The feature has to be whole in,
+// and shared by, the fragment on its side of the junction: ending in the junction and in
+// this Frag, or starting in it and in the other. For fragments joined through synthetic
+// fragments, the boundary is in one of the synthetic fragments' junctions with them
+func (f *Frag) junctionFeature(other *Frag) string {
+	if f.conf == nil || len(f.conf.FeatureJunctions) == 0 {
+		return ""
+	}
+
+	// where a feature whole in this Frag ends, and where one whole in the other starts
+	endStart, endEnd := other.start, f.end
+	if other.start > f.end {
+		endStart, endEnd = f.end, other.start
+	}
+	startStart, startEnd := endStart, endEnd
+	if f.synthDist(other) > 0 {
+		jL := f.conf.FragmentsMinHomology
+		endStart, endEnd = f.end-jL, f.end
+		startStart, startEnd = other.start, other.start+jL
+	}
+
+	for _, r := range f.conf.FeatureJunctions {
+		if r.End >= endStart && r.End <= endEnd && r.Start >= f.start && f.hasFeature(r.Seq) {
+			return r.Name
+		}
+		if r.Start >= startStart && r.Start <= startEnd && r.End <= other.end && other.hasFeature(r.Seq) {
+			return r.Name
+		}
+	}
+
+	return ""
+}
+
+// hasFeature returns whether the feature's sequence is in the Frag's on either strand, so
+// the Frag's source shares it with the target. Synthetic fragments have no source.
+func (f *Frag) hasFeature(featureSeq string) bool {
+	if f.fragType == synthetic || featureSeq == "" {
+		return false
+	}
+
+	seq := strings.ToUpper(f.Seq)
+	return strings.Contains(seq, featureSeq) || strings.Contains(seq, reverseComplement(featureSeq))
+}
+
+// inNoJunction returns whether the range of the target overlaps any of the regions
+// that can't have junctions.
+func inNoJunction(start, end int, conf *config.Config) bool {
+	if conf == nil {
+		return false
+	}
+
+	for _, r := range conf.NoJunctions {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+
+	return false
+}
+
 // junction checks for and returns any 100% identical homology between the end of this
-// Frag and the start of the other. returns an empty string if there's no junction between them
+// Frag and the start of the other. returns an empty string if there's no junction between them.
+// The longest junction within the criteria of its type is preferred, falling back to the longest
 func (f *Frag) junction(other *Frag, minHomology, maxHomology int) (junction string) {
 	s1 := f.Seq
 	if f.PCRSeq != "" {
@@ -351,6 +947,18 @@ func (f *Frag) junction(other *Frag, minHomology, maxHomology int) (junction str
 	s1 = strings.ToUpper(s1)
 	s2 = strings.ToUpper(s2)
 
+	// a junction with a requested overlap is that long or, if the ends don't overlap by
+	// exactly that many bp, at least that long
+	requested := 0
+	if f.conf != nil {
+		if length, set := f.conf.JunctionOverlap(f.ID, other.ID); set {
+			requested = length
+			if length > minHomology {
+				minHomology = length
+			}
+		}
+	}
+
 	//      v-maxHomology from end    v-minHomology from end
 	// ------------------------------------
 	//                    -----------------------------
@@ -364,6 +972,32 @@ func (f *Frag) junction(other *Frag, minHomology, maxHomology int) (junction str
 		end = 0
 	}
 
+	// the junction only depends on the ends compared, so those are the key rather than
+	// the fragments' IDs, which are shared by a fragment and its reverse complement
+	otherEnd := len(s1) - start
+	if otherEnd > len(s2) {
+		otherEnd = len(s2)
+	}
+	seam := seamType(f, other)
+	jHash := fmt.Sprintf("%d%s|%s|%s", end-start, s1[start:], s2[:otherEnd], seam)
+	if f.cache != nil {
+		f.cache.mu.Lock()
+		oldJunction, contained := f.cache.junctions[jHash]
+		f.cache.mu.Unlock()
+		if contained {
+			return oldJunction
+		}
+		defer func() {
+			f.cache.mu.Lock()
+			f.cache.junctions[jHash] = junction
+			f.cache.mu.Unlock()
+		}()
+	}
+
+	if requested > 0 && requested <= len(s1) && requested <= len(s2) && s1[len(s1)-requested:] == s2[:requested] {
+		return s1[len(s1)-requested:]
+	}
+
 	// for every possible start index
 	for i := start; i <= end; i++ {
 		// traverse from that index to the end of the seq
@@ -378,7 +1012,12 @@ func (f *Frag) junction(other *Frag, minHomology, maxHomology int) (junction str
 
 			// we made it to the end of the sequence, there's a junction
 			if k == len(s1)-1 {
-				return s1[i:]
+				if junctionInCriteria(s1[i:], seam, f.conf) {
+					return s1[i:]
+				}
+				if junction == "" {
+					junction = s1[i:]
+				}
 			}
 		}
 	}
@@ -386,13 +1025,119 @@ func (f *Frag) junction(other *Frag, minHomology, maxHomology int) (junction str
 	return
 }
 
+// readSeq returns the Frag's seq with the case it was read in with.
+func (f *Frag) readSeq() string {
+	if f.caseSeq != "" {
+		return f.caseSeq
+	}
+	return f.Seq
+}
+
+// matchCase returns seq with the case of the template from the start index onward.
+// Bases opposite those that are lower case in the template are lower case. The
+// template is circular and its start may be beyond its zero index.
+func matchCase(seq, template string, start int) string {
+	if template == "" || strings.ToUpper(template) == template {
+		return seq
+	}
+
+	cased := []byte(seq)
+	for i := range cased {
+		t := template[((start+i)%len(template)+len(template))%len(template)]
+		if t >= 'a' && t <= 'z' && cased[i] >= 'A' && cased[i] <= 'Z' {
+			cased[i] += 'a' - 'A'
+		}
+	}
+	return string(cased)
+}
+
+// cachePrimers stores the primers made for a PCR, or the error making them, in the cache
+// of the Frag's build.
+func (f *Frag) cachePrimers(pHash string, primers []Primer, err error) {
+	if f.cache == nil {
+		return
+	}
+
+	f.cache.mu.Lock()
+	defer f.cache.mu.Unlock()
+	if err != nil {
+		f.cache.primerErrs[pHash] = err
+	} else {
+		f.cache.primers[pHash] = primers
+	}
+}
+
+// gcContent returns the GC % of a sequence
+func gcContent(seq string) float64 {
+	if len(seq) < 1 {
+		return 0
+	}
+
+	seq = strings.ToUpper(seq)
+	gc := strings.Count(seq, "G") + strings.Count(seq, "C")
+	return 100 * float64(gc) / float64(len(seq))
+}
+
+// junctionTm estimates the Tm (celcius) of a junction's duplex from its length and
+// GC %, corrected for the concentration of monovalent cations (mM). Howley et al. 1979:
+// Tm = 81.5 + 16.6 * log10([Na+]) + 0.41 * GC% - 600 / length
+func junctionTm(junction string, monovalentConc float64) float64 {
+	if len(junction) < 1 {
+		return 0
+	}
+
+	if monovalentConc <= 0 {
+		monovalentConc = 50 // primer3's default
+	}
+
+	return 81.5 + 16.6*math.Log10(monovalentConc/1000) + 0.41*gcContent(junction) - 600/float64(len(junction))
+}
+
+// seamType returns the type of the junction between two fragments, which decides the
+// criteria it's held to: "synthetic" if both are synthetic, "mixed" if one is, otherwise "pcr"
+func seamType(f, other *Frag) string {
+	switch {
+	case f.fragType == synthetic && other.fragType == synthetic:
+		return "synthetic"
+	case f.fragType == synthetic || other.fragType == synthetic:
+		return "mixed"
+	default:
+		return "pcr"
+	}
+}
+
+// junctionInCriteria returns whether the junction's GC % and estimated Tm are within the
+// configured criteria of its type of junction. Unset bounds (eg: from an older settings
+// file) aren't checked
+func junctionInCriteria(junction, seam string, conf *config.Config) bool {
+	if conf == nil {
+		return true
+	}
+
+	criteria, _ := conf.JunctionCriteriaOf(seam)
+	if criteria.MinGC > 0 || criteria.MaxGC > 0 {
+		gc := gcContent(junction)
+		if gc < criteria.MinGC || (criteria.MaxGC > 0 && gc > criteria.MaxGC) {
+			return false
+		}
+	}
+	if criteria.MinTm > 0 || criteria.MaxTm > 0 {
+		tm := junctionTm(junction, conf.MonovalentConc())
+		if tm < criteria.MinTm || (criteria.MaxTm > 0 && tm > criteria.MaxTm) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // synthTo returns synthetic fragments to get this Frag to the next.
 // It creates a slice of building fragments that have homology against
 // one another and are within the upper and lower synthesis bounds.
 // target is the plasmid's full sequence. We need it to build up the target
 // plasmid's sequence
 func (f *Frag) synthTo(next *Frag, target string) (synths []*Frag) {
-	jL := f.conf.FragmentsMinHomology // junction length
+	jL := f.overlapLength(next) // junction length
 
 	// check whether we need to make synthetic fragments to get
 	// to the next fragment in the assembly
@@ -401,17 +1146,18 @@ func (f *Frag) synthTo(next *Frag, target string) (synths []*Frag) {
 		return nil
 	}
 
-	tL := len(target)               // length of the full target plasmid
-	fL := f.distTo(next) / synCount // each fragment's length
-	fL += jL * 2                    // account for homology on either end of each synthetic fragment
-	if f.conf.SyntheticMinLength > fL {
-		// need to synthesize at least Synthesis.MinLength bps
-		fL = f.conf.SyntheticMinLength
-	}
+	tL := len(target)         // length of the full target plasmid
+	fL := f.synthLength(next) // each fragment's length
 
 	// add to self to account for sequence across the zero-index (when sequence subselecting)
 	target = strings.ToUpper(target + target + target + target) // TODO remove this
 
+	// remove enzymes' sites from the bp that are only in the synthetic fragments
+	var mutations []Mutation
+	if enzymes := avoidEnzymes(f.conf); len(enzymes) > 0 {
+		target, mutations = removeSites(target, f.end+1+tL, next.start+tL, enzymes)
+	}
+
 	// slide along the range of sequence to create synthetic fragments
 	// and create one at each point, each w/ jL for the fragment
 	// before and after it
@@ -428,13 +1174,35 @@ func (f *Frag) synthTo(next *Frag, target string) (synths []*Frag) {
 			seq = target[start:end]
 		}
 
+		// shift the junction to the right, by at most its own length, till it's within the
+		// criteria of a junction with the next synthetic fragment, or the next fragment.
+		// The fragment isn't lengthened past the max synthesis length
+		seam := "synthetic"
+		if len(synths) == synCount-1 {
+			seam = seamType(&Frag{fragType: synthetic}, next)
+		}
+		for shift := 1; shift <= jL && end+shift-start <= f.conf.SyntheticMaxLength && !junctionInCriteria(seq[len(seq)-jL:], seam, f.conf); shift++ {
+			if junctionInCriteria(target[end+shift-jL:end+shift], seam, f.conf) {
+				end += shift
+				seq = target[start:end]
+			}
+		}
+
+		// extend the fragment across regions that can't have a junction
+		for inNoJunction(end-jL, end-1, f.conf) && end-start < f.conf.SyntheticMaxLength && end < len(target) {
+			end++
+			seq = target[start:end]
+		}
+
 		synths = append(synths, &Frag{
-			ID:       fmt.Sprintf("%s-%s-synthesis-%d", f.ID, next.ID, len(synths)+1),
-			Seq:      seq,
-			start:    start,
-			end:      end,
-			fragType: synthetic,
-			conf:     f.conf,
+			ID:        fmt.Sprintf("%s-%s-synthesis-%d", f.ID, next.ID, len(synths)+1),
+			Seq:       seq,
+			start:     start,
+			end:       end,
+			fragType:  synthetic,
+			Mutations: mutationsIn(mutations, start, end, tL),
+			conf:      f.conf,
+			cache:     f.cache,
 		})
 
 		start = end - jL
@@ -444,17 +1212,26 @@ func (f *Frag) synthTo(next *Frag, target string) (synths []*Frag) {
 }
 
 // setPrimers creates primers against a Frag and returns an error if:
-//	1. the primers have an unacceptably high primer3 penalty score
-//	2. the primers have off-targets in their source plasmid/fragment
+//  1. the primers have an unacceptably high primer3 penalty score
+//  2. the primers have off-targets in their source plasmid/fragment
 func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (err error) {
-	pHash := primerHash(last, f, next)
-	if oldPrimers, contained := madePrimers[pHash]; contained {
-		f.Primers = oldPrimers
-		mutatePrimers(f, seq, 0, 0) // set PCRSeq
+	pHash := primerHash(last, f, next, seq)
+	var oldPrimers []Primer
+	var oldErr error
+	contained, failed := false, false
+	if f.cache != nil {
+		f.cache.mu.Lock()
+		oldPrimers, contained = f.cache.primers[pHash]
+		oldErr, failed = f.cache.primerErrs[pHash]
+		f.cache.mu.Unlock()
+	}
+	if contained {
+		f.Primers = append([]Primer{}, oldPrimers...) // they're named and ordered per assembly
+		mutatePrimers(f, seq, 0, 0)                   // set PCRSeq
 		return nil
 	}
 
-	if oldErr, contained := primerErrs[pHash]; contained {
+	if failed {
 		return oldErr
 	}
 
@@ -471,23 +1248,26 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 		conf.PCRBufferLength,
 	)
 	if err != nil {
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
 	if err = psExec.run(); err != nil {
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
 	if err = psExec.parse(seq); err != nil {
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
 	// update Frag's range, and add additional bp to the left and right primer if it wasn't included in the primer3 output
 	mutatePrimers(f, seq, addLeft, addRight)
 
+	// lengthen tails that fold back and prime off themselves
+	shiftTailHairpins(f, last, next, seq, conf)
+
 	// make sure the fragment's length is still long enough for PCR
 	if len(f.PCRSeq) < conf.PCRMinLength {
 		err = fmt.Errorf(
@@ -497,7 +1277,7 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 			conf.PCRMinLength,
 		)
 		f.Primers = nil
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
@@ -511,7 +1291,7 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 			f.Primers[1],
 		)
 		f.Primers = nil
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
@@ -527,7 +1307,7 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 		err = mismatchResult.err
 	} else if f.db != "" {
 		// otherwise, query the fragment from the DB (try to find it) and then check for mismatches
-		mismatchResult := parentMismatch(f.Primers, f.ID, f.db, conf)
+		mismatchResult := parentMismatch(f.Primers, f.ID, f.db, f.sourceStrand(), conf)
 		mismatchExists = mismatchResult.wasMismatch
 		mm = mismatchResult.m
 		err = mismatchResult.err
@@ -535,18 +1315,18 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 
 	if err != nil {
 		f.Primers = nil
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return err
 	}
 	if mismatchExists {
-		err = fmt.Errorf(
+		err = &rejectError{rejectOffTarget, fmt.Errorf(
 			"found a mismatching sequence %s for primers: %s, %s",
 			mm.seq,
 			f.Primers[0].Seq,
 			f.Primers[1].Seq,
-		)
+		)}
 		f.Primers = nil
-		primerErrs[pHash] = err
+		f.cachePrimers(pHash, nil, err)
 		return
 	}
 
@@ -555,7 +1335,7 @@ func (f *Frag) setPrimers(last, next *Frag, seq string, conf *config.Config) (er
 	os.Remove(psExec.in.Name()) // delete the temporary input and output files
 	os.Remove(psExec.out.Name())
 
-	madePrimers[pHash] = f.Primers
+	f.cachePrimers(pHash, f.Primers, nil)
 
 	return
 }
@@ -584,6 +1364,7 @@ func mutatePrimers(f *Frag, seq string, addLeft, addRight int) *Frag {
 		oldStart := f.Primers[0].Range.start + sl
 		f.Primers[0].Seq = seq[oldStart-addLeft:oldStart] + f.Primers[0].Seq
 		f.Primers[0].Range.start -= addLeft
+		f.Primers[0].tailLength = addLeft
 	}
 
 	// add bp to the right/REV primer to match the fragment to the right
@@ -591,6 +1372,7 @@ func mutatePrimers(f *Frag, seq string, addLeft, addRight int) *Frag {
 		oldEnd := f.Primers[1].Range.end + sl
 		f.Primers[1].Seq = reverseComplement(seq[oldEnd+1:oldEnd+addRight+1]) + f.Primers[1].Seq
 		f.Primers[1].Range.end += addRight
+		f.Primers[1].tailLength = addRight
 	}
 
 	// update fragment sequence
@@ -599,6 +1381,93 @@ func mutatePrimers(f *Frag, seq string, addLeft, addRight int) *Frag {
 	return f
 }
 
+// shiftTailHairpins checks the homology tails of a Frag's primers for self-priming hairpins,
+// those pairing the tail with the primer's 3' end above the max junction hairpin melting
+// temperature. The junction of a primer with one is shifted outward, lengthening its tail
+// by up to pcr-max-tail-shift bp, to the first length without one. If there is none, its
+// tail is left as is and the hairpin's ΔG is kept on the primer
+//
+// More tail is more homology with the neighboring fragment, so a junction isn't shifted
+// past the max homology length
+//
+// returning Frag for testing
+func shiftTailHairpins(f, last, next *Frag, seq string, conf *config.Config) *Frag {
+	if len(f.Primers) != 2 {
+		return f
+	}
+
+	sl := len(seq)
+	seq = strings.ToUpper(seq + seq + seq + seq)
+
+	for i, p := range f.Primers {
+		if p.tailLength < 1 {
+			continue
+		}
+
+		overlap := 0
+		if i == 0 && last != nil {
+			overlap = junctionOverlap(last.end, p.Range.start, sl)
+		} else if i == 1 && next != nil {
+			overlap = junctionOverlap(p.Range.end, next.start, sl)
+		}
+
+		maxShift := conf.PCRMaxTailShift
+		if maxHomology := conf.FragmentsMaxHomology - overlap; maxShift > maxHomology {
+			maxShift = maxHomology
+		}
+
+		for shift := 0; shift <= maxShift; shift++ {
+			shifted := p
+			if shift > 0 && i == 0 {
+				start := p.Range.start + sl
+				shifted.Seq = seq[start-shift:start] + p.Seq
+				shifted.Range.start -= shift
+			} else if shift > 0 {
+				end := p.Range.end + sl
+				shifted.Seq = reverseComplement(seq[end+1:end+shift+1]) + p.Seq
+				shifted.Range.end += shift
+			}
+			shifted.tailLength += shift
+
+			dG, melt, selfPriming, err := tailHairpin(shifted.Seq, shifted.tailLength, conf)
+			if err != nil {
+				stderr.Printf("warning: failed to fold the primers of %s: %v\n", f.ID, err)
+				break
+			}
+
+			if !selfPriming || melt <= conf.FragmentsMaxHairpinMelt {
+				f.Primers[i] = shifted
+				break
+			}
+
+			if shift == 0 {
+				f.Primers[i].TailHairpin = math.Round(dG*100) / 100
+			}
+		}
+	}
+
+	// update fragment sequence
+	f.PCRSeq = seq[f.Primers[0].Range.start+sl : f.Primers[1].Range.end+sl+1]
+
+	return f
+}
+
+// junctionOverlap returns the bp of overlap between a fragment ending at end and the next,
+// starting at start, on a circular sequence of length sl
+func junctionOverlap(end, start, sl int) int {
+	d := (end - start) % sl
+	if d < 0 {
+		d += sl
+	}
+	if d > sl/2 {
+		d -= sl
+	}
+	if d < 0 {
+		return 0
+	}
+	return d + 1
+}
+
 // String returns a string representation of a fragment's type
 func (t fragType) String() string {
 	return []string{"linear", "plasmid", "pcr", "synthetic"}[t]
@@ -613,7 +1482,19 @@ func fragsCost(frags []*Frag) (cost float64) {
 	return
 }
 
-// primerHash returns a unique hash for a PCR run
-func primerHash(last, f, next *Frag) string {
-	return fmt.Sprintf("%s%d%d%d%d", f.uniqueID, last.end, f.start, f.end, next.start)
+// cheapestCost returns the cost of the cheapest of the solutions.
+func cheapestCost(solutions [][]*Frag) float64 {
+	cheapest := math.MaxFloat64
+	for _, s := range solutions {
+		cheapest = math.Min(cheapest, fragsCost(s))
+	}
+	return cheapest
+}
+
+// primerHash returns a unique hash for a PCR run on the target. Fragments of targets
+// that match a template at the same range still get their own primers, with their tails
+func primerHash(last, f, next *Frag, target string) string {
+	targetHash := fnv.New64a()
+	targetHash.Write([]byte(target))
+	return fmt.Sprintf("%s%d%d%d%d-%x", f.uniqueID, last.end, f.start, f.end, next.start, targetHash.Sum64())
 }
@@ -155,6 +155,11 @@ func Test_Frag_costTo(t *testing.T) {
 	c := config.New()
 	c.FragmentsMinHomology = 20
 	c.CostBP = 0.03
+	c.CostPCR = 0.27
+	c.CostInventoryFactor = 0.5
+	c.DBWeights = map[string]float64{"lab_inventory": 0.8}
+	c.GenomicDBs = []string{"ecoli_genome"}
+	c.CostGenomicPCR = 10
 	c.CostSyntheticFragment = map[int]config.SynthCost{
 		100000: {
 			Fixed: false,
@@ -198,7 +203,86 @@ func Test_Frag_costTo(t *testing.T) {
 					conf:  c,
 				},
 			},
-			1.5,
+			1.77,
+		},
+		{
+			"cost of PCR with primers that embed the gap if they're close",
+			fields{
+				start: 0,
+				end:   50,
+			},
+			args{
+				other: &Frag{
+					start: 55,
+					end:   100,
+					conf:  c,
+				},
+			},
+			2.73,
+		},
+		{
+			"discounted cost of PCR if the new Frag is from the inventory",
+			fields{
+				start: 0,
+				end:   50,
+			},
+			args{
+				other: &Frag{
+					start:     20,
+					end:       100,
+					Inventory: true,
+					conf:      c,
+				},
+			},
+			0.885,
+		},
+		{
+			"weighted cost of PCR if the new Frag is from a weighted db",
+			fields{
+				start: 0,
+				end:   50,
+			},
+			args{
+				other: &Frag{
+					start: 20,
+					end:   100,
+					db:    "/data/dbs/lab_inventory.fa",
+					conf:  c,
+				},
+			},
+			1.416,
+		},
+		{
+			"penalized cost of PCR if the new Frag is from a genomic db",
+			fields{
+				start: 0,
+				end:   50,
+			},
+			args{
+				other: &Frag{
+					start: 20,
+					end:   100,
+					db:    "/data/dbs/ecoli_genome.fa",
+					conf:  c,
+				},
+			},
+			11.77,
+		},
+		{
+			"no cost if the new Frag is already in hand",
+			fields{
+				start: 0,
+				end:   50,
+			},
+			args{
+				other: &Frag{
+					start:  20,
+					end:    100,
+					InHand: true,
+					conf:   c,
+				},
+			},
+			0.0,
 		},
 		{
 			"cost of synthesis if they don't overlap",
@@ -224,7 +308,7 @@ func Test_Frag_costTo(t *testing.T) {
 			args{
 				other: n1,
 			},
-			1.5,
+			1.77,
 		},
 	}
 	for _, tt := range tests {
@@ -244,6 +328,42 @@ func Test_Frag_costTo(t *testing.T) {
 	}
 }
 
+func Test_Frag_cost(t *testing.T) {
+	c := config.New()
+	c.CostBP = 0.03
+	c.CostPCR = 0.27
+	c.GenomicDBs = []string{"ecoli_genome"}
+	c.CostGenomicPCR = 10
+
+	primers := func(bp int) []Primer {
+		return []Primer{{Seq: strings.Repeat("A", bp)}, {Seq: strings.Repeat("T", bp)}}
+	}
+
+	// the genome's fragment has shorter primers, but the plasmid's is picked
+	genomic := &Frag{fragType: pcr, db: "/data/dbs/ecoli_genome.fa", Primers: primers(20), conf: c}
+	plasmid := &Frag{fragType: pcr, db: "/data/dbs/addgene", Primers: primers(40), conf: c}
+
+	tests := []struct {
+		name string
+		frag *Frag
+		want float64
+	}{
+		{"PCR from a plasmid", plasmid, 2.67},
+		{"PCR from a genome is penalized", genomic, 11.47},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.frag.cost(false); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Frag.cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if fragsCost([]*Frag{genomic}) <= fragsCost([]*Frag{plasmid}) {
+		t.Errorf("fragsCost() prefers the genomic fragment, %v <= %v", fragsCost([]*Frag{genomic}), fragsCost([]*Frag{plasmid}))
+	}
+}
+
 func Test_Frag_reach(t *testing.T) {
 	c := config.New()
 
@@ -378,12 +498,19 @@ func Test_new(t *testing.T) {
 				},
 			},
 			&Frag{
-				ID:         "testMatch",
-				fragType:   pcr,
-				Seq:        "ATGCTAGCTAGTG",
-				uniqueID:   "0testMatch",
-				start:      0,
-				end:        12,
+				ID:       "testMatch",
+				fragType: pcr,
+				Seq:      "ATGCTAGCTAGTG",
+				uniqueID: "0testMatch",
+				start:    0,
+				end:      12,
+				source: match{
+					entry:      "testMatch",
+					uniqueID:   "0testMatch",
+					seq:        "atgctagctagtg",
+					queryStart: 0,
+					queryEnd:   12,
+				},
 				assemblies: nil,
 				conf:       c,
 			},
@@ -398,6 +525,305 @@ func Test_new(t *testing.T) {
 	}
 }
 
+func Test_Frag_setSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		f          *Frag
+		wantStart  int
+		wantEnd    int
+		wantStrand int
+	}{
+		{
+			"unchanged range, top strand",
+			&Frag{
+				start:  100,
+				end:    200,
+				source: match{entry: "p1", queryStart: 100, queryEnd: 200, subjectStart: 10, subjectEnd: 110, forward: true},
+			},
+			11,
+			111,
+			1,
+		},
+		{
+			"range shrunk by primers, top strand",
+			&Frag{
+				start:  105,
+				end:    190,
+				source: match{entry: "p1", queryStart: 100, queryEnd: 200, subjectStart: 10, subjectEnd: 110, forward: true},
+			},
+			16,
+			101,
+			1,
+		},
+		{
+			"range shrunk by primers, bottom strand",
+			&Frag{
+				start:  105,
+				end:    190,
+				source: match{entry: "p1", queryStart: 100, queryEnd: 200, subjectStart: 10, subjectEnd: 110, forward: false},
+			},
+			21,
+			106,
+			-1,
+		},
+		{
+			"primer tails past the match",
+			&Frag{
+				start:  90,
+				end:    210,
+				source: match{entry: "p1", queryStart: 100, queryEnd: 200, subjectStart: 10, subjectEnd: 110, forward: true},
+			},
+			11,
+			111,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.f.setSource()
+			if tt.f.SourceID != "p1" || tt.f.SourceStart != tt.wantStart || tt.f.SourceEnd != tt.wantEnd || tt.f.SourceStrand != tt.wantStrand {
+				t.Errorf(
+					"Frag.setSource() = %s %d-%d (%d), want p1 %d-%d (%d)",
+					tt.f.SourceID, tt.f.SourceStart, tt.f.SourceEnd, tt.f.SourceStrand,
+					tt.wantStart, tt.wantEnd, tt.wantStrand,
+				)
+			}
+		})
+	}
+}
+
+func Test_Frag_setPrimerOrders(t *testing.T) {
+	primers := func() []Primer {
+		return []Primer{
+			Primer{Seq: "ACGTACGTACGTACGTAC", Strand: true},
+			Primer{Seq: "TGCATGCATGCATGCATG", Strand: false},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		conf      *config.Config
+		wantOrder []string
+	}{
+		{
+			"no modifications",
+			&config.Config{},
+			[]string{"ACGTACGTACGTACGTAC", "TGCATGCATGCATGCATG"},
+		},
+		{
+			"modification from the settings file",
+			&config.Config{PCRPrimerModification: "/5Phos/"},
+			[]string{"/5Phos/ACGTACGTACGTACGTAC", "/5Phos/TGCATGCATGCATGCATG"},
+		},
+		{
+			"primer modification over the fragment's and settings file's",
+			&config.Config{
+				PCRPrimerModification: "/5Phos/",
+				PrimerModifications:   map[string]string{"frag:REV": "/5SpC3/", "frag": "/5Biosg/"},
+			},
+			[]string{"/5Biosg/ACGTACGTACGTACGTAC", "/5SpC3/TGCATGCATGCATGCATG"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Frag{ID: "frag", Primers: primers(), conf: tt.conf}
+			f.setPrimerOrders()
+
+			for i, p := range f.Primers {
+				if p.Order != tt.wantOrder[i] {
+					t.Errorf("setPrimerOrders() primer %d = %s, want %s", i, p.Order, tt.wantOrder[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_Frag_setPrimerTails(t *testing.T) {
+	tests := []struct {
+		name       string
+		primer     Primer
+		wantTail   string
+		wantAnneal string
+	}{
+		{
+			"primer with a tail",
+			Primer{Seq: "CTCGATGACCTCGGC", tailLength: 5},
+			"CTCGA",
+			"TGACCTCGGC",
+		},
+		{
+			"primer without a tail",
+			Primer{Seq: "TGACCTCGGC"},
+			"",
+			"TGACCTCGGC",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Frag{Primers: []Primer{tt.primer}}
+			f.setPrimerTails()
+
+			p := f.Primers[0]
+			if p.TailSeq != tt.wantTail || p.AnnealSeq != tt.wantAnneal || p.FullSeq != tt.primer.Seq {
+				t.Errorf("setPrimerTails() = %s + %s (%s), want %s + %s", p.TailSeq, p.AnnealSeq, p.FullSeq, tt.wantTail, tt.wantAnneal)
+			}
+		})
+	}
+}
+
+func Test_setPrimerNames(t *testing.T) {
+	primers := func() []Primer {
+		return []Primer{Primer{Strand: true}, Primer{Strand: false}}
+	}
+
+	tests := []struct {
+		name      string
+		template  string
+		wantNames []string
+	}{
+		{
+			"default template",
+			"{target}_{fragID}_{dir}",
+			[]string{"p1_frag_FWD", "p1_frag_REV", "p1_frag_FWD_2", "p1_frag_REV_2"},
+		},
+		{
+			"template with the fragment index",
+			"{target}-{index}{dir}",
+			[]string{"p1-1FWD", "p1-1REV", "p1-2FWD", "p1-2REV"},
+		},
+		{
+			"no template",
+			"",
+			[]string{"", "", "", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the same fragment amplified twice
+			assembly := []*Frag{&Frag{ID: "frag", Primers: primers()}, &Frag{ID: "frag", Primers: primers()}}
+			setPrimerNames(assembly, "p1", &config.Config{PCRPrimerName: tt.template})
+
+			var gotNames []string
+			for _, f := range assembly {
+				for _, p := range f.Primers {
+					gotNames = append(gotNames, p.Name)
+				}
+			}
+			if !reflect.DeepEqual(gotNames, tt.wantNames) {
+				t.Errorf("setPrimerNames() = %v, want %v", gotNames, tt.wantNames)
+			}
+		})
+	}
+}
+
+func Test_Frag_junctionForbidden(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 20
+	c.PCRMaxEmbedLength = 20
+	c.SyntheticMaxLength = 500
+	c.NoJunctions = []config.Range{{Start: 100, End: 150}}
+
+	f := &Frag{start: 0, end: 120, conf: c}
+
+	tests := []struct {
+		name  string
+		other *Frag
+		want  bool
+	}{
+		{
+			"overlap in the region",
+			&Frag{start: 90, end: 300, conf: c},
+			true,
+		},
+		{
+			"PCR homology in the region",
+			&Frag{start: 135, end: 300, conf: c},
+			true,
+		},
+		{
+			"synthetic junction with the first frag in the region",
+			&Frag{start: 400, end: 600, conf: c},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.junctionForbidden(tt.other); got != tt.want {
+				t.Errorf("Frag.junctionForbidden() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// shouldn't be forbidden outside the region
+	outside := &Frag{start: 0, end: 60, conf: c}
+	if outside.junctionForbidden(&Frag{start: 40, end: 300, conf: c}) {
+		t.Error("Frag.junctionForbidden() = true for a junction outside the region")
+	}
+}
+
+func Test_Frag_junctionFeature(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 20
+	c.SyntheticMaxLength = 500
+	lacZ := "ATGACCATGATTACGGATTCACTGGCC"
+	ori := "TTGAGATCCTTTTTTTCTGCGCGTAATC"
+	c.FeatureJunctions = []config.Range{
+		{Start: 10, End: 110, Name: "lacZ", Seq: lacZ},
+		{Start: 305, End: 380, Name: "ori", Seq: ori},
+	}
+
+	tests := []struct {
+		name  string
+		f     *Frag
+		other *Frag
+		want  string
+	}{
+		{
+			"feature ends in the overlap",
+			&Frag{Seq: "GG" + lacZ + "CC", start: 0, end: 120, conf: c},
+			&Frag{start: 100, end: 300, conf: c},
+			"lacZ",
+		},
+		{
+			"feature isn't shared by the frag",
+			&Frag{Seq: "GGCC", start: 0, end: 120, conf: c},
+			&Frag{start: 100, end: 300, conf: c},
+			"",
+		},
+		{
+			"feature isn't whole in the frag",
+			&Frag{Seq: "GG" + lacZ + "CC", start: 0, end: 120, conf: c},
+			&Frag{start: 115, end: 300, conf: c},
+			"",
+		},
+		{
+			"synthetic junction with a feature starting in the next frag",
+			&Frag{start: 0, end: 200, conf: c},
+			&Frag{Seq: reverseComplement(ori), start: 300, end: 600, conf: c},
+			"ori",
+		},
+		{
+			"synthetic fragments have no source to share a feature",
+			&Frag{start: 0, end: 200, conf: c},
+			&Frag{Seq: ori, start: 300, end: 600, fragType: synthetic, conf: c},
+			"",
+		},
+		{
+			"synthetic junction without a feature",
+			&Frag{start: 0, end: 200, conf: c},
+			&Frag{Seq: ori, start: 400, end: 600, conf: c},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.junctionFeature(tt.other); got != tt.want {
+				t.Errorf("Frag.junctionFeature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_Frag_junction(t *testing.T) {
 	type fields struct {
 		ID         string
@@ -479,6 +905,23 @@ func Test_Frag_junction(t *testing.T) {
 			},
 			"CAGATGACGATG",
 		},
+		{
+			"requested overlap rather than the longest",
+			fields{
+				ID:   "a",
+				Seq:  "CCCCCCCCATGATGATG",
+				conf: &config.Config{JunctionOverlaps: map[string]int{"a/b": 6}},
+			},
+			args{
+				other: &Frag{
+					ID:  "b",
+					Seq: "ATGATGATGCCCCCCCC",
+				},
+				minHomology: 3,
+				maxHomology: 20,
+			},
+			"ATGATG",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -493,10 +936,284 @@ func Test_Frag_junction(t *testing.T) {
 				URL:        tt.fields.url,
 				Primers:    tt.fields.primers,
 				conf:       tt.fields.conf,
+				cache:      newBuildCache(),
 			}
 			if gotJunction := n.junction(tt.args.other, tt.args.minHomology, tt.args.maxHomology); gotJunction != tt.wantJunction {
 				t.Errorf("Frag.junction() = %v, want %v", gotJunction, tt.wantJunction)
 			}
+
+			// and again from the junctions found in the run
+			if gotJunction := n.junction(tt.args.other, tt.args.minHomology, tt.args.maxHomology); gotJunction != tt.wantJunction || len(n.cache.junctions) != 1 {
+				t.Errorf("Frag.junction() second call = %v, want %v from the %d junctions made", gotJunction, tt.wantJunction, len(n.cache.junctions))
+			}
+		})
+	}
+}
+
+func Test_junctionTm(t *testing.T) {
+	tests := []struct {
+		name           string
+		junction       string
+		monovalentConc float64
+		want           float64
+	}{
+		{"40 bp at 50% GC", "ATGCATGCATGCATGCATGCATGCATGCATGCATGCATGC", 50, 65.4},
+		{"more salt raises the Tm", "ATGCATGCATGCATGCATGCATGCATGCATGCATGCATGC", 100, 70.4},
+		{"default salt if unset", "ATGCATGCATGCATGCATGCATGCATGCATGCATGCATGC", 0, 65.4},
+		{"no junction", "", 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := junctionTm(tt.junction, tt.monovalentConc); math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("junctionTm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_junctionOverlap(t *testing.T) {
+	tests := []struct {
+		name       string
+		end, start int
+		want       int
+	}{
+		{"overlapping", 119, 100, 20},
+		{"abutting", 99, 100, 0},
+		{"apart", 80, 100, 0},
+		{"across the zero index", 1019, 0, 20},
+		{"in the next copy of the plasmid", 19, 1000, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := junctionOverlap(tt.end, tt.start, 1000); got != tt.want {
+				t.Errorf("junctionOverlap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_junctionInCriteria(t *testing.T) {
+	c := &config.Config{
+		FragmentsMinJunctionGC: 30,
+		FragmentsMaxJunctionGC: 70,
+		JunctionCriteria: map[string]config.JunctionCriteria{
+			"synthetic": {MinGC: 40, MaxGC: 60},
+			"mixed":     {MinTm: 60},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		junction string
+		seam     string
+		conf     *config.Config
+		want     bool
+	}{
+		{
+			"within range",
+			"ATGCATGCAT",
+			"pcr",
+			c,
+			true,
+		},
+		{
+			"too low GC",
+			"ATATATATGC",
+			"pcr",
+			c,
+			false,
+		},
+		{
+			"too high GC",
+			"GCGCGCGCAT",
+			"pcr",
+			c,
+			false,
+		},
+		{
+			"no GC bounds",
+			"ATATATATAT",
+			"pcr",
+			&config.Config{},
+			true,
+		},
+		{
+			"within the default range but not the synthetic one",
+			"ATGCATATAT",
+			"synthetic",
+			c,
+			false,
+		},
+		{
+			"within the synthetic range",
+			"ATGCATGCAT",
+			"synthetic",
+			c,
+			true,
+		},
+		{
+			"mixed junction below its Tm, without GC bounds",
+			"ATGCATGCATGCATGCATGC",
+			"mixed",
+			c,
+			false,
+		},
+		{
+			"mixed junction above its Tm",
+			"ATGCATATGCATATGCATGCATGCATGCATGCATATATGCATGCAT",
+			"mixed",
+			c,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := junctionInCriteria(tt.junction, tt.seam, tt.conf); got != tt.want {
+				t.Errorf("junctionInCriteria() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_seamType(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right fragType
+		want        string
+	}{
+		{"two synthetic fragments", synthetic, synthetic, "synthetic"},
+		{"synthetic then PCR", synthetic, pcr, "mixed"},
+		{"PCR then synthetic", pcr, synthetic, "mixed"},
+		{"PCR then a linear fragment", pcr, linear, "pcr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seamType(&Frag{fragType: tt.left}, &Frag{fragType: tt.right}); got != tt.want {
+				t.Errorf("seamType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matchCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		seq      string
+		template string
+		start    int
+		want     string
+	}{
+		{
+			"upper case template",
+			"ATGCAT",
+			"ATGCATGCAT",
+			2,
+			"ATGCAT",
+		},
+		{
+			"soft-masked region",
+			"GCATGC",
+			"ATGCatgCAT",
+			2,
+			"GCatgC",
+		},
+		{
+			"across the zero index",
+			"CATATG",
+			"atGCATGCaT",
+			7,
+			"CaTatG",
+		},
+		{
+			"start before the zero index",
+			"TATG",
+			"atGCATGCAT",
+			-1,
+			"TatG",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchCase(tt.seq, tt.template, tt.start); got != tt.want {
+				t.Errorf("matchCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Frag_Reverse(t *testing.T) {
+	f := &Frag{
+		ID:     "f1",
+		Seq:    "AATTGGCCAC",
+		PCRSeq: "CCAATTGGCCACTT",
+		Primers: []Primer{
+			Primer{Seq: "CCAATTGG", Strand: true},
+			Primer{Seq: "AAGTGGCC", Strand: false},
+		},
+		start: 10,
+		end:   19,
+	}
+
+	want := &Frag{
+		ID:     "f1",
+		Seq:    "GTGGCCAATT",
+		PCRSeq: "AAGTGGCCAATTGG",
+		Primers: []Primer{
+			Primer{Seq: "AAGTGGCC", Strand: true},
+			Primer{Seq: "CCAATTGG", Strand: false},
+		},
+		start: 10,
+		end:   19,
+	}
+
+	if got := f.Reverse(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Frag.Reverse() = %+v, want %+v", got, want)
+	}
+
+	if f.Seq != "AATTGGCCAC" || !f.Primers[0].Strand {
+		t.Errorf("Frag.Reverse() mutated the original fragment: %+v", f)
+	}
+
+	// the case it was read in with is kept on the other strand
+	cased := &Frag{Seq: "AATTGGCCAC", caseSeq: "aattGGCCAC"}
+	if got := cased.Reverse().readSeq(); got != "GTGGCCaatt" {
+		t.Errorf("Frag.Reverse() = %s, want GTGGCCaatt", got)
+	}
+}
+
+func Test_Frag_synthTo(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 10
+	c.SyntheticMinLength = 0
+	c.FragmentsMinLength = 0
+	c.FragmentsMaxHairpinMelt = 1000
+	c.FragmentsMinJunctionGC = 40
+	c.FragmentsMaxJunctionGC = 60
+	c.JunctionCriteria = nil
+
+	// an AT-rich junction at the end of the synthetic fragment, in range a few bp to the right
+	target := strings.Repeat("ATGC", 75)
+	target = target[:131] + strings.Repeat("A", 10) + "GCGCG" + target[146:]
+
+	tests := []struct {
+		name       string
+		maxLength  int
+		wantLength int
+	}{
+		{"junction shifted into the GC range", 120, 106},
+		{"junction not shifted past the max synthesis length", 104, 102},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := *c
+			conf.SyntheticMaxLength = tt.maxLength
+			f := &Frag{ID: "f", start: 0, end: 49, conf: &conf}
+			next := &Frag{ID: "next", start: 130, end: 250, conf: &conf}
+
+			synths := f.synthTo(next, target)
+			if len(synths) != 1 || len(synths[0].Seq) != tt.wantLength {
+				t.Errorf("Frag.synthTo() = %v, want one synthetic fragment of %d bp", synths, tt.wantLength)
+			}
 		})
 	}
 }
@@ -721,6 +1438,34 @@ func Test_setPrimers(t *testing.T) {
 	}
 }
 
+func Test_setBuildCache(t *testing.T) {
+	first := []*Frag{{ID: "a"}, {ID: "b"}}
+	second := []*Frag{{ID: "a"}}
+	setBuildCache(first, newBuildCache())
+	setBuildCache(second, newBuildCache())
+
+	if first[0].cache != first[1].cache || first[0].cache == second[0].cache {
+		t.Error("setBuildCache() builds should share a cache with their own fragments only")
+	}
+	if first[0].copy().cache != first[0].cache {
+		t.Error("Frag.copy() dropped the cache of its build")
+	}
+}
+
+func Test_primerHash(t *testing.T) {
+	last := &Frag{end: 10}
+	f := &Frag{uniqueID: "pSB1A3", start: 20, end: 200}
+	next := &Frag{start: 190}
+
+	target := "ATGCATGCATGCGATCGATCGATCG"
+	if primerHash(last, f, next, target) != primerHash(last, f, next, target) {
+		t.Error("primerHash() differs for the same PCR on the same target")
+	}
+	if primerHash(last, f, next, target) == primerHash(last, f, next, target+"A") {
+		t.Error("primerHash() is the same for a PCR at the same range of another target")
+	}
+}
+
 func Test_fragType_String(t *testing.T) {
 	tests := []struct {
 		name string
@@ -756,3 +1501,95 @@ func Test_fragType_String(t *testing.T) {
 		})
 	}
 }
+
+func Test_turnaround(t *testing.T) {
+	conf := &config.Config{
+		LeadTimeAddgene:   14,
+		LeadTimeIGEM:      10,
+		LeadTimeSynthesis: 12,
+		LeadTimePCR:       2,
+		LeadTimeAssembly:  1,
+	}
+
+	tests := []struct {
+		name  string
+		frags []*Frag
+		want  float64
+	}{
+		{
+			"lone plasmid from a repository",
+			[]*Frag{{URL: "http://parts.igem.org/Part:BBa_E0040", fragType: circular}},
+			10,
+		},
+		{
+			"PCR from addgene and synthesis",
+			[]*Frag{
+				{URL: "https://www.addgene.org/85472/", fragType: pcr},
+				{fragType: synthetic},
+			},
+			17,
+		},
+		{
+			"PCR from the inventory and synthesis",
+			[]*Frag{
+				{URL: "https://www.addgene.org/85472/", fragType: pcr, Inventory: true},
+				{fragType: synthetic},
+			},
+			13,
+		},
+		{
+			"PCR from local fragments",
+			[]*Frag{{fragType: pcr}, {fragType: pcr}},
+			3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := turnaround(tt.frags, conf); got != tt.want {
+				t.Errorf("turnaround() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       *Frag
+		min     int
+		wantErr string
+	}{
+		{
+			"one bp short",
+			&Frag{ID: "target", Seq: strings.Repeat("A", 14)},
+			15,
+			"target too short (len=14, need ≥15)",
+		},
+		{
+			"one bp short with an ID",
+			&Frag{ID: "GFP", Seq: strings.Repeat("A", 14)},
+			15,
+			"target GFP too short (len=14, need ≥15)",
+		},
+		{
+			"exactly the min",
+			&Frag{ID: "target", Seq: strings.Repeat("A", 15)},
+			15,
+			"",
+		},
+		{
+			"empty without an ID",
+			&Frag{},
+			15,
+			"target too short (len=0, need ≥15)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLength("target", tt.f, tt.min)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("checkLength() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
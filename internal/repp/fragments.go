@@ -2,6 +2,9 @@ package repp
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jjtimmons/repp/config"
@@ -31,13 +34,33 @@ func FragmentFindCmd(cmd *cobra.Command, args []string) {
 // FragmentsCmd accepts a cobra commands and assembles a list of building fragments in order
 func FragmentsCmd(cmd *cobra.Command, args []string) {
 	flags, conf := parseCmdFlags(cmd, args, true)
+	if flags.products {
+		Products(flags)
+		return
+	}
 
-	// read in the constituent fragments
-	frags, err := read(flags.in, false)
-	if err != nil {
+	// read in the constituent fragments, from a file or a directory of them
+	var frags []*Frag
+	if info, err := os.Stat(flags.in); err == nil && info.IsDir() {
+		frags, err = readDir(flags.in, flags.stripInvalid)
+		if err != nil {
+			stderr.Fatalln(err)
+		}
+	} else if frags, err = read(flags.in, false, flags.stripInvalid); err != nil {
 		stderr.Fatalln(err)
 	}
 
+	// reverse complement the fragments with ":rev" suffixes
+	frags = orientFrags(frags)
+
+	// the fragments shouldn't be cut by the enzymes that linearized the backbone
+	enzymes := backboneEnzymes(flags.backboneMeta)
+	for _, f := range frags {
+		if err := checkSites(f.ID, f.Seq, enzymes, flags.strict); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
 	// add in the backbone if it was provided
 	if flags.backbone.ID != "" {
 		frags = append([]*Frag{flags.backbone}, frags...)
@@ -48,19 +71,76 @@ func FragmentsCmd(cmd *cobra.Command, args []string) {
 		f.conf = conf
 	}
 
+	// pin the sequencing primers' sites at the ends of the insert, outside any enzymes' sites
+	if len(conf.SeqPrimerSites) > 0 {
+		if err := addSeqPrimerSites(frags, flags.backbone.ID != "", conf); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	// pin the enzymes' sites at the ends of the insert
+	if len(flags.flankSites) == 2 {
+		if err := flankInsert(frags, flags.backbone.ID != "", flags.flankSites[0], flags.flankSites[1], flags.flankSpacer, conf); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
 	target, solution := fragments(frags, conf)
 
+	// the enzymes can only cut at the sites flanking the assembled insert
+	if len(flags.flankSites) == 2 {
+		var err error
+		if conf.FlankSites, err = insertFlankSites(target.Seq, frags, flags.backbone.ID != "", flags.flankSites[0], flags.flankSites[1]); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
 	// write the single list of fragments as a possible solution to the output file
 	writeJSON(
 		flags.out,
 		flags.in,
 		target.Seq,
+		0,
 		[][]*Frag{solution},
 		len(target.Seq),
 		0,
 		flags.backboneMeta,
+		flags.dbs,
 		conf,
 	)
+
+	if flags.outputFormat == "benchling" {
+		if err := writeBenchling(flags.out, target.Seq, [][]*Frag{solution}, flags.backboneMeta); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	// the target has no ID
+	name := strings.TrimSuffix(filepath.Base(flags.in), filepath.Ext(flags.in))
+
+	if flags.outputFormat == "sbol" {
+		if err := writeSBOL(flags.out, name, target.Seq, [][]*Frag{solution}); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.synthFasta != "" {
+		if err := writeSynthFasta(flags.synthFasta, synthFasta(name, len(target.Seq), [][]*Frag{solution})); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.bom != "" {
+		if err := writeBOM(flags.bom, bomRows(name, [][]*Frag{solution}, flags.backboneMeta, conf)); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.overlapReport != "" {
+		if err := writeOverlapReport(flags.overlapReport, overlapRows(target.Seq, [][]*Frag{solution}, conf)); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
 }
 
 // fragments pieces together a list of fragments into a single plasmid
@@ -71,7 +151,15 @@ func fragments(frags []*Frag, conf *config.Config) (target *Frag, solution []*Fr
 		stderr.Fatalln("failed: no fragments to assemble")
 	}
 
+	// each fragment has to be long enough to overlap its neighbors
+	for _, f := range frags {
+		if err := checkLength("fragment", f, conf.FragmentsMinHomology); err != nil {
+			stderr.Fatalln(inPhase(phaseInput, err, nil))
+		}
+	}
+
 	// anneal the fragments together, shift their junctions and create the plasmid sequence
+	setBuildCache(frags, newBuildCache())
 	vecSeq := annealFragments(conf.FragmentsMinHomology, conf.FragmentsMaxHomology, frags)
 
 	// create the assumed target plasmid object
@@ -84,19 +172,22 @@ func fragments(frags []*Frag, conf *config.Config) (target *Frag, solution []*Fr
 	a := assembly{frags: frags}
 	solution, err := a.fill(target.Seq, conf)
 	if err != nil {
-		stderr.Fatalln(err)
+		stderr.Fatalln(inPhase(phasePrimer, err, nil))
 	}
 
 	return target, solution
 }
 
-// annealFragments shifts the start and end of junctions that overlap one another
+// annealFragments shifts the start and end of junctions that overlap one another.
+// Fragments with a scar pinned between them aren't annealed: the scar is put between
+// them in the plasmid and the primers' tails add it
 func annealFragments(min, max int, frags []*Frag) (vec string) {
 	// set the start, end, and plasmid sequence
 	// add all of each frags seq to the plasmid sequence, minus the region overlapping the next
 	var vecSeq strings.Builder
 	for i, f := range frags {
 		next := frags[(i+1)%len(frags)]
+		f.Scar = f.scarTo(next)
 		// if we're on the last fragment, mock the first one further along the plasmid
 		if i == len(frags)-1 {
 			nextSeq := next.Seq
@@ -110,7 +201,10 @@ func annealFragments(min, max int, frags []*Frag) (vec string) {
 			}
 		}
 
-		j := len(f.junction(next, min, max)) // junction length
+		j := 0 // junction length
+		if f.Scar == "" {
+			j = len(f.junction(next, min, max))
+		}
 
 		fragSeq := f.Seq
 		if f.PCRSeq != "" {
@@ -124,11 +218,199 @@ func annealFragments(min, max int, frags []*Frag) (vec string) {
 
 		// add this Frag's sequence onto the accumulated plasmid sequence
 		vecSeq.WriteString(contrib)
+		vecSeq.WriteString(f.Scar)
 	}
 
 	return vecSeq.String()
 }
 
+// flankInsert pins the sites of the left and right enzymes, for subcloning the insert out
+// of the plasmid later, at the ends of the insert: the fragments after the backbone or,
+// without one, all of them in order. The primers' tails add the sites with the spacer outside
+// each. The right site is reverse complemented so a Type IIS enzyme's cut is in the insert.
+// Other sites in the insert are found after it's assembled, by insertFlankSites.
+func flankInsert(frags []*Frag, hasBackbone bool, left, right enzyme, spacer string, conf *config.Config) error {
+	insert := frags
+	if hasBackbone {
+		insert = frags[1:]
+	}
+	if len(insert) < 1 {
+		return fmt.Errorf("failed to flank the insert with sites: no insert fragments")
+	}
+
+	leftSite, err := siteSeq(left)
+	if err != nil {
+		return fmt.Errorf("failed to flank the insert with sites: %v", err)
+	}
+	rightSite, err := siteSeq(right)
+	if err != nil {
+		return fmt.Errorf("failed to flank the insert with sites: %v", err)
+	}
+	rightSite = reverseComplement(rightSite)
+
+	pinInsertEnds(frags, hasBackbone, spacer+leftSite, rightSite+spacer, conf)
+
+	stderr.Printf("flanked the insert with %s (%s) and %s (%s) sites\n", left.name, leftSite, right.name, rightSite)
+	return nil
+}
+
+// insertFlankSites returns the sites of the left and right enzymes in the assembled insert,
+// the plasmid after the backbone or, without one, all of it. They're 0-indexed on the
+// plasmid. Errors if the enzymes have sites in the insert other than the two flanking it,
+// including sites that span the junctions between its fragments.
+func insertFlankSites(plasmid string, frags []*Frag, hasBackbone bool, left, right enzyme) (sites []config.Range, err error) {
+	plasmid = strings.ToUpper(plasmid)
+	insertStart, insert := 0, plasmid
+	if hasBackbone {
+		insertStart = frags[0].end + 1 // the backbone's scar isn't annealed, it's all in the plasmid
+		insert = plasmid[insertStart:]
+	} else if len(plasmid) > 0 {
+		insert += plasmid[:len(plasmid)/2] // catch sites across the zero-index
+	}
+
+	enzymes := []enzyme{left}
+	if right.name != left.name {
+		enzymes = append(enzymes, right)
+	}
+	cuts, _ := cutsites(insert, enzymes)
+
+	var positions []string
+	for _, c := range cuts {
+		start := insertStart + c.index
+		if start >= len(plasmid) {
+			continue // already found before the zero-index
+		}
+		sites = append(sites, config.Range{Start: start, End: start + len(c.enzyme.recog) - 1, Name: c.enzyme.name})
+		positions = append(positions, fmt.Sprintf("%s at bp %d", c.enzyme.name, start+1))
+	}
+	if len(sites) > 2 {
+		return nil, fmt.Errorf("failed to flank the insert with sites: the assembled insert has %d sites, %s, and can only have the two flanking it", len(sites), strings.Join(positions, ", "))
+	}
+
+	return sites, nil
+}
+
+// seqPrimers are universal sequencing primers whose sites can be added to the insert.
+var seqPrimers = map[string]string{
+	"M13F":   "GTAAAACGACGGCCAGT", // M13 forward (-20)
+	"M13R":   "CAGGAAACAGCTATGAC", // M13 reverse (-27)
+	"T7":     "TAATACGACTCACTATAGGG",
+	"T7term": "GCTAGTTATTGCTCAGCGG",
+	"T3":     "ATTAACCCTCACTAAAGGGA",
+	"SP6":    "ATTTAGGTGACACTATAG",
+}
+
+// seqPrimer returns the sequence of the sequencing primer with the name, which isn't case
+// sensitive: one in the seq-primers setting or, if there isn't one, a universal primer.
+func seqPrimer(name string, conf *config.Config) (string, error) {
+	if seq, set := conf.SeqPrimers[strings.ToLower(name)]; set { // viper lower cases keys
+		if seq = strings.ToUpper(seq); seq == "" || strings.Trim(seq, "ACGT") != "" {
+			return "", fmt.Errorf("sequencing primer %s in the settings, %s, has bp other than A, C, G and T", name, seq)
+		}
+		return seq, nil
+	}
+
+	names := []string{}
+	for primer, seq := range seqPrimers {
+		if strings.EqualFold(primer, name) {
+			return seq, nil
+		}
+		names = append(names, primer)
+	}
+	for primer := range conf.SeqPrimers {
+		names = append(names, primer)
+	}
+	sort.Strings(names)
+
+	return "", fmt.Errorf("unknown sequencing primer %s, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// addSeqPrimerSites pins the sites of the sequencing primers at the ends of the insert, for
+// verifying it later: the first primer's on its left end, reading into the insert, and the
+// second's, if there is one, reverse complemented on its right end. Errors if the 3' end
+// of a primer already binds one of the fragments and it would misprime.
+func addSeqPrimerSites(frags []*Frag, hasBackbone bool, conf *config.Config) error {
+	insert := frags
+	if hasBackbone {
+		insert = frags[1:]
+	}
+	if len(insert) < 1 {
+		return fmt.Errorf("failed to add sequencing primers' sites: no insert fragments")
+	}
+
+	var sites []string
+	for _, name := range conf.SeqPrimerSites {
+		seq, err := seqPrimer(name, conf)
+		if err != nil {
+			return fmt.Errorf("failed to add sequencing primers' sites: %v", err)
+		}
+
+		end := primerEnd(Primer{Seq: seq})
+		for _, f := range frags {
+			fragSeq := strings.ToUpper(f.Seq)
+			if strings.Contains(fragSeq, end) || strings.Contains(fragSeq, reverseComplement(end)) {
+				return fmt.Errorf("failed to add sequencing primers' sites: %s binds %s", name, f.ID)
+			}
+		}
+		sites = append(sites, seq)
+	}
+
+	left, right := sites[0], ""
+	if len(sites) > 1 {
+		right = reverseComplement(sites[1])
+	}
+	pinInsertEnds(frags, hasBackbone, left, right, conf)
+
+	stderr.Printf("added the %s sequencing primers' sites to the insert\n", strings.Join(conf.SeqPrimerSites, " and "))
+	return nil
+}
+
+// pinInsertEnds pins the left sequence before the first fragment of the insert, and the
+// right after its last, with the primers' tails. Each is next to the insert, inside any
+// scars already pinned at its ends.
+func pinInsertEnds(frags []*Frag, hasBackbone bool, left, right string, conf *config.Config) {
+	insert := frags
+	if hasBackbone {
+		insert = frags[1:]
+	}
+
+	if conf.JunctionScars == nil {
+		conf.JunctionScars = make(map[string]string)
+	}
+	pin := func(before, after *Frag, outside, inside string) {
+		if outside+inside == "" {
+			return
+		}
+		existing, _ := conf.JunctionScar(before.ID, after.ID)
+		conf.JunctionScars[before.ID+"/"+after.ID] = outside + existing + inside
+	}
+
+	first, last := insert[0], insert[len(insert)-1]
+	if hasBackbone {
+		pin(frags[0], first, "", left)
+		pin(last, frags[0], right, "")
+	} else {
+		pin(last, first, right, left)
+	}
+}
+
+// closingJunctions checks that each solution of a plasmid built without a backbone is
+// closed by a junction between its last and first fragments
+func closingJunctions(solutions [][]*Frag, conf *config.Config) error {
+	for _, frags := range solutions {
+		if len(frags) == 0 {
+			continue
+		}
+
+		last, first := frags[len(frags)-1], frags[0]
+		if last.junction(first, conf.FragmentsMinHomology, conf.FragmentsMaxHomology+1) == "" {
+			return fmt.Errorf("no junction closes the plasmid between %s and %s", last.ID, first.ID)
+		}
+	}
+
+	return nil
+}
+
 // validateJunctions checks each fragment and confirms that it has sufficient homology
 // with its adjacent fragments and that the match is exact. Largely for testing
 func validateJunctions(frags []*Frag, conf *config.Config) error {
@@ -1,7 +1,10 @@
 package repp
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/jjtimmons/repp/config"
 )
 
 func Test_annealFragments(t *testing.T) {
@@ -101,6 +104,35 @@ func Test_annealFragments(t *testing.T) {
 			},
 			"ACGTGCTAGCTACATCGATCGTAGCTAGCTAGCATCGACTGATCACTAGCATCGACTAGCTAGAACTGATCTAG",
 		},
+		{
+			"put a pinned scar between two fragments that overlap",
+			args{
+				min: 5,
+				max: 10,
+				frags: []*Frag{
+					&Frag{
+						ID:   "a",
+						Seq:  "TGCATATGGTGCGAATTGCCGAGAACCCGGCCCCACGCAATGGAACGTCTTTAGCTCCGGCAGGCAATTAAGGACAACGTAAGTATAGCGCATATAAACA",
+						conf: &config.Config{JunctionScars: map[string]string{"a/b": "GGTGGCGGTTCA"}},
+					},
+					&Frag{
+						ID:  "b",
+						Seq: "CATATAAACACGAATGAACCTATTCGTACCGTATCGAAGAATAGCCTCGCGGAGGCATGTGCCATGCTAGCGTGCGGGGCACTCTAGTTATGCATATGGT",
+					},
+				},
+			},
+			[]*Frag{
+				&Frag{
+					start: 0,
+					end:   99,
+				},
+				&Frag{
+					start: 112,
+					end:   211,
+				},
+			},
+			"TGCATATGGTGCGAATTGCCGAGAACCCGGCCCCACGCAATGGAACGTCTTTAGCTCCGGCAGGCAATTAAGGACAACGTAAGTATAGCGCATATAAACAGGTGGCGGTTCACATATAAACACGAATGAACCTATTCGTACCGTATCGAAGAATAGCCTCGCGGAGGCATGTGCCATGCTAGCGTGCGGGGCACTCTAGTTA",
+		},
 	}
 
 	for _, tt := range tests {
@@ -124,3 +156,303 @@ func Test_annealFragments(t *testing.T) {
 		})
 	}
 }
+
+func Test_orientFrags(t *testing.T) {
+	frags := orientFrags([]*Frag{
+		&Frag{
+			ID:  "fwd",
+			Seq: "ACGTGCTAGCTACATCGATCGTAGCTAGCTAGCATCG",
+		},
+		&Frag{
+			ID:  "rev:rev",
+			Seq: "CTAGCTAGTCGATGCTAGTGATCAGTCGATGCTAGCT", // reverse complement of AGCTAGCATCGACTGATCACTAGCATCGACTAGCTAG
+		},
+	})
+
+	if frags[1].ID != "rev" {
+		t.Errorf("orientFrags() ID = %s, want rev", frags[1].ID)
+	}
+
+	// the reversed fragment should land reverse complemented in the plasmid
+	wantVec := "ACGTGCTAGCTACATCGATCGTAGCTAGCTAGCATCGACTGATCACTAGCATCGACTAGCTAG"
+	if gotVec := annealFragments(5, 15, frags); gotVec != wantVec {
+		t.Errorf("annealFragments() = %s, want %s", gotVec, wantVec)
+	}
+}
+
+func Test_flankInsert(t *testing.T) {
+	ecoRI := newEnzyme("EcoRI", "G^AATT_C")
+	bsaI := newEnzyme("BsaI", "GGTCTCN^NNNN_")
+
+	backbone := &Frag{ID: "pSB1A3", Seq: "TTTTTTTTTTTTTTTTTTTT"}
+	gfp := &Frag{ID: "GFP", Seq: "ATGCGTAAAGGCGAAGAACTGTAA"}
+	term := &Frag{ID: "B0015", Seq: "CCAGGCATCAAATAAAACGAAAGG"}
+
+	tests := []struct {
+		name        string
+		frags       []*Frag
+		hasBackbone bool
+		left, right enzyme
+		spacer      string
+		scars       map[string]string
+		wantScars   map[string]string
+		wantErr     bool
+	}{
+		{
+			"sites between the backbone and the insert, with spacers",
+			[]*Frag{backbone, gfp, term},
+			true,
+			ecoRI,
+			bsaI,
+			"GC",
+			nil,
+			map[string]string{
+				"pSB1A3/GFP":   "GCGAATTC",
+				"B0015/pSB1A3": "GAGACCGC",
+			},
+			false,
+		},
+		{
+			"both sites between the last and first fragments without a backbone",
+			[]*Frag{gfp, term},
+			false,
+			ecoRI,
+			ecoRI,
+			"",
+			nil,
+			map[string]string{
+				"B0015/GFP": "GAATTCGAATTC",
+			},
+			false,
+		},
+		{
+			"sites next to the insert, inside a scar already pinned",
+			[]*Frag{backbone, gfp, term},
+			true,
+			ecoRI,
+			ecoRI,
+			"",
+			map[string]string{"pSB1A3/GFP": "TACTAG"},
+			map[string]string{
+				"pSB1A3/GFP":   "TACTAGGAATTC",
+				"B0015/pSB1A3": "GAATTC",
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.New()
+			conf.JunctionScars = tt.scars
+
+			err := flankInsert(tt.frags, tt.hasBackbone, tt.left, tt.right, tt.spacer, conf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("flankInsert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(conf.JunctionScars) != len(tt.wantScars) {
+				t.Errorf("flankInsert() scars = %v, want %v", conf.JunctionScars, tt.wantScars)
+			}
+			for junction, want := range tt.wantScars {
+				if got := conf.JunctionScars[junction]; got != want {
+					t.Errorf("flankInsert() scar of %s = %s, want %s", junction, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_insertFlankSites(t *testing.T) {
+	ecoRI := newEnzyme("EcoRI", "G^AATT_C")
+	bamHI := newEnzyme("BamHI", "G^GATC_C")
+
+	backbone := &Frag{ID: "pSB1A3", Seq: "TTTTTTTTTTGAATTCTTTT", end: 19}
+
+	tests := []struct {
+		name        string
+		plasmid     string
+		hasBackbone bool
+		want        []config.Range
+		wantErr     bool
+	}{
+		{
+			"only the flanking sites, not the backbone's",
+			"TTTTTTTTTTGAATTCTTTT" + "GAATTCATGCGTAAAGGCGAAGAACTGTAAGGATCC",
+			true,
+			[]config.Range{{Start: 20, End: 25, Name: "EcoRI"}, {Start: 50, End: 55, Name: "BamHI"}},
+			false,
+		},
+		{
+			"a site across a junction of the insert's fragments",
+			"TTTTTTTTTTGAATTCTTTT" + "GAATTCATGCGTAAAGGAATTCAACTGTAAGGATCC",
+			true,
+			nil,
+			true,
+		},
+		{
+			"sites across the zero-index without a backbone",
+			"ATCCGAATTCATGCGTAAAGGCGAAGAACTGTAAGG",
+			false,
+			[]config.Range{{Start: 4, End: 9, Name: "EcoRI"}, {Start: 34, End: 39, Name: "BamHI"}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := insertFlankSites(tt.plasmid, []*Frag{backbone}, tt.hasBackbone, ecoRI, bamHI)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("insertFlankSites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("insertFlankSites() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_addSeqPrimerSites(t *testing.T) {
+	backbone := &Frag{ID: "pSB1A3", Seq: "TTTTTTTTTTTTTTTTTTTT"}
+	gfp := &Frag{ID: "GFP", Seq: "ATGCGTAAAGGCGAAGAACTGTAA"}
+	term := &Frag{ID: "B0015", Seq: "CCAGGCATCAAATAAAACGAAAGG"}
+
+	tests := []struct {
+		name        string
+		frags       []*Frag
+		hasBackbone bool
+		primers     []string
+		seqPrimers  map[string]string
+		wantScars   map[string]string
+		wantErr     bool
+	}{
+		{
+			"universal primers' sites between the backbone and the insert",
+			[]*Frag{backbone, gfp, term},
+			true,
+			[]string{"M13F", "m13r"},
+			nil,
+			map[string]string{
+				"pSB1A3/GFP":   "GTAAAACGACGGCCAGT",
+				"B0015/pSB1A3": "GTCATAGCTGTTTCCTG",
+			},
+			false,
+		},
+		{
+			"only the left end, from a primer in the settings",
+			[]*Frag{backbone, gfp, term},
+			true,
+			[]string{"pGEX-F"},
+			map[string]string{"pgex-f": "GGGCTGGCAAGCCACGTTTGGTG"},
+			map[string]string{
+				"pSB1A3/GFP": "GGGCTGGCAAGCCACGTTTGGTG",
+			},
+			false,
+		},
+		{
+			"a primer can't bind the fragments",
+			[]*Frag{backbone, gfp, &Frag{ID: "lacZ", Seq: "CCCCGTAAAACGACGGCCAGTCCCC"}},
+			true,
+			[]string{"M13F", "M13R"},
+			nil,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.New()
+			conf.SeqPrimers = tt.seqPrimers
+			conf.SeqPrimerSites = tt.primers
+
+			err := addSeqPrimerSites(tt.frags, tt.hasBackbone, conf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addSeqPrimerSites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(conf.JunctionScars, tt.wantScars) {
+				t.Errorf("addSeqPrimerSites() scars = %v, want %v", conf.JunctionScars, tt.wantScars)
+			}
+		})
+	}
+}
+
+func Test_validateJunctions(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 15
+	c.FragmentsMaxHomology = 120
+
+	target := "ATGAGTAAAGGAGAAGAACTTTTCACTGGAGTTGTCCCAATTCTTGTTGAATTAGATGGTGATGTTAATGGGCACAAATTTTCTGTCAGTGGAGAGGGTGAAGGTGATGC"
+
+	tests := []struct {
+		name    string
+		frags   []*Frag
+		wantErr bool
+	}{
+		{
+			"circle closed by the last and first fragments",
+			[]*Frag{
+				{ID: "1", Seq: target[:50], conf: c},
+				{ID: "2", Seq: target[30:80], conf: c},
+				{ID: "3", Seq: target[60:] + target[:20], conf: c},
+			},
+			false,
+		},
+		{
+			"no seam between the last and first fragments",
+			[]*Frag{
+				{ID: "1", Seq: target[:50], conf: c},
+				{ID: "2", Seq: target[30:80], conf: c},
+				{ID: "3", Seq: target[60:], conf: c},
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateJunctions(tt.frags, c); (err != nil) != tt.wantErr {
+				t.Errorf("validateJunctions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_closingJunctions(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 15
+	c.FragmentsMaxHomology = 120
+
+	target := "ATGAGTAAAGGAGAAGAACTTTTCACTGGAGTTGTCCCAATTCTTGTTGAATTAGATGGTGATGTTAATGGGCACAAATTTTCTGTCAGTGGAGAGGGTGAAGGTGATGC"
+
+	closed := []*Frag{
+		{ID: "1", Seq: target[:50], conf: c},
+		{ID: "2", Seq: target[30:80], conf: c},
+		{ID: "3", Seq: target[60:] + target[:20], conf: c},
+	}
+	unclosed := []*Frag{
+		{ID: "1", Seq: target[:50], conf: c},
+		{ID: "2", Seq: target[30:80], conf: c},
+		{ID: "3", Seq: target[60:], conf: c},
+	}
+
+	tests := []struct {
+		name      string
+		solutions [][]*Frag
+		wantErr   bool
+	}{
+		{"closed by the last and first fragments", [][]*Frag{closed}, false},
+		{"a solution without a closing junction", [][]*Frag{closed, unclosed}, true},
+		{"no solutions", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := closingJunctions(tt.solutions, c); (err != nil) != tt.wantErr {
+				t.Errorf("closingJunctions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
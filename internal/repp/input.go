@@ -7,8 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/jjtimmons/repp/config"
 	"github.com/spf13/cobra"
@@ -16,7 +18,7 @@ import (
 
 var (
 	// stderr is for logging to Stderr (without an annoying timestamp)
-	stderr = log.New(os.Stderr, "", 0)
+	stderr = &errLogger{Logger: log.New(os.Stderr, "", 0)}
 )
 
 // Flags contains parsed cobra Flags like "in", "out", "dbs", etc that are used by multiple commands.
@@ -27,9 +29,39 @@ type Flags struct {
 	// the name of the file to write the output to
 	out string
 
+	// the format of additional output files: "json" (default) or "benchling"
+	outputFormat string
+
+	// input files of a batch run, one target in each
+	batch []string
+
+	// workers is the number of a batch run's targets to design concurrently
+	workers int
+
+	// the name of the file to write a batch run's cost report to
+	costReport string
+
+	// the name of the FASTA file to write the cheapest solution's synthetic fragments to
+	synthFasta string
+
+	// the name of the CSV file to write the solutions' bill of materials to
+	bom string
+
+	// the name of the TSV file to write the solutions' junctions and their QC checks to
+	overlapReport string
+
+	// the name of the DOT file to write the graph of fragments searched for assemblies to
+	graph string
+
 	// a list of dbs to run BLAST against (their names' on the filesystem)
 	dbs []string
 
+	// a list of the user's inventory dbs (also included in dbs)
+	inventory []string
+
+	// a list of dbs made from the fragments the user has in hand (also included in dbs)
+	have []string
+
 	// the backbone (optional) to insert the pieces into
 	backbone *Frag
 
@@ -39,8 +71,54 @@ type Flags struct {
 	// slice of strings to weed out fragments from BLAST matches
 	filters []string
 
+	// IDs of fragments that must be in every assembly
+	required []string
+
+	// ranges, or feature names, of the target that junctions can't be in
+	noJunctions []string
+
+	// whether to prefer junctions on the boundaries of the features annotated on the target
+	featureJunctions bool
+
+	// a layout file of fragments to design primers for, without BLAST or the assembly search
+	primersOnly string
+
+	// inserts to clone into the backbone at positions on it, as "file[:rev]@position"
+	inserts []string
+
+	// the backbone of a multi-insert cloning, as it is in the database and uncut
+	insertBackbone *Frag
+
+	// enzymes whose sites are added to the left and right ends of the insert, for subcloning
+	flankSites []enzyme
+
+	// bp between each of the flank sites and the sequence outside the insert
+	flankSpacer string
+
+	// whether to error out, rather than warn, on risky designs
+	strict bool
+
+	// whether to also design the target's reverse complement and keep the cheaper design
+	bothStrands bool
+
+	// whether to list the backbone's digestion products rather than build
+	products bool
+
+	// whether the whole plasmid is built de novo, closed by a junction between its last and first fragments
+	noBackbone bool
+
+	// whether to remove, rather than reject, invalid characters in input sequences
+	stripInvalid bool
+
 	// percentage identity for finding building fragments in BLAST databases
 	identity int
+
+	// dbIdentity are the percentage identities of specific BLAST databases, by their
+	// paths, in place of the identity
+	dbIdentity map[string]int
+
+	// whether BLAST masks low-complexity regions of the query with DUST
+	dust bool
 }
 
 // inputParser contains methods for parsing flags from the input &cobra.Command.
@@ -52,22 +130,27 @@ func NewFlags(
 	enzymes, dbs []string,
 	addgene, igem, dnasu bool,
 ) (*Flags, *config.Config) {
-	c := config.New()
-
-	if addgene {
-		dbs = append(dbs, config.AddgeneDB)
-	}
-	if igem {
-		dbs = append(dbs, config.IGEMDB)
-	}
-	if dnasu {
-		dbs = append(dbs, config.DNASUDB)
+	flags, c, err := DesignFlags(in, out, backbone, filter, enzymes, dbs, addgene, igem, dnasu)
+	if err != nil {
+		stderr.Fatal(err)
 	}
+	return flags, c
+}
+
+// DesignFlags makes a new flags object manually, as NewFlags does, but returns a failure
+// to digest the backbone rather than logging it. For Design.
+func DesignFlags(
+	in, out, backbone, filter string,
+	enzymes, dbs []string,
+	addgene, igem, dnasu bool,
+) (*Flags, *config.Config, error) {
+	c := config.New()
+	dbs = append(dbs, repositoryDBs(addgene, igem, dnasu, c)...)
 
 	p := inputParser{}
-	parsedBB, bbMeta, err := p.parseBackbone(backbone, enzymes, dbs, c)
+	parsedBB, bbMeta, err := p.parseBackbone(backbone, enzymes, []string{}, dbs, c)
 	if err != nil {
-		stderr.Fatal(err)
+		return nil, nil, err
 	}
 
 	if strings.Contains(in, ",") {
@@ -82,12 +165,16 @@ func NewFlags(
 		backboneMeta: bbMeta,
 		filters:      p.getFilters(filter),
 		identity:     98,
-	}, c
+		dust:         true,
+	}, c, nil
 }
 
 // parseCmdFlags gathers the in path, out path, etc from a cobra cmd object
 // returns Flags and a Config struct for repp.Plasmid or repp.Fragments.
 func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *config.Config) {
+	stderr.phase = phaseInput
+	defer func() { stderr.phase = "" }()
+
 	cmdName := strings.ToLower(cmd.Name())
 
 	var err error
@@ -95,8 +182,16 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 	p := inputParser{}
 	c := config.New()
 
+	// inserts to clone into distinct sites of the backbone
+	inserts, _ := cmd.Flags().GetString("inserts")
+	fs.inserts = p.parseCommaList(inserts)
+
 	if fs.in, err = cmd.Flags().GetString("in"); fs.in == "" || err != nil {
-		if cmdName == "features" {
+		if cmdName == "sequence" && len(fs.inserts) > 0 {
+			if fs.in, _, _, err = parseInsert(fs.inserts[0]); err != nil { // name the output after it
+				stderr.Fatal(err)
+			}
+		} else if cmdName == "features" {
 			fs.in = p.parseFeatureInput(args)
 		} else if cmdName == "sequence" && len(args) > 0 {
 			fs.in = "input.fa"
@@ -110,6 +205,20 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 		}
 	}
 
+	// a batch run if multiple input files were passed
+	if cmdName == "sequence" && strings.Contains(fs.in, ",") {
+		fs.batch = p.parseCommaList(fs.in)
+		fs.in = fs.batch[0]
+	}
+	fs.costReport, _ = cmd.Flags().GetString("cost-report")
+	if fs.workers, err = cmd.Flags().GetInt("workers"); err == nil && fs.workers < 1 {
+		stderr.Fatalf("must design targets with at least one worker, not %d", fs.workers)
+	}
+	fs.synthFasta, _ = cmd.Flags().GetString("synth-fasta")
+	fs.bom, _ = cmd.Flags().GetString("bom")
+	fs.graph, _ = cmd.Flags().GetString("graph")
+	fs.overlapReport, _ = cmd.Flags().GetString("overlap-report")
+
 	if fs.out, err = cmd.Flags().GetString("out"); strict && (fs.out == "" || err != nil) {
 		fs.out = p.guessOutput(fs.in) // guess at an output name
 
@@ -119,6 +228,24 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 		}
 	}
 
+	if fs.outputFormat, err = cmd.Flags().GetString("output-format"); err != nil || fs.outputFormat == "" {
+		fs.outputFormat = "json"
+	}
+	if fs.outputFormat != "json" && fs.outputFormat != "jsonl" && fs.outputFormat != "benchling" && fs.outputFormat != "sbol" {
+		cmd.Help()
+		stderr.Fatalf("unknown output format: %s. must be json, jsonl, benchling or sbol", fs.outputFormat)
+	}
+	if fs.outputFormat == "jsonl" {
+		if cmdName != "sequence" {
+			stderr.Fatal("jsonl output is only for the targets of 'repp make sequence'")
+		}
+
+		// every target's output is a line of the same file
+		if !cmd.Flags().Changed("out") {
+			fs.out = strings.TrimSuffix(fs.out, ".json") + ".jsonl"
+		}
+	}
+
 	addgene, err := cmd.Flags().GetBool("addgene") // use addgene db?
 	if strict && err != nil {
 		cmd.Help()
@@ -151,6 +278,144 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 	// try to split the filter fields into a list
 	fs.filters = p.getFilters(filters)
 
+	// fragments that every assembly has to include
+	required, _ := cmd.Flags().GetString("require")
+	fs.required = p.parseCommaList(required)
+
+	// fragments in the order they're preferred in
+	orderHint, _ := cmd.Flags().GetString("order-hint")
+	c.OrderHint = p.parseCommaList(orderHint)
+
+	// regions of the target that fragment junctions can't be in
+	noJunctions, _ := cmd.Flags().GetString("no-junctions")
+	fs.noJunctions = p.parseCommaList(noJunctions)
+	fs.featureJunctions, _ = cmd.Flags().GetBool("feature-junctions")
+
+	// use a synthesis vendor's length limits and costs
+	if vendor, _ := cmd.Flags().GetString("synth-vendor"); vendor != "" {
+		if err := c.UseSynthVendor(vendor); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// 5' modifications of the primers' ordered sequences
+	if primerMods, _ := cmd.Flags().GetString("primer-mod"); primerMods != "" {
+		if c.PrimerModifications, err = p.parsePrimerMods(primerMods); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// primer3 settings to pass through to primer3
+	if primer3File, _ := cmd.Flags().GetString("primer3-settings"); primer3File != "" {
+		if err = c.ReadPrimer3Settings(primer3File); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+	if _, ignored := primer3Settings(c); len(ignored) > 0 {
+		stderr.Printf("warning: ignoring primer3 settings that are set from the fragments: %s\n", strings.Join(ignored, ", "))
+	}
+
+	// a fixed cost for each distinct source plasmid in an assembly
+	if cmd.Flags().Changed("source-cost") {
+		if c.CostSource, err = cmd.Flags().GetFloat64("source-cost"); err != nil || c.CostSource < 0 {
+			stderr.Fatal("failed to parse --source-cost, expected a cost >= 0")
+		}
+	}
+
+	// the PCR master mix's concentrations, for primer Tms
+	for flag, setting := range map[string]*float64{
+		"monovalent-conc": &c.PCRMonovalentConc,
+		"divalent-conc":   &c.PCRDivalentConc,
+		"dntp-conc":       &c.PCRDNTPConc,
+		"primer-conc":     &c.PCRPrimerConc,
+	} {
+		if cmd.Flags().Changed(flag) {
+			if *setting, err = cmd.Flags().GetFloat64(flag); err != nil || *setting < 0 {
+				stderr.Fatalf("failed to parse --%s, expected a concentration >= 0", flag)
+			}
+		}
+	}
+
+	// the minimum length of PCR and synthetic fragments
+	if cmd.Flags().Changed("min-fragment-length") {
+		if c.FragmentsMinLength, err = cmd.Flags().GetInt("min-fragment-length"); err != nil || c.FragmentsMinLength < 0 {
+			stderr.Fatal("failed to parse --min-fragment-length, expected a length >= 0")
+		}
+	}
+
+	// how adjacent fragments are joined, Gibson or overlap-extension PCR
+	method, _ := cmd.Flags().GetString("junction-method")
+	if method == "" {
+		method = c.JunctionMethod
+	}
+	if err := c.UseJunctionMethod(method); err != nil {
+		stderr.Fatal(err)
+	}
+
+	// overlap lengths for specific junctions, in place of the automatic selection
+	if overlaps, _ := cmd.Flags().GetString("junction-overlap"); overlaps != "" {
+		if err := c.SetJunctionOverlaps(overlaps); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// sequences pinned between specific fragments, added by their primers' tails
+	if scars, _ := cmd.Flags().GetString("junction-scar"); scars != "" {
+		if err := c.SetJunctionScars(scars); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// enzymes whose sites are removed from synthetic fragments
+	if avoidSites, _ := cmd.Flags().GetString("avoid-sites"); avoidSites != "" {
+		if c.AvoidSites, err = p.parseAvoidSites(avoidSites); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// enzyme sites added to the ends of the insert by the primers, for subcloning it later
+	if flankSites, _ := cmd.Flags().GetString("flank-sites"); flankSites != "" {
+		if fs.flankSites, err = p.parseFlankSites(flankSites); err != nil {
+			stderr.Fatal(err)
+		}
+
+		fs.flankSpacer, _ = cmd.Flags().GetString("flank-spacer")
+		if fs.flankSpacer = strings.ToUpper(fs.flankSpacer); strings.Trim(fs.flankSpacer, "ACGT") != "" {
+			stderr.Fatalf("failed to parse --flank-spacer %s, expected only A, C, G and T", fs.flankSpacer)
+		}
+	}
+
+	// sequencing primers' sites added to the ends of the insert, for verifying it
+	if seqPrimerSites, _ := cmd.Flags().GetString("seq-primer-sites"); seqPrimerSites != "" {
+		if c.SeqPrimerSites, err = p.parseSeqPrimerSites(seqPrimerSites, c); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	// split the primers into their tails and annealing regions in the output
+	c.PrimerTails, _ = cmd.Flags().GetBool("primer-tails")
+
+	// check the primers for repeated binding sites in the assembled plasmid
+	c.PrimerRepeats, _ = cmd.Flags().GetBool("primer-repeats")
+
+	// log why the cheapest solution was chosen
+	c.Explain, _ = cmd.Flags().GetBool("explain")
+
+	// file to log the assemblies rejected in the search to
+	c.RejectLog, _ = cmd.Flags().GetString("reject-log")
+
+	// optimize the solutions for cost, turnaround time or the longest synthetic fragment
+	if c.Optimize, _ = cmd.Flags().GetString("optimize"); c.Optimize != "" && c.Optimize != "cost" && c.Optimize != "time" && c.Optimize != "max-synth-length" {
+		cmd.Help()
+		stderr.Fatalf("unknown optimization: %s. must be cost, time or max-synth-length", c.Optimize)
+	}
+
+	fs.primersOnly, _ = cmd.Flags().GetString("primers-only")
+	fs.strict, _ = cmd.Flags().GetBool("strict")
+	fs.bothStrands, _ = cmd.Flags().GetBool("both-strands")
+	fs.products, _ = cmd.Flags().GetBool("products")
+	fs.stripInvalid, _ = cmd.Flags().GetBool("strip-invalid")
+
 	identity, err := cmd.Flags().GetInt("identity")
 	if err != nil {
 		identity = 100 // might be something other than `repp plasmid`
@@ -158,6 +423,27 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 	// set identity for blastn searching
 	fs.identity = identity
 
+	// mask low-complexity regions of the target before BLAST, on unless turned off
+	fs.dust = true
+	if dust, err := cmd.Flags().GetString("dust"); err == nil {
+		switch strings.ToLower(dust) {
+		case "on", "":
+		case "off":
+			fs.dust = false
+		default:
+			stderr.Fatalf("unknown --dust setting: %s. must be on or off", dust)
+		}
+	}
+
+	// make BLAST dbs from FASTA files
+	if dbFasta, _ := cmd.Flags().GetString("db-fasta"); dbFasta != "" {
+		fastaPaths, err := fastaDBs(p.parseCommaList(dbFasta))
+		if err != nil {
+			stderr.Fatalln(err)
+		}
+		dbString = strings.Join(append(p.parseCommaList(dbString), fastaPaths...), ",")
+	}
+
 	if dbString == "" && !addgene && !igem && !dnasu {
 		fmt.Println("no fragment databases chosen [-agu]: using Addgene, DNASU, and iGEM by default")
 		addgene = true
@@ -165,19 +451,126 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 		dnasu = true
 	}
 	// read in the BLAST DB paths
-	if fs.dbs, err = p.parseDBs(dbString, addgene, igem, dnasu); err != nil || len(fs.dbs) == 0 {
+	if fs.dbs, err = p.parseDBs(dbString, addgene, igem, dnasu, c); err != nil || len(fs.dbs) == 0 {
 		stderr.Fatalf("failed to find any fragment databases: %v", err)
 	}
 
+	// read in the inventory DB paths, these are BLAST'ed alongside the others
+	if inventoryString, err := cmd.Flags().GetString("inventory"); err == nil && inventoryString != "" {
+		if fs.inventory, err = p.parseDBs(inventoryString, false, false, false, c); err != nil {
+			stderr.Fatalf("failed to find inventory databases: %v", err)
+		}
+		fs.dbs = append(fs.dbs, fs.inventory...)
+	}
+
+	// make BLAST dbs of the fragments in hand, also BLAST'ed alongside the others
+	if have, _ := cmd.Flags().GetString("have"); have != "" {
+		if fs.have, err = fastaDBs(p.parseCommaList(have)); err != nil {
+			stderr.Fatalf("failed to read in-hand fragments: %v", err)
+		}
+		fs.dbs = append(fs.dbs, fs.have...)
+	}
+
+	// identity thresholds of specific dbs, ex: stricter for a genome than for plasmids
+	if minIdentity, _ := cmd.Flags().GetString("min-identity"); minIdentity != "" {
+		if fs.dbIdentity, err = p.parseDBIdentity(minIdentity, fs.dbs); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
 	// check if user asked for a specific backbone, confirm it exists in one of the dbs
 	backbone, _ := cmd.Flags().GetString("backbone")
+	c.BackboneKeep5Overhangs, _ = cmd.Flags().GetBool("keep-5-overhangs")
 
 	// check if they also specified an enzyme
-	enzymeList, _ := cmd.Flags().GetString("enzymeList")
+	enzymeList, _ := cmd.Flags().GetString("enzymes")
 	enzymes := p.parseCommaList(enzymeList)
 
+	// or enzymes by their recognition sequence
+	enzymeSeqList, _ := cmd.Flags().GetString("enzyme-seq")
+	enzymeSeqs := p.parseCommaList(enzymeSeqList)
+
+	// the fragments of a primers-only layout are the whole plasmid, there's no backbone
+	if fs.primersOnly != "" {
+		auto, _ := cmd.Flags().GetBool("auto-enzyme")
+		if backbone != "" || len(enzymes) > 0 || len(enzymeSeqs) > 0 || auto || len(fs.inserts) > 0 {
+			stderr.Fatal("the layout of --primers-only is the whole plasmid, don't pass --backbone, --enzymes, --enzyme-seq, --auto-enzyme or --inserts with it")
+		}
+
+		fs.backbone, fs.backboneMeta = &Frag{}, &Backbone{}
+		return fs, c
+	}
+
+	// a whole plasmid built de novo is closed from the target's own ends, there's no backbone
+	if noBB, _ := cmd.Flags().GetBool("no-backbone"); noBB {
+		auto, _ := cmd.Flags().GetBool("auto-enzyme")
+		linearBB, _ := cmd.Flags().GetBool("linear-backbone")
+		switch {
+		case backbone != "" || linearBB:
+			stderr.Fatal("--no-backbone builds the plasmid without one, don't pass --backbone or --linear-backbone with it")
+		case len(enzymes) > 0 || len(enzymeSeqs) > 0 || auto || c.BackboneKeep5Overhangs || fs.products:
+			stderr.Fatal("there's no backbone to digest with --no-backbone, don't pass --enzymes, --enzyme-seq, --auto-enzyme, --keep-5-overhangs or --products with it")
+		case len(fs.inserts) > 0:
+			stderr.Fatal("--no-backbone isn't supported with --inserts, they're cloned into a backbone")
+		}
+
+		fs.noBackbone = true
+		fs.backbone, fs.backboneMeta = &Frag{}, &Backbone{}
+		return fs, c
+	}
+
+	// an already linear backbone is used as it is, without digesting it
+	if linearBB, _ := cmd.Flags().GetBool("linear-backbone"); linearBB {
+		auto, _ := cmd.Flags().GetBool("auto-enzyme")
+		switch {
+		case backbone == "":
+			stderr.Fatal("must pass a --backbone with --linear-backbone")
+		case len(enzymes) > 0 || len(enzymeSeqs) > 0 || auto:
+			stderr.Fatal("--linear-backbone isn't digested, don't pass --enzymes, --enzyme-seq or --auto-enzyme with it")
+		case len(fs.inserts) > 0:
+			stderr.Fatal("--linear-backbone isn't supported with --inserts, they're cloned into the uncut backbone")
+		}
+
+		bbFrag, err := queryDatabases(backbone, fs.dbs)
+		if err != nil {
+			stderr.Fatal(err)
+		}
+		if fs.backbone, fs.backboneMeta, err = linearBackbone(bbFrag); err != nil {
+			stderr.Fatal(err)
+		}
+		return fs, c
+	}
+
+	// the inserts of a multi-insert cloning go into the uncut backbone
+	if len(fs.inserts) > 0 {
+		auto, _ := cmd.Flags().GetBool("auto-enzyme")
+		switch {
+		case backbone == "":
+			stderr.Fatal("must pass a backbone to clone the inserts into")
+		case len(enzymes) > 0 || len(enzymeSeqs) > 0 || auto || c.BackboneKeep5Overhangs || fs.products:
+			stderr.Fatal("the inserts are cloned into the uncut backbone, don't pass --enzymes, --enzyme-seq, --auto-enzyme, --keep-5-overhangs or --products with --inserts")
+		}
+
+		if fs.insertBackbone, err = queryDatabases(backbone, fs.dbs); err != nil {
+			stderr.Fatal(err)
+		}
+		if fs.insertBackbone.fragType == circular {
+			fs.insertBackbone.Seq = fs.insertBackbone.Seq[:len(fs.insertBackbone.Seq)/2]
+		}
+
+		fs.backbone, fs.backboneMeta = &Frag{}, &Backbone{}
+		return fs, c
+	}
+
+	// or pick the enzyme that cuts the backbone once and none of the inserts
+	if auto, _ := cmd.Flags().GetBool("auto-enzyme"); auto && backbone != "" {
+		if enzymes, enzymeSeqs, err = p.parseAutoEnzyme(backbone, enzymes, enzymeSeqs, fs, c); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
 	// try to digest the backbone with the enzyme
-	fs.backbone, fs.backboneMeta, err = p.parseBackbone(backbone, enzymes, fs.dbs, c)
+	fs.backbone, fs.backboneMeta, err = p.parseBackbone(backbone, enzymes, enzymeSeqs, fs.dbs, c)
 	if strict && err != nil {
 		stderr.Fatal(err)
 	}
@@ -185,6 +578,28 @@ func parseCmdFlags(cmd *cobra.Command, args []string, strict bool) (*Flags, *con
 	return fs, c
 }
 
+// inInventory returns whether the db is one of the user's inventory dbs.
+func (f *Flags) inInventory(db string) bool {
+	for _, inventoryDB := range f.inventory {
+		if inventoryDB == db {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inHand returns whether the db is made from one of the user's in-hand fragments.
+func (f *Flags) inHand(db string) bool {
+	for _, haveDB := range f.have {
+		if haveDB == db {
+			return true
+		}
+	}
+
+	return false
+}
+
 // guessInput returns the first fasta file in the current directory. Is used
 // if the user hasn't specified an input file.
 func (p *inputParser) guessInput() (in string, err error) {
@@ -236,24 +651,19 @@ func (p *inputParser) parseFeatureInput(args []string) (out string) {
 // guessOutput gets an outpath path from an input path (if no output path is
 // specified). It uses the same name as the input path to create an output.
 func (p *inputParser) guessOutput(in string) (out string) {
+	in = strings.TrimRight(in, string(filepath.Separator)) // a directory of fragments
 	ext := filepath.Ext(in)
 	noExt := in[0 : len(in)-len(ext)]
 	return noExt + ".output.json"
 }
 
 // parseDBs returns a list of absolute paths to BLAST databases.
-func (p *inputParser) parseDBs(dbs string, addgene, igem, dnasu bool) (paths []string, err error) {
-	if addgene {
-		dbs += "," + config.AddgeneDB
-	}
-	if igem {
-		dbs += "," + config.IGEMDB
-	}
-	if dnasu {
-		dbs += "," + config.DNASUDB
+func (p *inputParser) parseDBs(dbs string, addgene, igem, dnasu bool, c *config.Config) (paths []string, err error) {
+	for _, repository := range repositoryDBs(addgene, igem, dnasu, c) {
+		dbs += "," + repository
 	}
 
-	if paths, err = p.dbPaths(dbs); err != nil {
+	if paths, err = p.dbPaths(dbs, c); err != nil {
 		return nil, err
 	}
 
@@ -267,12 +677,54 @@ func (p *inputParser) parseDBs(dbs string, addgene, igem, dnasu bool) (paths []s
 	return paths, nil
 }
 
+// parseDBIdentity parses a comma separated list of "db=identity" thresholds, like
+// "genome=99,addgene=95", to a map from the paths of the dbs to their identities. Each
+// db is matched to those being BLAST'ed by its path, file name, or file name without
+// its extension. It's an error if one doesn't match any db being BLAST'ed.
+func (p *inputParser) parseDBIdentity(identityList string, dbs []string) (map[string]int, error) {
+	dbIdentity := make(map[string]int)
+	for _, entry := range p.parseCommaList(identityList) {
+		cols := strings.Split(entry, "=")
+		if len(cols) != 2 {
+			return nil, fmt.Errorf(`failed to parse identity threshold %s, expected "db=identity"`, entry)
+		}
+
+		name := strings.TrimSpace(cols[0])
+		identity, err := strconv.Atoi(strings.TrimSpace(cols[1]))
+		if err != nil || identity < 1 || identity > 100 {
+			return nil, fmt.Errorf("failed to parse identity threshold %s, expected a %%-identity from 1 to 100", entry)
+		}
+
+		absName, _ := filepath.Abs(name)
+		matched := false
+		for _, db := range dbs {
+			base := filepath.Base(db)
+			if db == absName || base == name || strings.TrimSuffix(base, filepath.Ext(base)) == name {
+				dbIdentity[db] = identity
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("failed to find a database %s for its identity threshold, the databases are: %s", name, strings.Join(dbs, ", "))
+		}
+	}
+
+	return dbIdentity, nil
+}
+
 // dbPaths turns a single string of comma separated BLAST dbs into a
-// slice of absolute paths to the BLAST dbs on the local fs.
-func (p *inputParser) dbPaths(dbList string) (paths []string, err error) {
+// slice of absolute paths to the BLAST dbs on the local fs. Aliases of
+// dbs in the settings are replaced with their paths.
+func (p *inputParser) dbPaths(dbList string, c *config.Config) (paths []string, err error) {
 	dbPaths := p.parseCommaList(dbList)
 
 	for _, db := range dbPaths {
+		if c != nil {
+			if path, set := c.DBAlias(db); set {
+				db = path
+			}
+		}
+
 		absPath, err := filepath.Abs(db)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create absolute path: %v", err)
@@ -283,6 +735,30 @@ func (p *inputParser) dbPaths(dbList string) (paths []string, err error) {
 	return
 }
 
+// repositoryDBs returns the paths to the databases of the repositories that are used. They're
+// resolved like the aliases of --dbs, so an alias in the settings, ex: addgene: ~/dbs/addgene_2023,
+// replaces the repository's database.
+func repositoryDBs(addgene, igem, dnasu bool, c *config.Config) (paths []string) {
+	repositories := []struct {
+		alias string
+		used  bool
+	}{
+		{"addgene", addgene},
+		{"igem", igem},
+		{"dnasu", dnasu},
+	}
+
+	for _, r := range repositories {
+		if !r.used {
+			continue
+		}
+		path, _ := c.DBAlias(r.alias)
+		paths = append(paths, path)
+	}
+
+	return
+}
+
 // parseCommaList converts a comma separated list of strings into a list
 // of strings for use elsewhere
 func (p *inputParser) parseCommaList(commaList string) (newList []string) {
@@ -298,11 +774,102 @@ func (p *inputParser) parseCommaList(commaList string) (newList []string) {
 	return newList
 }
 
-// parseBackbone takes a backbone, referenced by its id, and an enzyme to cleave the
-// backbone, and returns the linearized backbone as a Frag.
+// parsePrimerMods parses a comma separated list of primers' 5' modifications. Each is either
+// a modification for every primer, like "/5Phos/", or one for a fragment's primers or a
+// single primer of it, like "pSB1A3=/5Phos/" or "pSB1A3:REV=/5SpC3/".
+func (p *inputParser) parsePrimerMods(mods string) (map[string]string, error) {
+	idtMod := regexp.MustCompile(`^(/[^/\s]+/)+$`)
+
+	parsed := make(map[string]string)
+	for _, item := range p.parseCommaList(mods) {
+		key, mod := "", item
+		if i := strings.LastIndex(item, "="); i >= 0 {
+			key, mod = strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		}
+
+		if !idtMod.MatchString(mod) {
+			return nil, fmt.Errorf("invalid 5' modification %s, expected IDT syntax like /5Phos/", mod)
+		}
+
+		// the primer's direction, if there is one, is matched in upper case
+		if i := strings.LastIndex(key, ":"); i >= 0 {
+			key = key[:i] + ":" + strings.ToUpper(key[i+1:])
+		}
+		parsed[key] = mod
+	}
+
+	return parsed, nil
+}
+
+// parseAvoidSites returns the recognition sequences, by name, of the enzymes whose sites
+// are removed from synthetic fragments. Enzymes are either named in the enzyme db
+// or by their recognition sequence.
+func (p *inputParser) parseAvoidSites(enzymeList string) (map[string]string, error) {
+	enzymeDB := NewEnzymeDB()
+
+	sites := make(map[string]string)
+	for _, name := range p.parseCommaList(enzymeList) {
+		if canonical, recogSeq, exists := enzymeDB.named(name); exists {
+			sites[canonical] = recogSeq
+		} else if recogSeq, err := validRecogSeq(name); err == nil {
+			sites[name] = recogSeq
+		} else if _, err := p.getEnzymes([]string{name}); err != nil {
+			return nil, err // not a known enzyme, with suggestions
+		}
+	}
+
+	return sites, nil
+}
+
+// parseFlankSites returns the enzymes whose sites flank the insert, on its left and right.
+// One enzyme is used on both ends. Enzymes are either named in the enzyme db or by their
+// recognition sequence.
+func (p *inputParser) parseFlankSites(enzymeList string) (enzymes []enzyme, err error) {
+	names := p.parseCommaList(enzymeList)
+	if len(names) < 1 || len(names) > 2 {
+		return nil, fmt.Errorf("failed to parse --flank-sites %s, expected one or two enzymes", enzymeList)
+	}
+
+	enzymeDB := NewEnzymeDB()
+	for _, name := range names {
+		if canonical, recogSeq, exists := enzymeDB.named(name); exists {
+			enzymes = append(enzymes, newEnzyme(canonical, recogSeq))
+		} else if recogSeq, err := validRecogSeq(name); err == nil {
+			enzymes = append(enzymes, newEnzyme(name, recogSeq))
+		} else if _, err := p.getEnzymes([]string{name}); err != nil {
+			return nil, err // not a known enzyme, with suggestions
+		}
+	}
+	if len(enzymes) == 1 {
+		enzymes = append(enzymes, enzymes[0])
+	}
+
+	return enzymes, nil
+}
+
+// parseSeqPrimerSites returns the names of the sequencing primers whose sites are added to
+// the left, and right, ends of the insert. Each is a universal primer or one in the settings.
+func (p *inputParser) parseSeqPrimerSites(primerList string, c *config.Config) (names []string, err error) {
+	names = p.parseCommaList(primerList)
+	if len(names) < 1 || len(names) > 2 {
+		return nil, fmt.Errorf("failed to parse --seq-primer-sites %s, expected one or two sequencing primers", primerList)
+	}
+
+	for _, name := range names {
+		if _, err := seqPrimer(name, c); err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+// parseBackbone takes a backbone, referenced by its id, and enzymes to cleave the
+// backbone, and returns the linearized backbone as a Frag. Enzymes are either
+// referenced by name in the enzyme db or by their recognition sequence.
 func (p *inputParser) parseBackbone(
 	bbName string,
-	enzymeNames, dbs []string,
+	enzymeNames, enzymeSeqs, dbs []string,
 	c *config.Config,
 ) (f *Frag, backbone *Backbone, err error) {
 	// if no backbone was specified, return an empty Frag
@@ -317,7 +884,7 @@ func (p *inputParser) parseBackbone(
 	}
 
 	// try to digest the backbone with the enzyme
-	if len(enzymeNames) == 0 {
+	if len(enzymeNames) == 0 && len(enzymeSeqs) == 0 {
 		return &Frag{},
 			&Backbone{},
 			fmt.Errorf("backbone passed, %s, without an enzyme to digest it", bbName)
@@ -329,23 +896,118 @@ func (p *inputParser) parseBackbone(
 		return &Frag{}, &Backbone{}, err
 	}
 
-	if f, backbone, err = digest(bbFrag, enzymes); err != nil {
+	// make enzymes from any recognition sequences passed directly
+	for _, enzymeSeq := range enzymeSeqs {
+		recogSeq, err := validRecogSeq(enzymeSeq)
+		if err != nil {
+			return &Frag{}, &Backbone{}, err
+		}
+		enzymes = append(enzymes, newEnzyme(recogSeq, recogSeq))
+	}
+
+	if f, backbone, err = digest(bbFrag, enzymes, c.BackboneKeep5Overhangs); err != nil {
 		return &Frag{}, &Backbone{}, err
 	}
 
 	return
 }
 
+// parseAutoEnzyme picks the enzyme to linearize the backbone from the candidates, by
+// name or recognition sequence, or from every enzyme in the enzyme db if there are none.
+// The chosen enzyme is returned as the only name or recognition sequence to digest with.
+func (p *inputParser) parseAutoEnzyme(
+	bbName string,
+	enzymeNames, enzymeSeqs []string,
+	fs *Flags,
+	c *config.Config,
+) (names, seqs []string, err error) {
+	bbFrag, err := queryDatabases(bbName, fs.dbs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates, err := p.getEnzymes(enzymeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	bySeq := make(map[string]bool) // the candidates passed by recognition sequence
+	for _, enzymeSeq := range enzymeSeqs {
+		recogSeq, err := validRecogSeq(enzymeSeq)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, newEnzyme(recogSeq, recogSeq))
+		bySeq[recogSeq] = true
+	}
+
+	if len(candidates) == 0 {
+		enzymeDB := NewEnzymeDB()
+		for name := range enzymeDB.enzymes {
+			enzymeNames = append(enzymeNames, name)
+		}
+		sort.Strings(enzymeNames)
+		if candidates, err = p.getEnzymes(enzymeNames); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	chosen, rejected, err := autoEnzyme(bbFrag, candidates, p.autoEnzymeInserts(fs), c.BackboneKeep5Overhangs)
+	logAutoEnzyme(bbName, chosen, rejected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if bySeq[chosen.name] {
+		return nil, []string{chosen.name}, nil
+	}
+	return []string{chosen.name}, nil, nil
+}
+
+// autoEnzymeInserts returns the inserts that an enzyme picked with --auto-enzyme can't
+// cut: the targets of a sequence build or the fragments of a fragments build. The input
+// of a features build isn't a file, so it has none.
+func (p *inputParser) autoEnzymeInserts(fs *Flags) (inserts []*Frag) {
+	inputs := fs.batch
+	if len(inputs) == 0 {
+		inputs = []string{fs.in}
+	}
+
+	for _, in := range inputs {
+		info, err := os.Stat(in)
+		if err != nil {
+			continue
+		}
+
+		var frags []*Frag
+		if info.IsDir() {
+			frags, err = readDir(in, fs.stripInvalid)
+		} else {
+			frags, err = read(in, false, fs.stripInvalid)
+		}
+		if err == nil {
+			inserts = append(inserts, frags...)
+		}
+	}
+
+	return
+}
+
 // getEnzymes return the enzyme with the name passed. errors out if there is none.
 func (p *inputParser) getEnzymes(enzymeNames []string) (enzymes []enzyme, err error) {
 	enzymeDB := NewEnzymeDB()
 	for _, enzymeName := range enzymeNames {
-		if cutseq, exists := enzymeDB.enzymes[enzymeName]; exists {
-			enzymes = append(enzymes, newEnzyme(enzymeName, cutseq))
+		if canonical, cutseq, exists := enzymeDB.named(enzymeName); exists {
+			enzymes = append(enzymes, newEnzyme(canonical, cutseq))
 		} else {
+			hint := ""
+			if suggestion := suggestNames(enzymeName, enzymeDB.enzymes); suggestion != "" {
+				hint = "\n" + suggestion
+			}
+
 			return enzymes, fmt.Errorf(
-				`failed to find enzyme with name %s use "repp enzymes" for a list of recognized enzymes`,
+				`failed to find enzyme with name %s use "repp enzymes" for a list of recognized enzymes%s`,
 				enzymeName,
+				hint,
 			)
 		}
 	}
@@ -363,8 +1025,31 @@ func (p *inputParser) getFilters(filterFlag string) []string {
 	return strings.FieldsFunc(strings.ToUpper(filterFlag), splitFunc)
 }
 
+// openOrCreate opens the file at the path for reading, creating it (and its
+// directory) if it doesn't exist yet.
+func openOrCreate(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+}
+
 // read a FASTA file (by its path on local FS) to a slice of Fragments.
-func read(path string, feature bool) (fragments []*Frag, err error) {
+// Unless stripInvalid, FASTA and Genbank files with characters other than A, T, G and C
+// in their sequences are rejected. With stripInvalid they're removed with a warning.
+func read(path string, feature, stripInvalid bool) (fragments []*Frag, err error) {
+	return readFile(path, feature, stripInvalid, true)
+}
+
+// readTemplate reads a fragment's template, from a database or a local file, to a slice
+// of Fragments. Invalid characters are removed without a warning: they're in the sources
+// of fragments rather than the user's input.
+func readTemplate(path string) (fragments []*Frag, err error) {
+	return readFile(path, false, true, false)
+}
+
+// readFile reads a FASTA or Genbank file to fragments, validating its sequences if validate.
+func readFile(path string, feature, stripInvalid, validate bool) (fragments []*Frag, err error) {
 	if !filepath.IsAbs(path) {
 		path, err = filepath.Abs(path)
 		if err != nil {
@@ -377,21 +1062,197 @@ func read(path string, feature bool) (fragments []*Frag, err error) {
 		return nil, err
 	}
 	file := string(dat)
+	if strings.TrimSpace(file) == "" {
+		return nil, fmt.Errorf("no sequences found in %s", path)
+	}
 
+	filename := path
 	path = strings.ToLower(path)
 	if strings.HasSuffix(path, "fa") ||
 		strings.HasSuffix(path, "fasta") ||
 		file[0] == '>' {
-		return readFasta(path, file)
-	}
-
-	if strings.HasSuffix(path, "gb") ||
+		if validate {
+			if err = checkValid(validateFasta(path, file), stripInvalid); err != nil {
+				return nil, err
+			}
+		}
+		fragments, err = readFasta(path, file)
+	} else if strings.HasSuffix(path, "gb") ||
 		strings.HasSuffix(path, "gbk") ||
 		strings.HasSuffix(path, "genbank") {
-		return readGenbank(path, file, feature)
+		if validate {
+			if err = checkValid(validateGenbank(path, file), stripInvalid); err != nil {
+				return nil, err
+			}
+		}
+		fragments, err = readGenbank(path, file, feature)
+	} else {
+		return nil, fmt.Errorf("failed to parse %s: unrecognized file type", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// headers without sequences, eg a FASTA file whose sequence was never pasted in
+	for _, f := range fragments {
+		if f.Seq != "" {
+			return fragments, nil
+		}
+	}
+	return nil, fmt.Errorf("no sequences found in %s", filename)
+}
+
+// fragmentsManifest is the file in a directory of fragments that lists their order
+const fragmentsManifest = "manifest.txt"
+
+// readDir reads the sequence files of a directory to fragments, in order. The files are
+// read in the order listed in its manifest.txt, one file name per line, or in lexical
+// order if it has none. Each file may have multiple records, which are kept in order.
+func readDir(dir string, stripInvalid bool) (fragments []*Frag, err error) {
+	var files []string
+	if manifest, err := ioutil.ReadFile(filepath.Join(dir, fragmentsManifest)); err == nil {
+		for _, line := range strings.Split(string(manifest), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			files = append(files, line)
+		}
+	} else {
+		entries, err := ioutil.ReadDir(dir) // sorted by name
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fragments from %s: %v", dir, err)
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.HasPrefix(name, ".") || !isSequenceFile(name) {
+				continue
+			}
+			files = append(files, name)
+		}
+	}
+
+	for _, file := range files {
+		frags, err := read(filepath.Join(dir, file), false, stripInvalid)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, frags...)
+	}
+
+	if len(fragments) < 1 {
+		return nil, fmt.Errorf("no sequences found in %s", dir)
+	}
+
+	return fragments, nil
+}
+
+// isSequenceFile returns whether the file has the extension of a FASTA or Genbank file.
+func isSequenceFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".fa", ".fasta", ".gb", ".gbk", ".genbank":
+		return true
+	}
+	return false
+}
+
+// validBases are the characters allowed in a sequence. Degenerate IUPAC codes are left
+// out: they can't be BLAST'ed or primed against, and removing them shifts every later base.
+const validBases = "ACGT"
+
+// invalidCharError is an invalid character in a sequence and where it was found.
+type invalidCharError struct {
+	char         rune
+	path, record string
+	line, column int
+}
+
+func (e *invalidCharError) Error() string {
+	return fmt.Sprintf(
+		"invalid character %q in %s: record %s, line %d, column %d. Only A, T, G and C are supported. Use --strip-invalid to remove invalid characters",
+		e.char, e.path, e.record, e.line, e.column,
+	)
+}
+
+// checkValid returns the validation error unless stripInvalid, in which case it's
+// logged as a warning since the invalid characters are removed.
+func checkValid(err error, stripInvalid bool) error {
+	invalid, ok := err.(*invalidCharError)
+	if !ok || !stripInvalid {
+		return err
+	}
+
+	stderr.Printf(
+		"warning: removing invalid characters from %s, the first is %q in record %s, line %d, column %d. Later bases are shifted\n",
+		invalid.path, invalid.char, invalid.record, invalid.line, invalid.column,
+	)
+	return nil
+}
+
+// validateFasta checks the sequences of a FASTA file for invalid characters. The error
+// has the record, line and column of the first. Whitespace at the end of lines is ignored.
+func validateFasta(path, contents string) error {
+	record := ""
+	for i, line := range strings.Split(contents, "\n") {
+		if strings.HasPrefix(line, ">") {
+			record = strings.TrimSpace(line[1:])
+			continue
+		}
+
+		if record == "" {
+			continue // not in a record yet
+		}
+
+		for j, c := range strings.TrimRight(line, " \t\r") {
+			if !strings.ContainsRune(validBases, unicode.ToUpper(c)) {
+				return &invalidCharError{c, path, record, i + 1, j + 1}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGenbank checks the ORIGIN sequence of a Genbank file for invalid characters.
+// The position numbers at the start of its lines and the spaces between blocks are skipped.
+func validateGenbank(path, contents string) error {
+	record := ""
+	inOrigin := false
+	for i, line := range strings.Split(contents, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "LOCUS" {
+			record = fields[1]
+		}
+
+		if strings.HasPrefix(line, "ORIGIN") {
+			inOrigin = true
+			continue
+		}
+
+		if !inOrigin {
+			continue
+		}
+
+		if strings.HasPrefix(line, "//") {
+			inOrigin = false
+			continue
+		}
+
+		// skip the position number at the start of the line
+		seq := strings.TrimLeft(line, " \t")
+		seq = strings.TrimLeftFunc(seq, unicode.IsDigit)
+		offset := len(line) - len(seq)
+		for j, c := range seq {
+			if unicode.IsSpace(c) {
+				continue
+			}
+			if !strings.ContainsRune(validBases, unicode.ToUpper(c)) {
+				return &invalidCharError{c, path, record, i + 1, offset + j + 1}
+			}
+		}
 	}
 
-	return nil, fmt.Errorf("failed to parse %s: unrecognized file type", path)
+	return nil
 }
 
 // readFasta parses the multifasta file to fragments.
@@ -419,7 +1280,7 @@ func readFasta(path, contents string) (frags []*Frag, err error) {
 	var unwantedChars = regexp.MustCompile(`(?im)[^atgc]|\W`)
 
 	// accumulate the sequences from between the headers
-	var seqs []string
+	var seqs, caseSeqs []string
 	for i, headerIndex := range headerIndices {
 		nextLine := len(lines)
 		if i < len(headerIndices)-1 {
@@ -428,8 +1289,8 @@ func readFasta(path, contents string) (frags []*Frag, err error) {
 		seqLines := lines[headerIndex+1 : nextLine]
 		seqJoined := strings.Join(seqLines, "")
 		seq := unwantedChars.ReplaceAllString(seqJoined, "")
-		seq = strings.ToUpper(seq)
-		seqs = append(seqs, seq)
+		caseSeqs = append(caseSeqs, seq)
+		seqs = append(seqs, strings.ToUpper(seq))
 	}
 
 	// build and return the new frags
@@ -437,6 +1298,7 @@ func readFasta(path, contents string) (frags []*Frag, err error) {
 		frags = append(frags, &Frag{
 			ID:       id,
 			Seq:      seqs[i],
+			caseSeq:  caseSeqs[i],
 			fragType: fragTypes[i],
 		})
 	}
@@ -458,9 +1320,9 @@ func readGenbank(path, contents string, parseFeatures bool) (fragments []*Frag,
 		return nil, fmt.Errorf("failed to parse %s: improperly formatted genbank file", path)
 	}
 
-	seq := strings.ToUpper(genbankSplit[1])
-	nonBpRegex := regexp.MustCompile("[^ATGC]")
-	cleanedSeq := nonBpRegex.ReplaceAllString(seq, "")
+	nonBpRegex := regexp.MustCompile("[^ATGCatgc]")
+	caseSeq := nonBpRegex.ReplaceAllString(genbankSplit[1], "")
+	cleanedSeq := strings.ToUpper(caseSeq)
 
 	if parseFeatures {
 		// parse each feature to a fragment (misnomer)
@@ -470,7 +1332,8 @@ func readGenbank(path, contents string, parseFeatures bool) (fragments []*Frag,
 			return nil, fmt.Errorf("failed to parse features from %s", path)
 		}
 
-		featureSplitRegex := regexp.MustCompile("\\w+\\s+\\w+")
+		// split on the keys padded at the start of each feature, ex: "     rep_origin      "
+		featureSplitRegex := regexp.MustCompile("\\n {5}\\S+\\s+")
 		featureStrings := featureSplitRegex.Split(splitOnFeatures[1], -1)
 
 		features := []*Frag{}
@@ -522,8 +1385,9 @@ func readGenbank(path, contents string, parseFeatures bool) (fragments []*Frag,
 
 	return []*Frag{
 		&Frag{
-			ID:  id,
-			Seq: cleanedSeq,
+			ID:      id,
+			Seq:     cleanedSeq,
+			caseSeq: caseSeq,
 		},
 	}, nil
 }
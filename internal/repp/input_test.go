@@ -2,10 +2,15 @@ package repp
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/jjtimmons/repp/config"
 )
 
 func Test_inputParser_dbPaths(t *testing.T) {
@@ -53,7 +58,7 @@ func Test_inputParser_dbPaths(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if gotPaths, _ := parser.dbPaths(tt.args.dbList); !reflect.DeepEqual(gotPaths, tt.wantPaths) {
+			if gotPaths, _ := parser.dbPaths(tt.args.dbList, nil); !reflect.DeepEqual(gotPaths, tt.wantPaths) {
 				t.Errorf("parseDBs() = %v, want %v", gotPaths, tt.wantPaths)
 			}
 		})
@@ -78,6 +83,13 @@ func Test_inputParser_parseOut(t *testing.T) {
 			},
 			"./test_file.output.json",
 		},
+		{
+			"directory with a trailing separator",
+			args{
+				in: "./parts/",
+			},
+			"./parts.output.json",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -125,6 +137,171 @@ func Test_inputParser_getFilters(t *testing.T) {
 	}
 }
 
+func Test_inputParser_parsePrimerMods(t *testing.T) {
+	tests := []struct {
+		name    string
+		mods    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			"every primer",
+			"/5Phos/",
+			map[string]string{"": "/5Phos/"},
+			false,
+		},
+		{
+			"fragment and primer modifications",
+			"pSB1A3=/5Phos/, BBa_E0040:rev=/5SpC3/",
+			map[string]string{"pSB1A3": "/5Phos/", "BBa_E0040:REV": "/5SpC3/"},
+			false,
+		},
+		{
+			"not IDT syntax",
+			"pSB1A3=5Phos",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &inputParser{}
+			got, err := p.parsePrimerMods(tt.mods)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("inputParser.parsePrimerMods() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inputParser.parsePrimerMods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inputParser_parseDBIdentity(t *testing.T) {
+	dbs := []string{"/repp/genome.fa", "/repp/addgene", "/repp/igem"}
+
+	tests := []struct {
+		name     string
+		identity string
+		want     map[string]int
+		wantErr  bool
+	}{
+		{
+			"unknown database",
+			"genome=100, dnasu=95",
+			nil,
+			true,
+		},
+		{
+			"by path and file name",
+			"/repp/genome.fa=100,addgene=95",
+			map[string]int{"/repp/genome.fa": 100, "/repp/addgene": 95},
+			false,
+		},
+		{
+			"by file name without an extension",
+			"genome=99",
+			map[string]int{"/repp/genome.fa": 99},
+			false,
+		},
+		{
+			"identity over 100",
+			"genome=101",
+			nil,
+			true,
+		},
+		{
+			"no identity",
+			"genome",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &inputParser{}
+			got, err := p.parseDBIdentity(tt.identity, dbs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("inputParser.parseDBIdentity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inputParser.parseDBIdentity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateFasta(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{
+			"valid with trailing whitespace",
+			">frag1\nATGC \r\natgc\n>frag2\nATGC\n",
+			"",
+		},
+		{
+			"invalid character",
+			">frag1\nATGC\n>frag2\nATGC\nAT*GC\n",
+			`invalid character '*' in test.fa: record frag2, line 5, column 3. Only A, T, G and C are supported. Use --strip-invalid to remove invalid characters`,
+		},
+		{
+			"space in sequence",
+			">frag1\nATG CATG\n",
+			`invalid character ' ' in test.fa: record frag1, line 2, column 4. Only A, T, G and C are supported. Use --strip-invalid to remove invalid characters`,
+		},
+		{
+			"degenerate base",
+			">frag1\nATGCNRY\n",
+			`invalid character 'N' in test.fa: record frag1, line 2, column 5. Only A, T, G and C are supported. Use --strip-invalid to remove invalid characters`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFasta("test.fa", tt.contents)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("validateFasta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateGenbank(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{
+			"valid",
+			"LOCUS       frag1  20 bp\nFEATURES\nORIGIN\n        1 atgcatgcat gcatgcatgc\n//\n",
+			"",
+		},
+		{
+			"degenerate base",
+			"LOCUS       frag1  20 bp\nFEATURES\nORIGIN\n        1 atgcatgcat gcatgnatgc\n//\n",
+			`invalid character 'n' in test.gb: record frag1, line 4, column 27. Only A, T, G and C are supported. Use --strip-invalid to remove invalid characters`,
+		},
+		{
+			"only checks the sequence",
+			"LOCUS       frag1  10 bp\nFEATURES\n     misc_feature    1..4\nORIGIN\n        1 atgcatgcat\n//\nXYZ\n",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGenbank("test.gb", tt.contents)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("validateGenbank() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // Test reading of a FASTA file
 func Test_read(t *testing.T) {
 	type fileRead struct {
@@ -162,7 +339,7 @@ func Test_read(t *testing.T) {
 	}
 
 	for _, f := range files {
-		fragments, err := read(f.file, f.readFeatures)
+		fragments, err := read(f.file, f.readFeatures, false)
 
 		if err != nil {
 			t.Error(err)
@@ -185,3 +362,170 @@ func Test_read(t *testing.T) {
 		}
 	}
 }
+
+func Test_readDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fragments-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"b_insert.fa":   ">insert\nCCCCGGGG\n",
+		"a_backbone.fa": ">backbone\nAAAATTTT\n",
+		"c_multi.fasta": ">gfp\nATGCATGC\n>terminator\nGCGCGCGC\n",
+		"notes.txt":     "not a fragment",
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(frags []*Frag) (ids []string) {
+		for _, f := range frags {
+			ids = append(ids, f.ID)
+		}
+		return
+	}
+
+	// lexical order without a manifest
+	frags, err := readDir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"backbone", "insert", "gfp", "terminator"}; !reflect.DeepEqual(ids(frags), want) {
+		t.Errorf("readDir() = %v, want %v", ids(frags), want)
+	}
+
+	// the manifest's order
+	manifest := "# order of the assembly\nc_multi.fasta\n\na_backbone.fa\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, fragmentsManifest), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if frags, err = readDir(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"gfp", "terminator", "backbone"}; !reflect.DeepEqual(ids(frags), want) {
+		t.Errorf("readDir() = %v, want %v", ids(frags), want)
+	}
+
+	// a file in the manifest that doesn't exist
+	if err := ioutil.WriteFile(filepath.Join(dir, fragmentsManifest), []byte("missing.fa\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readDir(dir, false); err == nil {
+		t.Error("readDir() error = nil, want an error for the missing file")
+	}
+}
+
+func Test_read_empty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "empty-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		filename string
+		contents string
+	}{
+		{
+			"empty file",
+			"empty.fa",
+			"",
+		},
+		{
+			"header-only record",
+			"header.fa",
+			">seq1\n",
+		},
+		{
+			"only whitespace",
+			"whitespace.fa",
+			"  \n\t\n\n",
+		},
+		{
+			"only whitespace without an extension",
+			"whitespace",
+			"\n \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.filename)
+			if err := ioutil.WriteFile(filename, []byte(tt.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			fragments, err := read(filename, false, false)
+			if err == nil || !strings.Contains(err.Error(), "no sequences found in "+filename) {
+				t.Errorf("read() error = %v, want no sequences found in %s", err, filename)
+			}
+			if fragments != nil {
+				t.Errorf("read() = %v, want nil", fragments)
+			}
+		})
+	}
+}
+
+func Test_readGenbank_features(t *testing.T) {
+	contents := `LOCUS       test        20 bp    DNA     circular
+FEATURES             Location/Qualifiers
+     rep_origin      3..10
+                     /label="pUC ori"
+                     /note="a high copy number origin"
+     misc_feature    complement(12..17)
+                     /label=BseYI
+ORIGIN
+        1 atgcatgcat gcatgcatgc
+//
+`
+
+	features, err := readGenbank("test.gb", contents, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Frag{
+		{ID: `"pUC ori"`, Seq: "GCATGCAT"},
+		{ID: "BseYI", Seq: "CATGCA"},
+	}
+	if !reflect.DeepEqual(features, want) {
+		for _, f := range features {
+			t.Logf("feature %s: %s", f.ID, f.Seq)
+		}
+		t.Errorf("readGenbank() = %d features, want %s: %s and %s: %s", len(features), want[0].ID, want[0].Seq, want[1].ID, want[1].Seq)
+	}
+}
+
+func Test_read_case(t *testing.T) {
+	dir, err := ioutil.TempDir("", "case-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "exons.fa")
+	if err := ioutil.WriteFile(filename, []byte(">exons\nATGCatgcat\nGCAT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fragments, err := read(filename, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := fragments[0]; f.Seq != "ATGCATGCATGCAT" || f.readSeq() != "ATGCatgcatGCAT" {
+		t.Errorf("read() = %s and %s, want ATGCATGCATGCAT and ATGCatgcatGCAT", f.Seq, f.readSeq())
+	}
+}
+
+func Test_repositoryDBs(t *testing.T) {
+	c := &config.Config{DBAliases: map[string]string{"igem": "/dbs/igem_2019"}}
+
+	got := repositoryDBs(true, true, false, c)
+	if want := []string{config.AddgeneDB, "/dbs/igem_2019"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("repositoryDBs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+// migrations upgrade an output from the schema version at their index to the next.
+// Each fills the fields that were added in the next version from those in the output.
+var migrations = []func(out *Output, conf *config.Config) []string{
+	migrateUnversioned,
+	migrateV1,
+	migrateV2,
+}
+
+// MigrateCmd upgrades output JSON files to the current schema. The upgraded output is
+// logged, or written over the files with --in-place.
+func MigrateCmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass an output JSON file to migrate.")
+	}
+
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	if len(args) > 1 && !inPlace {
+		stderr.Fatalln("must pass --in-place to migrate multiple output JSON files.")
+	}
+
+	conf := config.New()
+	for _, filename := range args {
+		out, err := readOutput(filename)
+		if err != nil {
+			stderr.Fatalln(err)
+		}
+
+		from := out.SchemaVersion
+		filled, err := migrate(&out, conf)
+		if err != nil {
+			stderr.Fatalf("failed to migrate %s: %v", filename, err)
+		}
+		if len(filled) > 0 {
+			stderr.Printf("%s: migrated from schema version %d to %d, filled %s\n", filename, from, out.SchemaVersion, strings.Join(filled, ", "))
+		}
+
+		output, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			stderr.Fatalf("failed to serialize %s: %v", filename, err)
+		}
+
+		if !inPlace {
+			fmt.Println(string(output))
+		} else if err = ioutil.WriteFile(filename, output, 0666); err != nil {
+			stderr.Fatalf("failed to write %s: %v", filename, err)
+		}
+	}
+}
+
+// migrate upgrades the output to the current schema version and returns the fields
+// that were filled. Fields that were removed from the schema are dropped when it's read.
+func migrate(out *Output, conf *config.Config) (filled []string, err error) {
+	if out.SchemaVersion > schemaVersion {
+		return nil, fmt.Errorf("schema version %d is newer than this build of repp's, %d", out.SchemaVersion, schemaVersion)
+	}
+
+	for version := out.SchemaVersion; version < schemaVersion; version++ {
+		filled = append(filled, migrations[version](out, conf)...)
+	}
+	out.SchemaVersion = schemaVersion
+
+	return filled, nil
+}
+
+// migrateUnversioned upgrades an output written before the schema was versioned. Fields
+// added since repp's first outputs are filled from the solutions' fragments and primers.
+// The build is left empty, it's unknown.
+func migrateUnversioned(out *Output, conf *config.Config) (filled []string) {
+	fill := func(field string) {
+		for _, f := range filled {
+			if f == field {
+				return
+			}
+		}
+		filled = append(filled, field)
+	}
+
+	if out.Stability == nil && out.TargetSeq != "" {
+		out.Stability = stability(out.TargetSeq)
+		fill("stability")
+	}
+
+	for i := range out.Solutions {
+		s := &out.Solutions[i]
+		if s.Count == 0 {
+			s.Count = len(s.Fragments)
+			fill("count")
+		}
+
+		for _, f := range s.Fragments {
+			if f.Type != pcr.String() || len(f.Primers) != 2 {
+				continue
+			}
+			if f.AnnealingTemp == 0 {
+				f.AnnealingTemp = math.Round(math.Min(f.Primers[0].Tm, f.Primers[1].Tm)*10) / 10
+				fill("annealingTemp")
+			}
+
+			amplicon := f.PCRSeq
+			if amplicon == "" {
+				amplicon = f.Seq
+			}
+			if f.AmpliconLength == 0 {
+				f.AmpliconLength = len(amplicon)
+				fill("ampliconLength")
+			}
+			if f.ExtensionTime == 0 {
+				f.ExtensionTime = int(math.Ceil(float64(len(amplicon)) / 1000 * conf.PCRExtensionRate))
+				fill("extensionTime")
+			}
+			for j, p := range f.Primers {
+				if p.Order == "" {
+					f.Primers[j].Order = conf.PCRPrimerModification + p.Seq
+					fill("order")
+				}
+			}
+		}
+
+		if s.Penalty == 0 {
+			if s.Penalty = primersPenalty(s.Fragments); s.Penalty != 0 {
+				fill("penalty")
+			}
+		}
+		if s.Sources == 0 {
+			if s.Sources = sourceCount(s.Fragments); s.Sources != 0 {
+				fill("sources")
+			}
+		}
+		if s.Rotation == 0 {
+			s.Rotation = seqRotation(s.Fragments, out.TargetSeq)
+			fill("rotation")
+		}
+		if s.Thermocycler == nil {
+			if s.Thermocycler = thermocycler(s.Fragments, conf.PCRAnnealingRange); s.Thermocycler != nil {
+				fill("thermocycler")
+			}
+		}
+	}
+
+	return
+}
+
+// migrateV1 upgrades an output of schema version 1, filling each solution's turnaround
+// from its fragments' types and sources.
+func migrateV1(out *Output, conf *config.Config) (filled []string) {
+	for i := range out.Solutions {
+		s := &out.Solutions[i]
+		if s.Turnaround != 0 {
+			continue
+		}
+
+		for _, f := range s.Fragments {
+			f.fragType = parseFragType(f.Type)
+		}
+		if s.Turnaround = turnaround(s.Fragments, conf); s.Turnaround != 0 && len(filled) == 0 {
+			filled = append(filled, "turnaround")
+		}
+	}
+
+	return
+}
+
+// migrateV2 upgrades an output of schema version 2, filling its provenance with the hash of
+// its target's sequence. The databases it was designed from, and their state, are unknown.
+func migrateV2(out *Output, conf *config.Config) (filled []string) {
+	if out.Provenance == nil && out.TargetSeq != "" {
+		out.Provenance = provenance(out.TargetSeq, nil)
+		filled = append(filled, "provenance")
+	}
+
+	return
+}
+
+// parseFragType returns the fragment type with the name, like "pcr". Linear if it's unknown.
+func parseFragType(name string) fragType {
+	for _, t := range []fragType{linear, circular, pcr, synthetic} {
+		if t.String() == name {
+			return t
+		}
+	}
+	return linear
+}
+
+// seqRotation returns the 1-based index of the circular target where the first
+// fragment's sequence, with the bp added by its primers, starts. 1 if it isn't found.
+func seqRotation(frags []*Frag, targetSeq string) int {
+	if len(frags) == 0 || targetSeq == "" {
+		return 1
+	}
+
+	seq := frags[0].PCRSeq
+	if seq == "" {
+		seq = frags[0].Seq
+	}
+
+	target := strings.ToUpper(targetSeq)
+	if index := strings.Index(target+target, strings.ToUpper(seq)); index >= 0 && seq != "" {
+		return index%len(target) + 1
+	}
+	return 1
+}
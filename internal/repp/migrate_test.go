@@ -0,0 +1,70 @@
+package repp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_migrate(t *testing.T) {
+	conf := &config.Config{PCRExtensionRate: 60, PCRAnnealingRange: 2, LeadTimeAddgene: 14, LeadTimePCR: 2, LeadTimeSynthesis: 10, LeadTimeAssembly: 1}
+	target := "AAAACCCCGGGGTTTTACGT"
+
+	out := &Output{
+		TargetSeq: target,
+		Solutions: []Solution{{
+			Fragments: []*Frag{
+				{
+					Type:   "pcr",
+					URL:    "https://www.addgene.org/1/",
+					Seq:    "CCCCGGGG",
+					PCRSeq: "aaCCCCGGGGTT",
+					Primers: []Primer{
+						{Seq: "AACCCC", Tm: 59.97, Penalty: 1.5},
+						{Seq: "AACCCC", Tm: 61.2, Penalty: 0.5},
+					},
+				},
+				{Type: "synthetic", Seq: "GGTTTTACGTAAAACC"},
+			},
+		}},
+	}
+
+	filled, err := migrate(out, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFilled := []string{"stability", "count", "annealingTemp", "ampliconLength", "extensionTime", "order", "penalty", "sources", "rotation", "thermocycler", "turnaround", "provenance"}
+	if !reflect.DeepEqual(filled, wantFilled) {
+		t.Errorf("migrate() filled = %v, want %v", filled, wantFilled)
+	}
+
+	s := out.Solutions[0]
+	f := s.Fragments[0]
+	if out.SchemaVersion != schemaVersion || s.Count != 2 || s.Sources != 1 || s.Penalty != 2 || s.Rotation != 3 {
+		t.Errorf("migrate() = schema %d, count %d, sources %d, penalty %f, rotation %d", out.SchemaVersion, s.Count, s.Sources, s.Penalty, s.Rotation)
+	}
+	if f.AnnealingTemp != 60 || f.AmpliconLength != 12 || f.ExtensionTime != 1 || f.Primers[0].Order != "AACCCC" {
+		t.Errorf("migrate() fragment = %+v", f)
+	}
+	if s.Turnaround != 17 {
+		t.Errorf("migrate() turnaround = %v, want 17", s.Turnaround)
+	}
+	if out.Provenance == nil || out.Provenance.TargetSHA256 != "e412865fbfaf3a37083db954532df766da19a7b4668c913cc02c2ddb0c9eac38" {
+		t.Errorf("migrate() provenance = %+v", out.Provenance)
+	}
+	if len(s.Thermocycler) != 1 || out.Stability == nil {
+		t.Errorf("migrate() thermocycler = %v, stability = %v", s.Thermocycler, out.Stability)
+	}
+
+	// current outputs are unchanged
+	if filled, err = migrate(out, conf); err != nil || len(filled) > 0 {
+		t.Errorf("migrate() of a current output filled %v, err %v", filled, err)
+	}
+
+	// newer outputs can't be migrated
+	if _, err = migrate(&Output{SchemaVersion: schemaVersion + 1}, conf); err == nil {
+		t.Error("migrate() of a newer output should fail")
+	}
+}
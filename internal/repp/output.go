@@ -1,12 +1,21 @@
 package repp
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jjtimmons/repp/config"
@@ -20,18 +29,62 @@ type Solution struct {
 	// Cost estimated from the primer and sequence lengths
 	Cost float64 `json:"cost"`
 
+	// Penalty is the summed primer3 penalty of the solution's primers
+	Penalty float64 `json:"penalty,omitempty"`
+
+	// Sources is the number of distinct source plasmids to procure from repositories
+	Sources int `json:"sources"`
+
+	// Turnaround is the estimated worst-case days to make the plasmid: the longest lead time
+	// of its fragments, to procure or synthesize and then PCR, and then the assembly
+	Turnaround float64 `json:"turnaround"`
+
+	// MaxSynthLength is the length of the solution's longest synthetic fragment, 0 if it has none
+	MaxSynthLength int `json:"maxSynthLength"`
+
+	// Rotation is the 1-based index of the target where the solution's first fragment starts.
+	// The target is circular so solutions may start anywhere, fragments are listed from here
+	Rotation int `json:"rotation"`
+
 	// Fragments used to build this solution
 	Fragments []*Frag `json:"fragments"`
+
+	// Thermocycler programs for the solution's PCRs
+	Thermocycler []PCRProgram `json:"thermocycler,omitempty"`
 }
 
+// PCRProgram is a suggested thermocycler program shared by one or more PCRs.
+type PCRProgram struct {
+	// AnnealingTemp is the annealing temperature (celcius)
+	AnnealingTemp float64 `json:"annealingTemp"`
+
+	// ExtensionTime is the extension time in seconds
+	ExtensionTime int `json:"extensionTime"`
+
+	// Fragments to amplify with this program
+	Fragments []string `json:"fragments"`
+}
+
+// schemaVersion is the version of the output's schema. It's bumped, with a migration
+// in migrations, when the output changes in a way that older outputs need to be upgraded.
+const schemaVersion = 3
+
 // Output is a struct containing design results for the assembly.
 type Output struct {
+	// SchemaVersion is the version of the output's schema, 0 if written before it was versioned
+	SchemaVersion int `json:"schemaVersion"`
+
 	// Target's name. In >example_CDS FASTA its "example_CDS"
 	Target string `json:"target"`
 
 	// Target's sequence
 	TargetSeq string `json:"seq"`
 
+	// TargetStrand is the strand of the target that the solutions are designed on, with
+	// --both-strands: 1 if the target, -1 if its reverse complement. The sequence and
+	// coordinates of the solutions are on that strand
+	TargetStrand int `json:"targetStrand,omitempty"`
+
 	// Time, ex: "2018-01-01 20:41:00"
 	Time string `json:"time"`
 
@@ -49,17 +102,114 @@ type Output struct {
 
 	// Backbone is the user linearized a backbone fragment
 	Backbone *Backbone `json:"backbone,omitempty"`
+
+	// SeqPrimerSites are the sites of sequencing primers added to the ends of the insert
+	SeqPrimerSites []SeqPrimerSite `json:"seqPrimerSites,omitempty"`
+
+	// FlankSites are the sites of the enzymes added to the ends of the insert
+	FlankSites []FlankSite `json:"flankSites,omitempty"`
+
+	// Stability is advisory metadata about the target's GC content and repeats
+	Stability *Stability `json:"stability,omitempty"`
+
+	// Build is the build of repp that wrote the output
+	Build BuildInfo `json:"build"`
+
+	// Provenance has the hashes of the target and the databases the design was made from
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance identifies the inputs of a design, so it can be traced back to them later.
+type Provenance struct {
+	// TargetSHA256 is the hex SHA-256 of the target's sequence, as it is in the output's seq
+	TargetSHA256 string `json:"targetSHA256"`
+
+	// DBs are the databases searched for fragments, with the hashes of their files
+	DBs []DBHash `json:"dbs,omitempty"`
+}
+
+// SeqPrimerSite is the binding site of a sequencing primer added to an end of the insert.
+type SeqPrimerSite struct {
+	// Name of the sequencing primer, ex: M13F
+	Name string `json:"name"`
+
+	// Seq of the sequencing primer
+	Seq string `json:"seq"`
+
+	// Start of the binding site on the plasmid (1-indexed)
+	Start int `json:"start"`
+
+	// Strand of the plasmid the primer's sequence is on. 1 if top, reading toward the end
+	// of the plasmid, -1 if bottom, reading toward its start
+	Strand int `json:"strand"`
+}
+
+// FlankSite is the site of an enzyme added to an end of the insert.
+type FlankSite struct {
+	// Enzyme is the name of the enzyme, ex: EcoRI
+	Enzyme string `json:"enzyme"`
+
+	// Start of the site on the plasmid (1-indexed)
+	Start int `json:"start"`
+
+	// End of the site on the plasmid (1-indexed), past its length if the site spans its zero-index
+	End int `json:"end"`
+}
+
+// DBHash is the hash of a database's files when a design was made.
+type DBHash struct {
+	// Path to the database
+	Path string `json:"path"`
+
+	// SHA256 is the hex SHA-256 of the database's files, in the order of their names
+	SHA256 string `json:"sha256"`
+}
+
+// BuildInfo identifies a build of repp.
+type BuildInfo struct {
+	// Version of repp, ex: "0.1.0"
+	Version string `json:"version"`
+
+	// Commit is the git commit repp was built from
+	Commit string `json:"commit,omitempty"`
+
+	// BuildDate is when repp was built, ex: "2020-01-01T20:41:00Z"
+	BuildDate string `json:"buildDate,omitempty"`
+}
+
+// Build returns the version, commit and build date of this build of repp.
+func Build() BuildInfo {
+	return BuildInfo{
+		Version:   config.Version,
+		Commit:    config.Commit,
+		BuildDate: config.BuildDate,
+	}
+}
+
+// String returns a description of the build, ex: "repp 0.1.0 (commit 1a2b3c4, built 2020-01-01T20:41:00Z)".
+func (b BuildInfo) String() string {
+	commit, date := b.Commit, b.BuildDate
+	if commit == "" {
+		commit = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return fmt.Sprintf("repp %s (commit %s, built %s)", b.Version, commit, date)
 }
 
 // writeJSON turns a list of solutions into a Solution object and writes to the filename requested.
+// Nothing is written if the filename is empty, as for a target of a jsonl batch output.
 func writeJSON(
 	filename,
 	targetName,
 	targetSeq string,
+	targetStrand int,
 	assemblies [][]*Frag,
 	insertSeqLength int,
 	seconds float64,
 	backbone *Backbone,
+	dbs []string,
 	conf *config.Config,
 ) (output []byte, err error) {
 	// store save time, using same format as log.Println https://golang.org/pkg/log/#Println
@@ -80,6 +230,10 @@ func writeJSON(
 		gibson := false // whether it will be assembled via Gibson assembly
 		hasPCR := false // whether there will be a batch PCR
 
+		junctionGCs(assembly, conf)
+		junctionFeatures(assembly, len(targetSeq))
+		setPrimerNames(assembly, targetName, conf)
+
 		for _, f := range assembly {
 			if f.fragType != linear && f.fragType != circular {
 				gibson = true
@@ -89,8 +243,64 @@ func writeJSON(
 				hasPCR = true
 			}
 
+			// check for primers binding elsewhere in the plasmid
+			if f.fragType == pcr && len(f.Primers) == 2 {
+				f.setSource()
+				f.setPrimerOrders()
+				if conf.PrimerTails {
+					f.setPrimerTails()
+				}
+
+				if f.Mispriming, err = targetMismatch(f, targetSeq, conf); err != nil {
+					stderr.Printf("warning: failed to check %s's primers against the plasmid: %v\n", f.ID, err)
+				}
+
+				for _, m := range f.Mispriming {
+					stderr.Printf(
+						"warning: %s primer of %s binds the plasmid at %d, a %d bp spurious amplicon\n",
+						m.Primer, f.ID, m.Start+1, m.Amplicon,
+					)
+				}
+
+				for _, p := range f.Primers {
+					if p.TailHairpin != 0 {
+						dir := "FWD"
+						if !p.Strand {
+							dir = "REV"
+						}
+						stderr.Printf(
+							"warning: tail of %s primer of %s forms a self-priming hairpin, ΔG %.2f kcal/mol\n",
+							dir, f.ID, p.TailHairpin,
+						)
+					}
+				}
+
+				if conf.PrimerRepeats {
+					for _, warning := range primerRepeats(f, targetSeq) {
+						stderr.Println(warning)
+					}
+				}
+			}
+
+			// sites left in synthetic fragments are shared with other fragments or couldn't be removed
+			if f.fragType == synthetic && len(conf.AvoidSites) > 0 {
+				if cuts, _ := cutsites(strings.ToUpper(f.Seq), avoidEnzymes(conf)); len(cuts) > 0 {
+					stderr.Printf("warning: %s has %d sites of the enzymes in --avoid-sites that couldn't be removed\n", f.ID, len(cuts))
+				}
+			}
+
+			f.Genomic = f.fragType != synthetic && conf.GenomicDB(f.db)
+
 			f.Type = f.fragType.String() // freeze fragment type
 
+			// keep the case of the target's sequence in the fragments made from it
+			if f.fragType == pcr || f.fragType == synthetic {
+				f.Seq = matchCase(f.Seq, targetSeq, f.start)
+			}
+			if f.PCRSeq != "" && len(f.Primers) == 2 {
+				f.PCRSeq = matchCase(f.PCRSeq, targetSeq, f.Primers[0].Range.start)
+			}
+
 			if f.URL == "" && f.fragType != synthetic {
 				f.URL = parseURL(f.ID, f.db)
 			}
@@ -99,6 +309,23 @@ func writeJSON(
 				f.ID = "" // just log one or the other
 			}
 
+			// suggest PCR conditions
+			if f.fragType == pcr && len(f.Primers) == 2 {
+				f.AnnealingTemp = math.Min(f.Primers[0].Tm, f.Primers[1].Tm)
+				f.AnnealingTemp = math.Round(f.AnnealingTemp*10) / 10
+
+				amplicon := f.PCRSeq
+				if amplicon == "" {
+					amplicon = f.Seq
+				}
+				f.ExtensionTime = int(math.Ceil(float64(len(amplicon)) / 1000 * conf.PCRExtensionRate))
+				f.AmpliconLength = len(amplicon)
+
+				if warning := longAmpliconWarning(f, conf); warning != "" {
+					stderr.Print(warning)
+				}
+			}
+
 			// round to two decimal places
 			if f.Cost, err = roundCost(f.cost(true)); err != nil {
 				return nil, err
@@ -117,7 +344,14 @@ func writeJSON(
 			assemblyCost += f.Cost
 		}
 
-		if gibson {
+		// the fixed cost of each plasmid to procure, like its shipping
+		sources := sourceCount(assembly)
+		assemblyCost += float64(sources) * conf.CostSource
+
+		if gibson && conf.JunctionMethod == "soe" {
+			// an overlap-extension PCR for each junction, rather than one Gibson Assembly
+			assemblyCost += float64(len(assembly))*conf.CostPCR + conf.CostTimePCR
+		} else if gibson {
 			assemblyCost += conf.CostGibson + conf.CostTimeGibson
 		}
 
@@ -131,9 +365,15 @@ func writeJSON(
 		}
 
 		solutions = append(solutions, Solution{
-			Count:     len(assembly),
-			Cost:      solutionCost,
-			Fragments: assembly,
+			Count:          len(assembly),
+			Cost:           solutionCost,
+			Penalty:        primersPenalty(assembly),
+			Sources:        sources,
+			Turnaround:     turnaround(assembly, conf),
+			MaxSynthLength: maxSynthLength(assembly),
+			Rotation:       rotation(assembly, len(targetSeq)),
+			Fragments:      assembly,
+			Thermocycler:   thermocycler(assembly, conf.PCRAnnealingRange),
 		})
 	}
 
@@ -160,13 +400,27 @@ func writeJSON(
 		backbone = nil
 	}
 
+	stable := stability(targetSeq)
+	if stable != nil {
+		for _, warning := range stable.Warnings {
+			stderr.Printf("warning: %s may be unstable: %s\n", targetName, warning)
+		}
+	}
+
 	out := Output{
-		Time:      time,
-		Target:    targetName,
-		TargetSeq: strings.ToUpper(targetSeq),
-		Execution: seconds,
-		Solutions: solutions,
-		Backbone:  backbone,
+		SchemaVersion:  schemaVersion,
+		Time:           time,
+		Target:         targetName,
+		TargetSeq:      targetSeq,
+		TargetStrand:   targetStrand,
+		Execution:      seconds,
+		Solutions:      solutions,
+		Backbone:       backbone,
+		SeqPrimerSites: seqPrimerSites(targetSeq, conf),
+		FlankSites:     flankSites(conf),
+		Stability:      stable,
+		Build:          Build(),
+		Provenance:     provenance(targetSeq, dbs),
 		// PlasmidSynthesisCost: fullSynthCost,
 		// InsertSynthesisCost: insertSynthCost,
 	}
@@ -176,6 +430,10 @@ func writeJSON(
 		return output, fmt.Errorf("failed to serialize output: %v", err)
 	}
 
+	if filename == "" {
+		return output, nil
+	}
+
 	if err = ioutil.WriteFile(filename, output, 0666); err != nil {
 		return output, fmt.Errorf("failed to write the output: %v", err)
 	}
@@ -183,6 +441,829 @@ func writeJSON(
 	return output, nil
 }
 
+// jsonlWriter writes the outputs of a batch run's targets as lines of JSON, one per target
+// in the order they're designed. It's safe for concurrent use by the batch's workers.
+type jsonlWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// write compacts an output onto a single line and writes it.
+func (j *jsonlWriter) write(output []byte) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, output); err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err := j.w.Write(line.Bytes())
+	return err
+}
+
+// flankSites returns the sites of the enzymes flanking the insert, 1-indexed.
+func flankSites(conf *config.Config) (sites []FlankSite) {
+	for _, r := range conf.FlankSites {
+		sites = append(sites, FlankSite{Enzyme: r.Name, Start: r.Start + 1, End: r.End + 1})
+	}
+	return
+}
+
+// seqPrimerSites returns the binding sites, on the plasmid, of the sequencing primers whose
+// sites were added to the ends of the insert: the first's on the top strand and the second's
+// on the bottom strand.
+func seqPrimerSites(plasmid string, conf *config.Config) (sites []SeqPrimerSite) {
+	doubled := strings.ToUpper(plasmid + plasmid) // catch sites across the zero-index
+	for i, name := range conf.SeqPrimerSites {
+		seq, err := seqPrimer(name, conf)
+		if err != nil {
+			continue
+		}
+
+		site, strand := seq, 1
+		if i > 0 {
+			site, strand = reverseComplement(seq), -1
+		}
+
+		if index := strings.Index(doubled, site); index >= 0 {
+			sites = append(sites, SeqPrimerSite{Name: name, Seq: seq, Start: index%len(plasmid) + 1, Strand: strand})
+		}
+	}
+
+	return
+}
+
+// provenance returns the hashes of the target's sequence and of the databases' files.
+// A database that can't be read is logged and left out.
+func provenance(targetSeq string, dbs []string) *Provenance {
+	targetHash := sha256.Sum256([]byte(targetSeq))
+	p := &Provenance{TargetSHA256: hex.EncodeToString(targetHash[:])}
+
+	for _, db := range dbs {
+		dbHash, err := hashDB(db)
+		if err != nil {
+			stderr.Printf("warning: failed to hash %s for the output's provenance: %v\n", db, err)
+			continue
+		}
+		p.DBs = append(p.DBs, DBHash{Path: db, SHA256: dbHash})
+	}
+
+	return p
+}
+
+// dbHash is the hash of a BLAST database's files, and the sizes and modification times
+// of the files when they were hashed.
+type dbHash struct {
+	// Stamp is the files' sizes and modification times
+	Stamp string `json:"stamp"`
+
+	// SHA256 is the hex SHA-256 of the files
+	SHA256 string `json:"sha256"`
+}
+
+// hashDB returns the hex SHA-256 of a BLAST database's files, those made by makeblastdb
+// with the path and an extension, like its .nsq. Each file's extension is hashed before
+// its contents. Hashes are cached, in the run and in the DBHashCache, until the files'
+// sizes or modification times change.
+func hashDB(db string) (string, error) {
+	var stamp strings.Builder
+	for _, ext := range blastDBIndexes {
+		info, err := os.Stat(db + ext)
+		if err != nil {
+			return "", fmt.Errorf("no database files at %s: %v", db, err)
+		}
+		fmt.Fprintf(&stamp, "%s:%d:%d;", ext, info.Size(), info.ModTime().UnixNano())
+	}
+
+	cacheMu.Lock()
+	if !dbHashesRead {
+		readDBHashes()
+	}
+	cached, contained := dbHashes[db]
+	cacheMu.Unlock()
+	if contained && cached.Stamp == stamp.String() {
+		return cached.SHA256, nil
+	}
+
+	h := sha256.New()
+	for _, ext := range blastDBIndexes {
+		f, err := os.Open(db + ext)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(ext + "\n"))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	cacheMu.Lock()
+	dbHashes[db] = dbHash{Stamp: stamp.String(), SHA256: sum}
+	writeDBHashes()
+	cacheMu.Unlock()
+
+	return sum, nil
+}
+
+// readDBHashes reads the hashes of databases from earlier runs. A missing or unreadable
+// cache is the same as an empty one. cacheMu must be held.
+func readDBHashes() {
+	dbHashesRead = true
+	contents, err := ioutil.ReadFile(config.DBHashCache)
+	if err != nil {
+		return
+	}
+
+	cached := make(map[string]dbHash)
+	if json.Unmarshal(contents, &cached) != nil {
+		return
+	}
+	for db, hash := range cached {
+		if _, contained := dbHashes[db]; !contained {
+			dbHashes[db] = hash
+		}
+	}
+}
+
+// writeDBHashes writes the hashes of databases for later runs. Failures are ignored,
+// the hashes are remade in the next run. cacheMu must be held.
+func writeDBHashes() {
+	contents, err := json.Marshal(dbHashes)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(config.DBHashCache), 0755) != nil {
+		return
+	}
+	ioutil.WriteFile(config.DBHashCache, contents, 0644)
+}
+
+// longAmpliconWarning returns a warning if the PCR fragment's amplicon is longer than the
+// polymerase reliably amplifies, or an empty string if it isn't.
+func longAmpliconWarning(f *Frag, conf *config.Config) string {
+	if conf.PCRMaxAmpliconLength < 1 || f.AmpliconLength <= conf.PCRMaxAmpliconLength {
+		return ""
+	}
+
+	name := f.ID
+	if name == "" {
+		name = f.URL
+	}
+	return fmt.Sprintf(
+		"warning: the %d bp amplicon of %s is longer than the %d bp pcr-max-amplicon-length. Split it or use a long-range polymerase\n",
+		f.AmpliconLength, name, conf.PCRMaxAmpliconLength,
+	)
+}
+
+// costRow is a summary of a single target's design in a batch run's cost report.
+type costRow struct {
+	// target's name
+	target string
+
+	// count of fragments in the cheapest solution
+	count int
+
+	// cost of the cheapest solution
+	cost float64
+
+	// synthesized bp in the cheapest solution
+	synthesized int
+
+	// err if the design failed
+	err error
+}
+
+// newCostRow summarizes the cheapest solution in a design's JSON output.
+func newCostRow(output []byte) (row costRow, err error) {
+	out := Output{}
+	if err = json.Unmarshal(output, &out); err != nil {
+		return row, fmt.Errorf("failed to parse design output: %v", err)
+	}
+
+	row.target = out.Target
+	if len(out.Solutions) < 1 {
+		row.err = fmt.Errorf("no solutions")
+		return row, nil
+	}
+
+	cheapest := out.Solutions[0]
+	for _, s := range out.Solutions {
+		if s.Cost < cheapest.Cost {
+			cheapest = s
+		}
+	}
+
+	row.count = cheapest.Count
+	row.cost = cheapest.Cost
+	for _, f := range cheapest.Fragments {
+		if f.Type == synthetic.String() {
+			row.synthesized += len(f.Seq)
+		}
+	}
+
+	return row, nil
+}
+
+// writeCostReport writes a summary of a batch run's designs, sorted by increasing cost
+// with the failed designs last. It's a CSV if the filename ends in ".csv", else a TSV.
+func writeCostReport(filename string, rows []costRow) error {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if (rows[i].err == nil) != (rows[j].err == nil) {
+			return rows[i].err == nil
+		}
+		return rows[i].cost < rows[j].cost
+	})
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create cost report: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if strings.ToLower(filepath.Ext(filename)) != ".csv" {
+		writer.Comma = '\t'
+	}
+
+	writer.Write([]string{"Target", "Fragments", "Cost", "Synthesized", "Succeeded", "Error"})
+	for _, r := range rows {
+		if r.err != nil {
+			writer.Write([]string{r.target, "", "", "", "false", r.err.Error()})
+			continue
+		}
+
+		writer.Write([]string{
+			r.target,
+			strconv.Itoa(r.count),
+			fmt.Sprintf("%.2f", r.cost),
+			strconv.Itoa(r.synthesized),
+			"true",
+			"",
+		})
+	}
+	writer.Flush()
+
+	if err = writer.Error(); err != nil {
+		return fmt.Errorf("failed to write cost report: %v", err)
+	}
+
+	return nil
+}
+
+// thermocycler groups the PCR fragments of an assembly into shared thermocycler programs.
+// PCRs with annealing temperatures within annealingRange of the group's lowest share
+// a program, at that lowest temperature and the longest extension time in the group.
+func thermocycler(assembly []*Frag, annealingRange float64) (programs []PCRProgram) {
+	var pcrs []*Frag
+	for _, f := range assembly {
+		if f.AnnealingTemp > 0 {
+			pcrs = append(pcrs, f)
+		}
+	}
+
+	sort.Slice(pcrs, func(i, j int) bool {
+		return pcrs[i].AnnealingTemp < pcrs[j].AnnealingTemp
+	})
+
+	for _, f := range pcrs {
+		name := f.ID
+		if name == "" {
+			name = f.URL
+		}
+
+		if last := len(programs) - 1; last >= 0 && f.AnnealingTemp-programs[last].AnnealingTemp <= annealingRange {
+			programs[last].Fragments = append(programs[last].Fragments, name)
+			if f.ExtensionTime > programs[last].ExtensionTime {
+				programs[last].ExtensionTime = f.ExtensionTime
+			}
+			continue
+		}
+
+		programs = append(programs, PCRProgram{
+			AnnealingTemp: f.AnnealingTemp,
+			ExtensionTime: f.ExtensionTime,
+			Fragments:     []string{name},
+		})
+	}
+
+	return
+}
+
+// junctionGCs sets the GC % and estimated Tm of each fragment's junction with the next
+// fragment in the assembly, and the criteria applied to it. Logs a warning for each
+// junction outside its criteria.
+func junctionGCs(assembly []*Frag, conf *config.Config) {
+	if len(assembly) < 2 {
+		return
+	}
+
+	for i, f := range assembly {
+		next := assembly[(i+1)%len(assembly)]
+		junction := f.junction(next, conf.FragmentsMinHomology, conf.FragmentsMaxHomology+1)
+		if junction == "" {
+			continue
+		}
+
+		f.junctionSeq = junction
+		f.JunctionGC = math.Round(gcContent(junction)*10) / 10
+		f.JunctionTm = math.Round(junctionTm(junction, conf.MonovalentConc())*10) / 10
+		f.JunctionMethod = conf.JunctionMethod
+
+		seam := seamType(f, next)
+		_, f.JunctionCriteria = conf.JunctionCriteriaOf(seam)
+		if !junctionInCriteria(junction, seam, conf) {
+			stderr.Printf(
+				"warning: no junction between %s and %s within the %s junction criteria. Using one with %.1f%% GC and a %.1f Tm\n",
+				f.ID, next.ID, f.JunctionCriteria, f.JunctionGC, f.JunctionTm,
+			)
+		}
+	}
+}
+
+// junctionFeatures sets the shared feature, if any, whose boundary each fragment's
+// junction with the next fragment in the assembly is on.
+func junctionFeatures(assembly []*Frag, targetLength int) {
+	if len(assembly) < 2 {
+		return
+	}
+
+	for i, f := range assembly {
+		f.JunctionFeature = f.junctionFeature(nextFrag(assembly, i, targetLength))
+	}
+}
+
+// primerRepeats returns a warning for each of a PCR fragment's primers that binds the
+// assembled plasmid more than once, with the 1-based starts of the binding sites: its
+// own and those found by targetMismatch, in the fragment's Mispriming.
+func primerRepeats(f *Frag, plasmid string) (warnings []string) {
+	tL := len(plasmid)
+	if len(f.Primers) != 2 || tL < 1 {
+		return nil
+	}
+	doubled := strings.ToUpper(plasmid + plasmid)
+
+	for i, p := range f.Primers {
+		dir, site := "FWD", strings.ToUpper(p.Seq)
+		if i > 0 {
+			dir, site = "REV", reverseComplement(site)
+		}
+
+		var starts []string
+		if own := strings.Index(doubled, site); own >= 0 {
+			starts = append(starts, strconv.Itoa(own%tL+1))
+		}
+		for _, m := range f.Mispriming {
+			if m.Primer == dir {
+				starts = append(starts, strconv.Itoa(m.Start+1))
+			}
+		}
+		if len(starts) < 2 {
+			continue
+		}
+
+		warnings = append(warnings, fmt.Sprintf(
+			"warning: %s primer of %s binds the plasmid at %d sites: %s",
+			dir, f.ID, len(starts), strings.Join(starts, ", "),
+		))
+	}
+
+	return
+}
+
+// sourceCount returns the number of distinct source plasmids, by URL, to procure from
+// repositories for an assembly. Those in the user's inventory are already on hand.
+func sourceCount(assembly []*Frag) int {
+	sources := make(map[string]bool)
+	for _, f := range assembly {
+		if f.URL != "" && !f.Inventory {
+			sources[f.URL] = true
+		}
+	}
+	return len(sources)
+}
+
+// rotation returns the 1-based index of the circular target where an assembly's first
+// fragment starts. The index includes bp added by the fragment's primers.
+func rotation(assembly []*Frag, targetLength int) int {
+	if len(assembly) == 0 || targetLength < 1 {
+		return 1
+	}
+
+	start := assembly[0].start
+	if first := assembly[0]; len(first.Primers) == 2 {
+		start = first.Primers[0].Range.start
+	}
+
+	return (start%targetLength+targetLength)%targetLength + 1
+}
+
+// writeBenchling writes a CSV feature table for each solution that can be imported
+// into Benchling alongside the plasmid's sequence. Fragments, primers, junctions and
+// the backbone's recognition sites are annotated on the final circular plasmid.
+func writeBenchling(filename, targetSeq string, assemblies [][]*Frag, backbone *Backbone) error {
+	targetSeq = strings.ToUpper(targetSeq)
+	if len(targetSeq) < 1 {
+		return fmt.Errorf("failed to write feature table: no plasmid sequence")
+	}
+
+	enzymes := backboneEnzymes(backbone)
+
+	for i, assembly := range assemblies {
+		rows := benchlingRows(targetSeq, assembly, enzymes)
+
+		out, err := os.Create(benchlingFilename(filename, i, len(assemblies)))
+		if err != nil {
+			return fmt.Errorf("failed to write feature table: %v", err)
+		}
+
+		w := csv.NewWriter(out)
+		w.Write([]string{"Name", "Start", "End", "Strand", "Type"})
+		w.WriteAll(rows)
+		out.Close()
+
+		if err = w.Error(); err != nil {
+			return fmt.Errorf("failed to write feature table: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// benchlingRows returns the Benchling feature rows for a single assembly.
+// Coordinates are 1-based and inclusive on the final circular plasmid.
+func benchlingRows(targetSeq string, assembly []*Frag, enzymes []enzyme) (rows [][]string) {
+	tL := len(targetSeq)
+	row := func(name string, start, end int, forward bool, featType string) []string {
+		strand := "1"
+		if !forward {
+			strand = "-1"
+		}
+
+		start = (start%tL+tL)%tL + 1
+		end = (end%tL+tL)%tL + 1
+		return []string{name, strconv.Itoa(start), strconv.Itoa(end), strand, featType}
+	}
+
+	// the range that a fragment spans on the plasmid, including bp added by its primers
+	span := func(f *Frag) (int, int) {
+		if len(f.Primers) == 2 {
+			return f.Primers[0].Range.start, f.Primers[1].Range.end
+		}
+		return f.start, f.end
+	}
+
+	for i, f := range assembly {
+		start, end := span(f)
+		rows = append(rows, row(fragName(f), start, end, true, "fragment"))
+
+		for _, p := range f.Primers {
+			primerName := p.Name
+			if primerName == "" {
+				primerName = fragName(f) + " primer"
+			}
+			rows = append(rows, row(primerName, p.Range.start, p.Range.end, p.Strand, "primer_bind"))
+		}
+
+		// the homology with the next fragment in the assembly
+		if len(assembly) > 1 {
+			next := assembly[(i+1)%len(assembly)]
+			nextStart, _ := span(next)
+			if i == len(assembly)-1 {
+				nextStart += tL // the next fragment is across the zero index
+			}
+			if nextStart <= end {
+				rows = append(rows, row(fragName(f)+"-"+fragName(next)+" junction", nextStart, end, true, "junction"))
+			}
+		}
+	}
+
+	cuts, _ := cutsites(targetSeq, enzymes)
+	for _, c := range cuts {
+		rows = append(rows, row(c.enzyme.name, c.index, c.index+len(c.enzyme.recog)-1, c.strand, "restriction_site"))
+	}
+
+	return
+}
+
+// benchlingFilename returns the name of the feature table for a solution. A number is
+// added for each solution if there are more than one: example.output-1.csv
+func benchlingFilename(filename string, index, count int) string {
+	noExt := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if count < 2 {
+		return noExt + ".csv"
+	}
+	return fmt.Sprintf("%s-%d.csv", noExt, index+1)
+}
+
+// writeSynthFasta writes the synthetic fragments of the cheapest solution to a FASTA
+// file, for a synthesis vendor's bulk order. A batch run's records are all in one file.
+func writeSynthFasta(filename, records string) error {
+	if records == "" {
+		stderr.Printf("warning: no synthetic fragments to write to %s\n", filename)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte(records), 0644); err != nil {
+		return fmt.Errorf("failed to write synthetic fragments: %v", err)
+	}
+
+	return nil
+}
+
+// synthFasta returns a FASTA record for each synthetic fragment in the cheapest of the
+// assemblies. Each is named by the target and the 1-based range of the fragment on it:
+// >target:1201-1650
+func synthFasta(targetName string, targetLength int, assemblies [][]*Frag) string {
+	if len(assemblies) < 1 || targetLength < 1 {
+		return ""
+	}
+
+	// fragments' costs are set when the output is written
+	cost := func(assembly []*Frag) (total float64) {
+		for _, f := range assembly {
+			total += f.Cost
+		}
+		return
+	}
+
+	cheapest := assemblies[0]
+	for _, assembly := range assemblies {
+		if cost(assembly) < cost(cheapest) {
+			cheapest = assembly
+		}
+	}
+
+	var sb strings.Builder
+	for _, f := range cheapest {
+		if f.fragType != synthetic {
+			continue
+		}
+
+		start := (f.start%targetLength+targetLength)%targetLength + 1
+		end := ((f.start+len(f.Seq)-1)%targetLength+targetLength)%targetLength + 1
+		fmt.Fprintf(&sb, ">%s:%d-%d\n%s\n", targetName, start, end, strings.ToUpper(f.Seq))
+	}
+
+	return sb.String()
+}
+
+// fragName returns the name of a fragment in reports: its ID or, if it has none, its URL.
+func fragName(f *Frag) string {
+	if f.ID != "" {
+		return f.ID
+	}
+	return f.URL
+}
+
+// writeBOM writes the bill of materials of a design, or of every design in a batch run, to
+// a CSV: the plasmids, enzymes, primers and synthetic fragments to order for each solution.
+func writeBOM(filename string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create bill of materials: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"Target", "Solution", "Item", "Name", "Sequence", "Length", "URL", "Cost"})
+	writer.WriteAll(rows)
+
+	if err = writer.Error(); err != nil {
+		return fmt.Errorf("failed to write bill of materials: %v", err)
+	}
+
+	return nil
+}
+
+// bomRows returns the bill of materials rows of each of the assemblies, with their costs
+// from the output: the source plasmids and backbone to procure, the enzymes to digest the
+// backbone, and the primers and synthetic fragments to order. Plasmids in the user's
+// inventory or in hand aren't procured. Solutions are 1-based, as in the output.
+func bomRows(targetName string, assemblies [][]*Frag, backbone *Backbone, conf *config.Config) (rows [][]string) {
+	for i, assembly := range assemblies {
+		row := func(item, name, seq string, length int, url, cost string) []string {
+			lengthCol := ""
+			if length > 0 {
+				lengthCol = strconv.Itoa(length)
+			}
+			return []string{targetName, strconv.Itoa(i + 1), item, name, seq, lengthCol, url, cost}
+		}
+
+		// the cost of procuring a plasmid and the fixed cost of it as a source
+		procure := func(f *Frag) string {
+			if f.URL == "" || f.Inventory || f.InHand {
+				return fmt.Sprintf("%.2f", 0.0)
+			}
+			return fmt.Sprintf("%.2f", procureCost(f.URL, conf)+conf.CostSource)
+		}
+
+		var plasmids, enzymes, primers, synths [][]string
+		procured := make(map[string]bool)
+		for _, f := range assembly {
+			switch {
+			case f.fragType == synthetic:
+				synths = append(synths, row("synthetic", fragName(f), strings.ToUpper(f.Seq), len(f.Seq), "", fmt.Sprintf("%.2f", f.Cost)))
+			case strings.HasPrefix(f.uniqueID, "backbone"):
+				procured[f.URL] = true
+				length := len(f.Seq)
+				if backbone != nil && backbone.Seq != "" {
+					length = len(backbone.Seq) // as it's ordered, uncut
+				}
+				plasmids = append(plasmids, row("backbone", fragName(f), "", length, f.URL, procure(f)))
+			case f.URL != "" && !f.Inventory && !f.InHand && !procured[f.URL]:
+				procured[f.URL] = true
+				plasmids = append(plasmids, row("plasmid", fragName(f), "", 0, f.URL, procure(f)))
+			}
+
+			for _, p := range f.Primers {
+				order := p.Order
+				if order == "" {
+					order = p.Seq
+				}
+				primers = append(primers, row("primer", p.Name, order, len(p.Seq), "", fmt.Sprintf("%.2f", float64(len(p.Seq))*conf.CostBP)))
+			}
+		}
+
+		if backbone != nil {
+			for _, e := range backbone.Enzymes {
+				enzymes = append(enzymes, row("enzyme", e, "", 0, "", ""))
+			}
+		}
+
+		for _, items := range [][][]string{plasmids, enzymes, primers, synths} {
+			rows = append(rows, items...)
+		}
+	}
+
+	return
+}
+
+// writeOverlapReport writes a TSV of the junctions of a design's solutions: the fragments on
+// either side of each, its overlap, the overlap's GC % and Tm, and the QC checks it fails.
+func writeOverlapReport(filename string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create overlap report: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = '\t'
+	writer.Write([]string{"Solution", "Left", "Right", "Length", "Overlap", "GC", "Tm", "Flags"})
+	writer.WriteAll(rows)
+
+	if err = writer.Error(); err != nil {
+		return fmt.Errorf("failed to write overlap report: %v", err)
+	}
+
+	return nil
+}
+
+// overlapRows returns a row for each junction of each of the assemblies, from the overlaps
+// set on their fragments in the output. Solutions are 1-based, as in the output.
+func overlapRows(targetSeq string, assemblies [][]*Frag, conf *config.Config) (rows [][]string) {
+	for i, assembly := range assemblies {
+		for j, f := range assembly {
+			if f.junctionSeq == "" {
+				continue
+			}
+
+			next := assembly[(j+1)%len(assembly)]
+			rows = append(rows, []string{
+				strconv.Itoa(i + 1),
+				fragName(f),
+				fragName(next),
+				strconv.Itoa(len(f.junctionSeq)),
+				f.junctionSeq,
+				fmt.Sprintf("%.1f", f.JunctionGC),
+				fmt.Sprintf("%.1f", f.JunctionTm),
+				strings.Join(overlapFlags(assembly, j, targetSeq, conf), ","),
+			})
+		}
+	}
+
+	return
+}
+
+// overlapFlags returns the QC checks that the junction of the fragment at index i of the
+// assembly with the next fails: "hairpin" if its overlap has a hairpin that melts above
+// the max hairpin melt, "repeat" if the overlap or its reverse complement is elsewhere in
+// the plasmid, "duplicate" if the fragment also anneals to itself or a fragment other
+// than the next, and "criteria" if the overlap is outside its junction criteria.
+func overlapFlags(assembly []*Frag, i int, targetSeq string, conf *config.Config) (flags []string) {
+	f := assembly[i]
+	next := assembly[(i+1)%len(assembly)]
+	junction := f.junctionSeq
+
+	if hairpin(junction, conf) > conf.FragmentsMaxHairpinMelt {
+		flags = append(flags, "hairpin")
+	}
+
+	target := strings.ToUpper(targetSeq)
+	if len(junction) > 0 && len(junction) <= len(target) {
+		plasmid := target + target[:len(junction)-1] // across the zero index
+		count := strings.Count(plasmid, junction)
+		if rc := reverseComplement(junction); rc != junction {
+			count += strings.Count(plasmid, rc) // a palindrome's is the same site
+		}
+		if count > 1 {
+			flags = append(flags, "repeat")
+		}
+	}
+
+	min, max := conf.FragmentsMinHomology, conf.FragmentsMaxHomology+1
+	duplicate := false
+	if selfJ := f.junction(f, min, max); selfJ != "" && len(selfJ) < len(f.Seq) {
+		duplicate = true
+	}
+	for j := 2; j < len(assembly); j++ { // skip next Frag, it's supposed to anneal
+		if f.junction(assembly[(i+j)%len(assembly)], min, max) != "" {
+			duplicate = true
+		}
+	}
+	if duplicate {
+		flags = append(flags, "duplicate")
+	}
+
+	if !junctionInCriteria(junction, seamType(f, next), conf) {
+		flags = append(flags, "criteria")
+	}
+
+	return
+}
+
+// writeGraph writes the graph of fragments searched for assemblies to a GraphViz DOT file.
+func writeGraph(filename string, frags []*Frag, features bool, solutions [][]*Frag) error {
+	if err := ioutil.WriteFile(filename, []byte(graphDOT(frags, features, solutions)), 0644); err != nil {
+		return fmt.Errorf("failed to write assembly graph: %v", err)
+	}
+
+	return nil
+}
+
+// graphDOT returns the graph of fragments searched for assemblies in DOT. Each node is a
+// fragment, in order of its start on the target, and each edge is a junction to a fragment
+// it reaches via PCR (solid) or synthesis (dashed). The cheapest solution's are red.
+func graphDOT(frags []*Frag, features bool, solutions [][]*Frag) string {
+	// the fragments' unique IDs, and the junctions between them, in the cheapest solution.
+	// Fragments from other matches against the same template share their ID but not this
+	chosen := make(map[string]bool)
+	chosenJunctions := make(map[string]bool)
+	if len(solutions) > 0 {
+		cheapest := solutions[0]
+		for _, s := range solutions {
+			if fragsCost(s) < fragsCost(cheapest) {
+				cheapest = s
+			}
+		}
+
+		var ids []string
+		for _, f := range cheapest {
+			if f.fragType != synthetic {
+				ids = append(ids, f.uniqueID)
+				chosen[f.uniqueID] = true
+			}
+		}
+		for i, id := range ids {
+			chosenJunctions[id+"\t"+ids[(i+1)%len(ids)]] = true
+		}
+	}
+
+	highlight := func(isChosen bool) string {
+		if isChosen {
+			return ", color=red, penwidth=2"
+		}
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph assembly {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for i, f := range frags {
+		label := fmt.Sprintf("%s\n%d-%d", f.ID, f.start+1, f.end+1)
+		if features {
+			label = fmt.Sprintf("%s\nfeatures %d-%d", f.ID, f.featureStart+1, f.featureEnd+1)
+		}
+		fmt.Fprintf(&sb, "\tn%d [label=%q%s];\n", i, label, highlight(chosen[f.uniqueID]))
+	}
+
+	for i, f := range frags {
+		for _, j := range f.reach(frags, i, features) {
+			method, style := "synthesis", "dashed"
+			if f.overlapsViaPCR(frags[j]) {
+				method, style = "pcr", "solid"
+			}
+			isChosen := chosenJunctions[f.uniqueID+"\t"+frags[j].uniqueID]
+			fmt.Fprintf(&sb, "\tn%d -> n%d [label=%q, style=%s%s];\n", i, j, method, style, highlight(isChosen))
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
 // writeGenbank writes a slice of fragments/features to a genbank output file.
 func writeGenbank(filename, name, seq string, frags []*Frag, feats []match) {
 	// header row
@@ -1,8 +1,15 @@
 package repp
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/jjtimmons/repp/config"
 )
 
 func Test_writeGenbank(t *testing.T) {
@@ -48,3 +55,519 @@ func Test_writeGenbank(t *testing.T) {
 		})
 	}
 }
+
+func Test_longAmpliconWarning(t *testing.T) {
+	conf := &config.Config{PCRMaxAmpliconLength: 6000}
+
+	tests := []struct {
+		name string
+		frag *Frag
+		want string
+	}{
+		{
+			"within the limit",
+			&Frag{ID: "pSB1A3", AmpliconLength: 6000},
+			"",
+		},
+		{
+			"longer than the limit",
+			&Frag{URL: "https://www.addgene.org/85472/", AmpliconLength: 7200},
+			"warning: the 7200 bp amplicon of https://www.addgene.org/85472/ is longer than the 6000 bp pcr-max-amplicon-length. Split it or use a long-range polymerase\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longAmpliconWarning(tt.frag, conf); got != tt.want {
+				t.Errorf("longAmpliconWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newCostRow(t *testing.T) {
+	output := []byte(`{
+		"target": "p1",
+		"solutions": [
+			{"count": 2, "cost": 120.5, "fragments": [{"type": "pcr", "seq": "ATGC"}, {"type": "synthetic", "seq": "ATGCATGC"}]},
+			{"count": 3, "cost": 80.25, "fragments": [{"type": "pcr", "seq": "ATGC"}, {"type": "synthetic", "seq": "ATG"}, {"type": "synthetic", "seq": "AT"}]}
+		]
+	}`)
+
+	got, err := newCostRow(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := costRow{target: "p1", count: 3, cost: 80.25, synthesized: 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newCostRow() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_writeCostReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cost-report-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rows := []costRow{
+		{target: "failed", err: fmt.Errorf("no solutions")},
+		{target: "expensive", count: 4, cost: 310.5, synthesized: 1200},
+		{target: "cheap", count: 2, cost: 95, synthesized: 0},
+	}
+
+	filename := filepath.Join(dir, "report.tsv")
+	if err := writeCostReport(filename, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := ioutil.ReadFile(filename)
+	want := "Target\tFragments\tCost\tSynthesized\tSucceeded\tError\n" +
+		"cheap\t2\t95.00\t0\ttrue\t\n" +
+		"expensive\t4\t310.50\t1200\ttrue\t\n" +
+		"failed\t\t\t\tfalse\tno solutions\n"
+	if string(got) != want {
+		t.Errorf("writeCostReport() = %q, want %q", got, want)
+	}
+}
+
+func Test_jsonlWriter(t *testing.T) {
+	var out strings.Builder
+	lines := &jsonlWriter{w: &out}
+
+	outputs := []string{
+		"{\n  \"target\": \"GFP_CDS\",\n  \"solutions\": []\n}",
+		"{\n  \"target\": \"RFP_CDS\",\n  \"seq\": \"ATG\"\n}",
+	}
+	for _, output := range outputs {
+		if err := lines.write([]byte(output)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "{\"target\":\"GFP_CDS\",\"solutions\":[]}\n{\"target\":\"RFP_CDS\",\"seq\":\"ATG\"}\n"
+	if out.String() != want {
+		t.Errorf("jsonlWriter.write() = %q, want %q", out.String(), want)
+	}
+
+	if err := lines.write([]byte("{")); err == nil {
+		t.Error("jsonlWriter.write() of invalid JSON didn't fail")
+	}
+}
+
+func Test_seqPrimerSites(t *testing.T) {
+	conf := &config.Config{SeqPrimerSites: []string{"M13F", "M13R"}}
+
+	// M13F's site on the top strand at bp 9 (1-indexed), M13R's on the bottom strand across the zero index
+	plasmid := "CCTG" + "AAAA" + "GTAAAACGACGGCCAGT" + "ATGCGTAAAGGCGAAGAACTGTAA" + "GTCATAGCTGTTT"
+	want := []SeqPrimerSite{
+		{Name: "M13F", Seq: "GTAAAACGACGGCCAGT", Start: 9, Strand: 1},
+		{Name: "M13R", Seq: "CAGGAAACAGCTATGAC", Start: 50, Strand: -1},
+	}
+
+	if got := seqPrimerSites(plasmid, conf); !reflect.DeepEqual(got, want) {
+		t.Errorf("seqPrimerSites() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_provenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provenance-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hashCache := config.DBHashCache
+	config.DBHashCache = filepath.Join(dir, "cache", "db-hashes.json")
+	dbHashes, dbHashesRead = make(map[string]dbHash), false
+	defer func() { config.DBHashCache, dbHashes, dbHashesRead = hashCache, make(map[string]dbHash), false }()
+
+	// two copies of a db, a third with a different index, and a sibling db of the first
+	for db, index := range map[string]string{"parts": "nsq", "copy": "nsq", "changed": "nsq2", "parts.old": "old"} {
+		for _, ext := range blastDBIndexes {
+			ioutil.WriteFile(filepath.Join(dir, db+ext), []byte(index), 0644)
+		}
+	}
+	dbs := []string{filepath.Join(dir, "parts"), filepath.Join(dir, "copy"), filepath.Join(dir, "changed"), filepath.Join(dir, "missing")}
+
+	got := provenance("ACGT", dbs)
+	if got.TargetSHA256 != "1dff3e84fe7877e0673b69bbddcf40124e396e3f9943dd890c91b6a09adb9af0" {
+		t.Errorf("provenance() target = %s", got.TargetSHA256)
+	}
+	if len(got.DBs) != 3 {
+		t.Fatalf("provenance() dbs = %+v, want 3 without the missing db", got.DBs)
+	}
+	if got.DBs[0].SHA256 != got.DBs[1].SHA256 {
+		t.Errorf("provenance() hashes of the same db's files differ, or include a sibling db's: %s, %s", got.DBs[0].SHA256, got.DBs[1].SHA256)
+	}
+	if got.DBs[0].SHA256 == got.DBs[2].SHA256 {
+		t.Errorf("provenance() hash didn't change with the db's files: %s", got.DBs[2].SHA256)
+	}
+
+	// the hashes are read from the cache in later runs, and remade when the files change
+	dbHashes, dbHashesRead = make(map[string]dbHash), false
+	if cached := provenance("ACGT", dbs); cached.DBs[2].SHA256 != got.DBs[2].SHA256 {
+		t.Errorf("provenance() cached hash = %s, want %s", cached.DBs[2].SHA256, got.DBs[2].SHA256)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "changed.nsq"), []byte("changed again"), 0644)
+	if changed := provenance("ACGT", dbs); changed.DBs[2].SHA256 == got.DBs[2].SHA256 {
+		t.Errorf("provenance() hash didn't change after the db's files did: %s", changed.DBs[2].SHA256)
+	}
+	if len(dbHashes) != 3 {
+		t.Errorf("provenance() cached %d hashes, want 3", len(dbHashes))
+	}
+}
+
+func Test_benchlingRows(t *testing.T) {
+	type args struct {
+		targetSeq string
+		assembly  []*Frag
+		enzymes   []enzyme
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantRows [][]string
+	}{
+		{
+			"fragments, junctions and a recognition site across the zero index",
+			args{
+				"ATGCATGCATGAATTCATGCATGCATGCATGCATGCATGC",
+				[]*Frag{
+					&Frag{ID: "f1", start: 0, end: 24},
+					&Frag{ID: "f2", start: 20, end: 44},
+				},
+				[]enzyme{newEnzyme("EcoRI", "G^AATT_C")},
+			},
+			[][]string{
+				[]string{"f1", "1", "25", "1", "fragment"},
+				[]string{"f1-f2 junction", "21", "25", "1", "junction"},
+				[]string{"f2", "21", "5", "1", "fragment"},
+				[]string{"f2-f1 junction", "1", "5", "1", "junction"},
+				[]string{"EcoRI", "11", "16", "1", "restriction_site"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotRows := benchlingRows(tt.args.targetSeq, tt.args.assembly, tt.args.enzymes); !reflect.DeepEqual(gotRows, tt.wantRows) {
+				t.Errorf("benchlingRows() = %v, want %v", gotRows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func Test_synthFasta(t *testing.T) {
+	pcrFrag := &Frag{ID: "f1", Cost: 50, start: 0, end: 59, fragType: pcr}
+	synthFrag := &Frag{ID: "f1-f2-synthesis-1", Cost: 89, Seq: "acgtacgtac", start: 50, end: 60, fragType: synthetic}
+
+	tests := []struct {
+		name       string
+		assemblies [][]*Frag
+		want       string
+	}{
+		{
+			"synthetic fragments of the cheapest assembly",
+			[][]*Frag{
+				[]*Frag{synthFrag, &Frag{ID: "f2-f1-synthesis-1", Cost: 120, Seq: "GGGGCCCC", start: 95, end: 103, fragType: synthetic}},
+				[]*Frag{pcrFrag, synthFrag},
+			},
+			">target:51-60\nACGTACGTAC\n",
+		},
+		{
+			"synthetic fragment across the zero index",
+			[][]*Frag{
+				[]*Frag{pcrFrag, &Frag{ID: "f1-f1-synthesis-1", Cost: 89, Seq: "GGGGCCCC", start: 95, end: 103, fragType: synthetic}},
+			},
+			">target:96-3\nGGGGCCCC\n",
+		},
+		{
+			"no synthetic fragments",
+			[][]*Frag{[]*Frag{pcrFrag}},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := synthFasta("target", 100, tt.assemblies); got != tt.want {
+				t.Errorf("synthFasta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bomRows(t *testing.T) {
+	c := config.New()
+	c.CostBP = 0.5
+	c.CostAddgene = 65
+	c.CostIGEM = 0
+	c.CostSource = 10
+
+	backbone := &Frag{URL: "https://www.addgene.org/50005/", uniqueID: "backbone100", Seq: "ACGTACGTAC", fragType: linear}
+	primers := []Primer{
+		{Name: "target_f1_FWD", Seq: "ACGTAC", Order: "/5Phos/ACGTAC", Strand: true},
+		{Name: "target_f1_REV", Seq: "GGCC", Strand: false},
+	}
+	pcrFrag := &Frag{URL: "http://parts.igem.org/Part:BBa_E0040", Primers: primers, fragType: pcr}
+	samePlasmid := &Frag{URL: "http://parts.igem.org/Part:BBa_E0040", fragType: pcr}
+	inventory := &Frag{ID: "lab_plasmid", URL: "https://www.addgene.org/1/", Inventory: true, fragType: pcr}
+	synthFrag := &Frag{ID: "target-synthesis-1", Seq: "ggcccc", Cost: 12.5, fragType: synthetic}
+	meta := &Backbone{URL: backbone.URL, Seq: "ACGTACGTACGAATTCTGCAG", Enzymes: []string{"EcoRI", "PstI"}}
+
+	want := [][]string{
+		{"target", "1", "backbone", "https://www.addgene.org/50005/", "", "21", "https://www.addgene.org/50005/", "75.00"},
+		{"target", "1", "plasmid", "http://parts.igem.org/Part:BBa_E0040", "", "", "http://parts.igem.org/Part:BBa_E0040", "10.00"},
+		{"target", "1", "enzyme", "EcoRI", "", "", "", ""},
+		{"target", "1", "enzyme", "PstI", "", "", "", ""},
+		{"target", "1", "primer", "target_f1_FWD", "/5Phos/ACGTAC", "6", "", "3.00"},
+		{"target", "1", "primer", "target_f1_REV", "GGCC", "4", "", "2.00"},
+		{"target", "1", "synthetic", "target-synthesis-1", "GGCCCC", "6", "", "12.50"},
+	}
+
+	got := bomRows("target", [][]*Frag{{backbone, pcrFrag, samePlasmid, inventory, synthFrag}}, meta, c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bomRows() = %v, want %v", got, want)
+	}
+}
+
+func Test_overlapRows(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 15
+	c.FragmentsMaxHomology = 30
+	c.FragmentsMinJunctionGC = 30
+	c.FragmentsMaxJunctionGC = 70
+	c.JunctionCriteria = nil
+
+	left := strings.Repeat("ACT", 10)
+	right := strings.Repeat("TCA", 10)
+	unique := "AACCTTGGACAGTCAGTGAC"
+	palindrome := "ACAGTCGAATTCGACTGT" // its own reverse complement
+
+	tests := []struct {
+		name     string
+		junction string
+		target   string
+		want     string
+	}{
+		{
+			"unique junction",
+			unique,
+			left + unique + right,
+			"",
+		},
+		{
+			"junction repeated in the plasmid",
+			unique,
+			left + unique + right + reverseComplement(unique),
+			"repeat",
+		},
+		{
+			"palindromic junction isn't its own repeat",
+			palindrome,
+			left + palindrome + right,
+			"hairpin", // it folds on itself, but isn't elsewhere
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f1 := &Frag{ID: "f1", Seq: left + tt.junction, junctionSeq: tt.junction, JunctionGC: 50, JunctionTm: 60.5, fragType: pcr, conf: c}
+			f2 := &Frag{URL: "https://www.addgene.org/1/", Seq: tt.junction + right, fragType: pcr, conf: c}
+
+			want := [][]string{{"1", "f1", "https://www.addgene.org/1/", fmt.Sprint(len(tt.junction)), tt.junction, "50.0", "60.5", tt.want}}
+			if got := overlapRows(tt.target, [][]*Frag{{f1, f2}}, c); !reflect.DeepEqual(got, want) {
+				t.Errorf("overlapRows() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_sourceCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		assembly []*Frag
+		want     int
+	}{
+		{
+			"two fragments from one plasmid",
+			[]*Frag{
+				&Frag{URL: "https://www.addgene.org/103998/"},
+				&Frag{URL: "https://www.addgene.org/103998/"},
+				&Frag{URL: "http://parts.igem.org/Part:pSB1A3"},
+			},
+			2,
+		},
+		{
+			"synthetic, local and inventory fragments aren't procured",
+			[]*Frag{
+				&Frag{ID: "103998-synthesis-1", fragType: synthetic},
+				&Frag{ID: "local-frag"},
+				&Frag{URL: "https://www.addgene.org/85472/", Inventory: true},
+				&Frag{URL: "https://www.addgene.org/103998/"},
+			},
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sourceCount(tt.assembly); got != tt.want {
+				t.Errorf("sourceCount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_primerRepeats(t *testing.T) {
+	plasmid := "CCCCCGATCCTTAGCAAAAAAAAAATTTTTGCTAACCAAAAA"
+	f := &Frag{
+		ID:         "frag",
+		Primers:    []Primer{Primer{Seq: "GATCCTTAGC", Strand: true}, Primer{Seq: "GGTTAGCAAA", Strand: false}},
+		Mispriming: []Mispriming{Mispriming{Primer: "FWD", Start: 29, Amplicon: 12}},
+	}
+
+	want := []string{"warning: FWD primer of frag binds the plasmid at 2 sites: 6, 30"}
+	if got := primerRepeats(f, plasmid); !reflect.DeepEqual(got, want) {
+		t.Errorf("primerRepeats() = %v, want %v", got, want)
+	}
+}
+
+func Test_rotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		assembly []*Frag
+		want     int
+	}{
+		{
+			"starts at the zero index",
+			[]*Frag{&Frag{start: 0, end: 500}, &Frag{start: 480, end: 1020}},
+			1,
+		},
+		{
+			"starts past the zero index",
+			[]*Frag{&Frag{start: 1400, end: 2100}, &Frag{start: 2080, end: 2430}},
+			401,
+		},
+		{
+			"starts at the PCR fragment's primer",
+			[]*Frag{
+				&Frag{start: 300, end: 800, Primers: []Primer{
+					Primer{Range: ranged{start: 280, end: 320}},
+					Primer{Range: ranged{start: 780, end: 800}},
+				}},
+			},
+			281,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rotation(tt.assembly, 1000); got != tt.want {
+				t.Errorf("rotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_thermocycler(t *testing.T) {
+	type args struct {
+		assembly       []*Frag
+		annealingRange float64
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantPrograms []PCRProgram
+	}{
+		{
+			"group PCRs with similar annealing temperatures",
+			args{
+				[]*Frag{
+					&Frag{ID: "1", AnnealingTemp: 58.2, ExtensionTime: 30},
+					&Frag{ID: "2", fragType: synthetic},
+					&Frag{ID: "3", AnnealingTemp: 64.0, ExtensionTime: 15},
+					&Frag{URL: "https://www.addgene.org/103998/", AnnealingTemp: 57.0, ExtensionTime: 45},
+				},
+				2.0,
+			},
+			[]PCRProgram{
+				PCRProgram{
+					AnnealingTemp: 57.0,
+					ExtensionTime: 45,
+					Fragments:     []string{"https://www.addgene.org/103998/", "1"},
+				},
+				PCRProgram{
+					AnnealingTemp: 64.0,
+					ExtensionTime: 15,
+					Fragments:     []string{"3"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotPrograms := thermocycler(tt.args.assembly, tt.args.annealingRange); !reflect.DeepEqual(gotPrograms, tt.wantPrograms) {
+				t.Errorf("thermocycler() = %v, want %v", gotPrograms, tt.wantPrograms)
+			}
+		})
+	}
+}
+
+func Test_graphDOT(t *testing.T) {
+	conf := &config.Config{PCRMaxEmbedLength: 20, FragmentsMinHomology: 10}
+	frags := []*Frag{
+		&Frag{ID: "a", uniqueID: "a0", start: 0, end: 99, conf: conf},
+		&Frag{ID: "b", uniqueID: "b80", start: 80, end: 199, conf: conf},
+		&Frag{ID: "b", uniqueID: "b150", start: 150, end: 250, conf: conf}, // another match against b
+		&Frag{ID: "a", uniqueID: "a0", start: 300, end: 399, conf: conf},   // a across the zero index
+	}
+	solutions := [][]*Frag{
+		[]*Frag{
+			&Frag{ID: "a", uniqueID: "a0", fragType: pcr, conf: conf},
+			&Frag{ID: "b", uniqueID: "b80", fragType: pcr, conf: conf},
+		},
+	}
+
+	want := `digraph assembly {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a\n1-100", color=red, penwidth=2];
+	n1 [label="b\n81-200", color=red, penwidth=2];
+	n2 [label="b\n151-251"];
+	n3 [label="a\n301-400", color=red, penwidth=2];
+	n0 -> n1 [label="pcr", style=solid, color=red, penwidth=2];
+	n0 -> n2 [label="synthesis", style=dashed];
+	n0 -> n3 [label="synthesis", style=dashed];
+	n1 -> n2 [label="pcr", style=solid];
+	n1 -> n3 [label="synthesis", style=dashed, color=red, penwidth=2];
+	n2 -> n3 [label="synthesis", style=dashed];
+}
+`
+	if got := graphDOT(frags, false, solutions); got != want {
+		t.Errorf("graphDOT() = %s, want %s", got, want)
+	}
+}
+
+func Test_BuildInfo_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		build BuildInfo
+		want  string
+	}{
+		{
+			"from make build",
+			BuildInfo{Version: "0.1.0", Commit: "1a2b3c4", BuildDate: "2020-01-01T20:41:00Z"},
+			"repp 0.1.0 (commit 1a2b3c4, built 2020-01-01T20:41:00Z)",
+		},
+		{
+			"from go build",
+			BuildInfo{Version: "0.1.0"},
+			"repp 0.1.0 (commit unknown, built unknown)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build.String(); got != tt.want {
+				t.Errorf("BuildInfo.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
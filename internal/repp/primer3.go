@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -40,6 +41,55 @@ type primer3 struct {
 	primer3ConfDir string
 }
 
+// primer3Tags are the primer3 settings that can be passed through to primer3, from the
+// pcr-primer3-settings setting or --primer3-settings. The others, like the product size
+// range and the primers' regions, are set from the fragment and its neighbors.
+var primer3Tags = map[string]bool{
+	"PRIMER_MIN_SIZE":              true,
+	"PRIMER_OPT_SIZE":              true,
+	"PRIMER_MAX_SIZE":              true,
+	"PRIMER_MIN_TM":                true,
+	"PRIMER_OPT_TM":                true,
+	"PRIMER_MAX_TM":                true,
+	"PRIMER_PAIR_MAX_DIFF_TM":      true,
+	"PRIMER_TM_FORMULA":            true,
+	"PRIMER_SALT_CORRECTIONS":      true,
+	"PRIMER_MIN_GC":                true,
+	"PRIMER_OPT_GC_PERCENT":        true,
+	"PRIMER_MAX_GC":                true,
+	"PRIMER_GC_CLAMP":              true,
+	"PRIMER_MAX_END_GC":            true,
+	"PRIMER_MAX_POLY_X":            true,
+	"PRIMER_MAX_NS_ACCEPTED":       true,
+	"PRIMER_MAX_END_STABILITY":     true,
+	"PRIMER_MAX_HAIRPIN_TH":        true,
+	"PRIMER_MAX_SELF_ANY":          true,
+	"PRIMER_MAX_SELF_END":          true,
+	"PRIMER_MAX_SELF_ANY_TH":       true,
+	"PRIMER_MAX_SELF_END_TH":       true,
+	"PRIMER_PAIR_MAX_COMPL_ANY":    true,
+	"PRIMER_PAIR_MAX_COMPL_END":    true,
+	"PRIMER_PAIR_MAX_COMPL_ANY_TH": true,
+	"PRIMER_PAIR_MAX_COMPL_END_TH": true,
+}
+
+// primer3Settings returns the honored primer3 settings passed through from the config,
+// by their upper case tags, and the sorted tags of those that are ignored.
+func primer3Settings(conf *config.Config) (settings map[string]string, ignored []string) {
+	settings = make(map[string]string)
+	for tag, value := range conf.PCRPrimer3Settings {
+		tag = strings.ToUpper(tag) // keys are lower case once read from a settings file
+		if !primer3Tags[tag] {
+			ignored = append(ignored, tag)
+			continue
+		}
+		settings[tag] = value
+	}
+	sort.Strings(ignored)
+
+	return
+}
+
 // newPrimer3 creates a primer3 struct from a fragment
 func newPrimer3(last, this, next *Frag, seq string, conf *config.Config) primer3 {
 	in, _ := ioutil.TempFile("", "primer3-in-*")
@@ -69,9 +119,11 @@ func (p *primer3) input(minHomology, maxHomology, maxEmbedLength, minLength, pcr
 	// with the neighboring fragment
 	p.shrink(p.last, p.f, p.next, maxHomology, minLength) // could skip passing as a param, but this is a bit easier to test
 
-	// calc the bps to add on the left and right side of this Frag
-	addLeft = p.bpToAdd(p.last, p.f)
-	addRight = p.bpToAdd(p.f, p.next)
+	// calc the bps to add on the left and right side of this Frag, this Frag is
+	// downstream of the last and upstream of the next
+	split := homologySplit(p.f.conf)
+	addLeft = p.bpToAdd(p.last, p.f, 1-split)
+	addRight = p.bpToAdd(p.f, p.next, split)
 
 	start := p.f.start
 	length := p.f.end - start + 1
@@ -81,6 +133,16 @@ func (p *primer3) input(minHomology, maxHomology, maxEmbedLength, minLength, pcr
 	primerOpt := 20
 	primerMax := 30 // defaults to 23
 
+	// or those passed through, the regions to pick primers from depend on them
+	passed, _ := primer3Settings(p.f.conf)
+	for tag, size := range map[string]*int{"PRIMER_MIN_SIZE": &primerMin, "PRIMER_OPT_SIZE": &primerOpt, "PRIMER_MAX_SIZE": &primerMax} {
+		if value, set := passed[tag]; set {
+			if *size, err = strconv.Atoi(value); err != nil {
+				return 0, 0, fmt.Errorf("failed to parse primer3 setting %s=%s: %v", tag, value, err)
+			}
+		}
+	}
+
 	// check whether we have wiggle room on the left or right hand sides to move the
 	// primers inward (let primer3 pick better primers)
 	//
@@ -144,8 +206,9 @@ func (p *primer3) shrink(last, f, next *Frag, maxHomology int, minLength int) *F
 }
 
 // bpToAdd returns the number of bp to add the end of the left Frag to create a junction
-// with the right Frag
-func (p *primer3) bpToAdd(left, right *Frag) int {
+// with the right Frag. share is the part of the junction's homology, 0 to 1, added by
+// this Frag's primer
+func (p *primer3) bpToAdd(left, right *Frag, share float64) int {
 	if !left.overlapsViaPCR(right) {
 		return 0 // we're going to synthesize there, don't add bp via PCR
 	}
@@ -154,21 +217,31 @@ func (p *primer3) bpToAdd(left, right *Frag) int {
 		return 0 // there is already enough overlap via PCR
 	}
 
-	minHomology := left.conf.FragmentsMinHomology
+	minHomology := left.overlapLength(right)
 	bpDist := left.distTo(right) + 1 // if there's a gap
 	if bpDist < 0 {
 		bpDist = 0
 	}
 
-	// this Frag will add half the homology to the last fragment
-	// eg: 5 bp distance leads to 2.5bp + ~10bp additonal
+	// this Frag will add its share of the homology to the last fragment
+	// eg: 5 bp distance leads to 2.5bp + ~10bp additonal with an even split
 	// eg: -10bp distance leads to ~0 bp additional:
 	// 		other Frag is responsible for all of it
-	b := math.Ceil(float64(minHomology) / float64(2))
+	b := math.Ceil(float64(minHomology) * share)
 
 	return bpDist + int(b)
 }
 
+// homologySplit returns the share of the homology added to a junction by PCR that's
+// in the upstream fragment's REV primer. It's 0.5, an even split, if the setting is
+// unset, and kept between 0 and 1.
+func homologySplit(conf *config.Config) float64 {
+	if conf == nil || conf.PCRHomologySplit == nil {
+		return 0.5
+	}
+	return math.Min(math.Max(*conf.PCRHomologySplit, 0), 1)
+}
+
 // buffer takes the dist from a one fragment to another and
 // returns the length of the "buffer" in which the primers can be optimized (let primer3 pick)
 //
@@ -214,6 +287,16 @@ func (p *primer3) settings(
 		"PRIMER_MAX_HAIRPIN_TH":                fmt.Sprintf("%f", p.f.conf.FragmentsMaxHairpinMelt), // defaults to 47.0
 		"PRIMER_MAX_POLY_X":                    "7",                                                 // defaults to 5
 		"PRIMER_PAIR_MAX_COMPL_ANY":            "13.0",                                              // defaults to 8.00
+		"PRIMER_SALT_MONOVALENT":               fmt.Sprintf("%f", p.f.conf.MonovalentConc()),        // mM
+		"PRIMER_SALT_DIVALENT":                 fmt.Sprintf("%f", p.f.conf.PCRDivalentConc),         // mM
+		"PRIMER_DNTP_CONC":                     fmt.Sprintf("%f", p.f.conf.PCRDNTPConc),             // mM
+		"PRIMER_DNA_CONC":                      fmt.Sprintf("%f", p.f.conf.PrimerConc()),            // nM
+	}
+
+	// settings passed through from the config take precedence over the defaults above
+	passed, _ := primer3Settings(p.f.conf)
+	for tag, value := range passed {
+		settings[tag] = value
 	}
 
 	// if there is room to optimize, we let primer3 pick the best primers available
@@ -361,6 +444,17 @@ func (p *primer3) parse(target string) (err error) {
 	return
 }
 
+// ntthalConcentrations returns ntthal's arguments for the PCR's cation, dNTP and
+// primer concentrations so its Tms match primer3's
+func ntthalConcentrations(c *config.Config) []string {
+	return []string{
+		"-mv", strconv.FormatFloat(c.MonovalentConc(), 'f', -1, 64), // mM
+		"-dv", strconv.FormatFloat(c.PCRDivalentConc, 'f', -1, 64), // mM
+		"-n", strconv.FormatFloat(c.PCRDNTPConc, 'f', -1, 64), // mM
+		"-d", strconv.FormatFloat(c.PrimerConc(), 'f', -1, 64), // nM
+	}
+}
+
 // hairpin finds the melting temperature of a hairpin in a sequence
 // returns 0 if there is none
 func hairpin(seq string, conf *config.Config) (melt float64) {
@@ -377,14 +471,14 @@ func hairpin(seq string, conf *config.Config) (melt float64) {
 	}
 
 	// see nnthal (no parameters) help. within primer3 distribution
-	ntthalCmd := exec.Command(
-		"ntthal",
+	args := append([]string{
 		"-a", "HAIRPIN",
 		"-r",       // temperature only
 		"-t", "50", // gibson assembly is at 50 degrees
 		"-s1", seq,
 		"-path", config.Primer3Config,
-	)
+	}, ntthalConcentrations(conf)...)
+	ntthalCmd := exec.Command("ntthal", args...)
 
 	ntthalOut, err := ntthalCmd.CombinedOutput()
 	if err != nil {
@@ -402,7 +496,90 @@ func hairpin(seq string, conf *config.Config) (melt float64) {
 	return temp
 }
 
-// reverseComplement returns the reverse complement of a sequence
+// selfPrimingEnd is the bp of a primer's 3' end that, if paired with its tail, the
+// polymerase can extend. A proofreading polymerase chews back a short unpaired overhang
+const selfPrimingEnd = 3
+
+// tailHairpin folds a primer, its tail and annealing region together, and returns the
+// ΔG (kcal/mol) and melting temperature of its hairpin. selfPriming is whether the
+// hairpin pairs the primer's 3' end with its tail. Primers longer than 60bp (max for
+// ntthal) are folded from their 3' end
+func tailHairpin(seq string, tailLength int, conf *config.Config) (dG, melt float64, selfPriming bool, err error) {
+	if len(seq) > 60 {
+		tailLength -= len(seq) - 60
+		seq = seq[len(seq)-60:]
+	}
+	if tailLength < 1 {
+		return 0, 0, false, nil
+	}
+
+	args := append([]string{
+		"-a", "HAIRPIN",
+		"-t", "50", // gibson assembly is at 50 degrees
+		"-s1", seq,
+		"-path", config.Primer3Config,
+	}, ntthalConcentrations(conf)...)
+	ntthalOut, err := exec.Command("ntthal", args...).CombinedOutput()
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to execute ntthal: -s1 %s -path %s: %v", seq, config.Primer3Config, err)
+	}
+
+	return parseTailHairpin(string(ntthalOut), tailLength)
+}
+
+// parseTailHairpin parses ntthal's structure output for a hairpin of a primer with a
+// tail of tailLength bp. The structure line marks the 5' and 3' sides of each pair
+// with '/' and '\', so the pairs nest like parentheses
+func parseTailHairpin(ntthalOut string, tailLength int) (dG, melt float64, selfPriming bool, err error) {
+	if strings.Contains(ntthalOut, "No secondary structure") {
+		return 0, 0, false, nil
+	}
+
+	structure := ""
+	for _, line := range strings.Split(ntthalOut, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if strings.HasPrefix(line, "Calculated") {
+			for _, field := range fields {
+				if strings.HasPrefix(field, "dG = ") {
+					dG, err = strconv.ParseFloat(strings.TrimPrefix(field, "dG = "), 64)
+				} else if strings.HasPrefix(field, "t = ") {
+					melt, err = strconv.ParseFloat(strings.TrimPrefix(field, "t = "), 64)
+				}
+				if err != nil {
+					return 0, 0, false, fmt.Errorf("failed to parse ntthal output %s: %v", line, err)
+				}
+			}
+		} else if fields[0] == "SEQ" && len(fields) > 1 {
+			structure = fields[1]
+		}
+	}
+	if structure == "" {
+		return 0, 0, false, fmt.Errorf("failed to parse ntthal output, no structure: %s", ntthalOut)
+	}
+
+	var opened []int // indexes of the 5' sides of pairs without a 3' side yet
+	for i, c := range structure {
+		switch c {
+		case '/':
+			opened = append(opened, i)
+		case '\\':
+			if len(opened) == 0 {
+				return 0, 0, false, fmt.Errorf("failed to parse ntthal structure %s", structure)
+			}
+			partner := opened[len(opened)-1]
+			opened = opened[:len(opened)-1]
+
+			if i >= len(structure)-selfPrimingEnd && partner < tailLength {
+				selfPriming = true
+			}
+		}
+	}
+
+	return dG / 1000, melt, selfPriming, nil // ntthal's ΔG is in cal/mol
+}
+
+// reverseComplement returns the reverse complement of a sequence.
+// Degenerate IUPAC codes, as in enzyme recognition sequences, are complemented too
 func reverseComplement(seq string) string {
 	seq = strings.ToUpper(seq)
 
@@ -411,6 +588,18 @@ func reverseComplement(seq string) string {
 		'T': 'A',
 		'G': 'C',
 		'C': 'G',
+		'M': 'K',
+		'K': 'M',
+		'R': 'Y',
+		'Y': 'R',
+		'W': 'W',
+		'S': 'S',
+		'H': 'D',
+		'D': 'H',
+		'V': 'B',
+		'B': 'V',
+		'N': 'N',
+		'X': 'X',
 		'^': '_',
 		'_': '^',
 	}
@@ -80,6 +80,27 @@ func Test_primer3_shrink(t *testing.T) {
 	}
 }
 
+func Test_primer3Settings(t *testing.T) {
+	conf := &config.Config{PCRPrimer3Settings: map[string]string{
+		"primer_opt_tm":             "62",
+		"PRIMER_MAX_POLY_X":         "4",
+		"PRIMER_PRODUCT_SIZE_RANGE": "100-300",
+		"SEQUENCE_TEMPLATE":         "ATGC",
+	}}
+
+	settings, ignored := primer3Settings(conf)
+
+	wantSettings := map[string]string{"PRIMER_OPT_TM": "62", "PRIMER_MAX_POLY_X": "4"}
+	if !reflect.DeepEqual(settings, wantSettings) {
+		t.Errorf("primer3Settings() settings = %v, want %v", settings, wantSettings)
+	}
+
+	wantIgnored := []string{"PRIMER_PRODUCT_SIZE_RANGE", "SEQUENCE_TEMPLATE"}
+	if !reflect.DeepEqual(ignored, wantIgnored) {
+		t.Errorf("primer3Settings() ignored = %v, want %v", ignored, wantIgnored)
+	}
+}
+
 func Test_bpToAdd(t *testing.T) {
 	c := config.New()
 	c.PCRMaxEmbedLength = 20
@@ -90,6 +111,7 @@ func Test_bpToAdd(t *testing.T) {
 	type args struct {
 		left  *Frag
 		right *Frag
+		share float64
 	}
 	tests := []struct {
 		name        string
@@ -109,6 +131,7 @@ func Test_bpToAdd(t *testing.T) {
 					end:   30,
 					conf:  c,
 				},
+				share: 0.5,
 			},
 			0,
 		},
@@ -125,6 +148,7 @@ func Test_bpToAdd(t *testing.T) {
 					end:   30,
 					conf:  c,
 				},
+				share: 0.5,
 			},
 			0,
 		},
@@ -141,6 +165,7 @@ func Test_bpToAdd(t *testing.T) {
 					end:   30,
 					conf:  c,
 				},
+				share: 0.5,
 			},
 			12,
 		},
@@ -157,19 +182,76 @@ func Test_bpToAdd(t *testing.T) {
 					end:   1050,
 					conf:  c,
 				},
+				share: 0.5,
 			},
 			0,
 		},
+		{
+			"upstream Frag adds all the homology",
+			args{
+				left: &Frag{
+					start: 0,
+					end:   10,
+					conf:  c,
+				},
+				right: &Frag{
+					start: 16,
+					end:   30,
+					conf:  c,
+				},
+				share: 1,
+			},
+			17,
+		},
+		{
+			"downstream Frag adds none of the homology",
+			args{
+				left: &Frag{
+					start: 0,
+					end:   10,
+					conf:  c,
+				},
+				right: &Frag{
+					start: 16,
+					end:   30,
+					conf:  c,
+				},
+				share: 0,
+			},
+			7,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if gotBpToAdd := p.bpToAdd(tt.args.left, tt.args.right); gotBpToAdd != tt.wantBpToAdd {
+			if gotBpToAdd := p.bpToAdd(tt.args.left, tt.args.right, tt.args.share); gotBpToAdd != tt.wantBpToAdd {
 				t.Errorf("bpToAdd() = %v, want %v", gotBpToAdd, tt.wantBpToAdd)
 			}
 		})
 	}
 }
 
+func Test_homologySplit(t *testing.T) {
+	split := func(share float64) *config.Config { return &config.Config{PCRHomologySplit: &share} }
+
+	tests := []struct {
+		name string
+		conf *config.Config
+		want float64
+	}{
+		{"no settings", nil, 0.5},
+		{"unset", &config.Config{}, 0.5},
+		{"all in the FWD primer", split(0), 0},
+		{"kept under 1", split(1.5), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := homologySplit(tt.conf); got != tt.want {
+				t.Errorf("homologySplit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_mutatePrimers(t *testing.T) {
 	type args struct {
 		n        *Frag
@@ -224,7 +306,8 @@ func Test_mutatePrimers(t *testing.T) {
 							start: 5,
 							end:   20,
 						},
-						Strand: true,
+						Strand:     true,
+						tailLength: 5,
 					},
 					Primer{
 						Seq: "AAGAATCGCCGTAGTA", //  rev comp TACTACGGCGATTCTT
@@ -232,7 +315,8 @@ func Test_mutatePrimers(t *testing.T) {
 							start: 30,
 							end:   45,
 						},
-						Strand: false,
+						Strand:     false,
+						tailLength: 6,
 					},
 				},
 			},
@@ -325,6 +409,13 @@ func Test_reverseComplement(t *testing.T) {
 			},
 			"ATG^_CAT",
 		},
+		{
+			"complements degenerate bases",
+			args{
+				seq: "RGGWCCYM",
+			},
+			"KRGGWCCY",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -349,12 +440,12 @@ func Test_hairpin(t *testing.T) {
 		wantMelt float64
 	}{
 		{
-			"find hairpin of ~75 degrees",
+			"find hairpin of ~82 degrees at the PCR's cation concentrations",
 			args{
 				"TGTGCACTCATCATCATCATCGGGGGGGGGGGGTGAACACTATCCCCCCCCCCCCCCA",
 				c,
 			},
-			75.0,
+			82.0,
 		},
 		{
 			"return 0 when no hairpin found",
@@ -370,7 +461,7 @@ func Test_hairpin(t *testing.T) {
 				"TGTGcactcatcatcaacacaactacgtcgatcagctacgatcgatcgatgctgatcgatatttatatcgagctagctacggatcatcGGGGGGGGGGGGTGAACACTATCCCCCCCCCCCCCCA",
 				c,
 			},
-			75.0,
+			82.0,
 		},
 	}
 	for _, tt := range tests {
@@ -381,3 +472,159 @@ func Test_hairpin(t *testing.T) {
 		})
 	}
 }
+
+func Test_ntthalConcentrations(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *config.Config
+		want []string
+	}{
+		{
+			"primer3 defaults",
+			&config.Config{PCRMonovalentConc: 50, PCRDivalentConc: 1.5, PCRDNTPConc: 0.6, PCRPrimerConc: 50},
+			[]string{"-mv", "50", "-dv", "1.5", "-n", "0.6", "-d", "50"},
+		},
+		{
+			"master mix without magnesium",
+			&config.Config{PCRMonovalentConc: 50, PCRDivalentConc: 0, PCRDNTPConc: 0.8, PCRPrimerConc: 500},
+			[]string{"-mv", "50", "-dv", "0", "-n", "0.8", "-d", "500"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ntthalConcentrations(tt.conf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ntthalConcentrations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tailHairpin(t *testing.T) {
+	c := config.New()
+
+	tests := []struct {
+		name            string
+		seq             string
+		tailLength      int
+		wantSelfPriming bool
+		wantHairpin     bool
+	}{
+		{
+			"tail pairs with the 3' end",
+			"GGGGCCATGCTTAGCAACGTCAGTACGTAGCTATGGCCCC",
+			8,
+			true,
+			true,
+		},
+		{
+			"primer without a tail",
+			"GGGGCCATGCTTAGCAACGTCAGTACGTAGCTATGGCCCC",
+			0,
+			false,
+			false,
+		},
+		{
+			"hairpin away from the 3' end",
+			"ACGTCAGTACGTAGCTAGCTTAGCAGGTCCATCGATCGAC",
+			12,
+			false,
+			true,
+		},
+		{
+			"no hairpin",
+			"TGTGCACTCATCATCCCCA",
+			4,
+			false,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dG, melt, selfPriming, err := tailHairpin(tt.seq, tt.tailLength, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if selfPriming != tt.wantSelfPriming {
+				t.Errorf("tailHairpin() selfPriming = %v, want %v", selfPriming, tt.wantSelfPriming)
+			}
+			if (dG < 0 && melt > 0) != tt.wantHairpin {
+				t.Errorf("tailHairpin() dG = %v, melt = %v, want a hairpin %v", dG, melt, tt.wantHairpin)
+			}
+		})
+	}
+}
+
+func Test_shiftTailHairpins(t *testing.T) {
+	c := config.New()
+
+	// the FWD primer's 7bp tail pairs with its 3' end
+	upstream := "TCGTGAGCATGG"
+	primer := "GGGCCATGCTTAGCAACGTCAGTACGTAGCTATGGCCC"
+	downstream := "ATCGATTAGCGCTAACGTCGATGACTAGCTAGCATCGACTAGCAGTCGAGCTA"
+	seq := upstream + primer + downstream
+	rev := reverseComplement(seq[len(seq)-20:])
+
+	newFrag := func() *Frag {
+		return &Frag{
+			ID:  "frag",
+			Seq: seq[len(upstream):],
+			Primers: []Primer{
+				{Seq: primer, Strand: true, Range: ranged{len(upstream), len(upstream) + len(primer) - 1}, tailLength: 7},
+				{Seq: rev, Strand: false, Range: ranged{len(seq) - 20, len(seq) - 1}},
+			},
+		}
+	}
+
+	// the upstream fragment overlaps the FWD primer's tail
+	last := &Frag{ID: "last", start: 0, end: len(upstream) + 6}
+
+	noShift := *c
+	noShift.PCRMaxTailShift = 0
+
+	maxHomology := *c
+	maxHomology.FragmentsMaxHomology = 7
+
+	tests := []struct {
+		name            string
+		conf            *config.Config
+		wantFWD         string
+		wantTailHairpin bool
+	}{
+		{
+			"lengthen the tail to remove the hairpin",
+			c,
+			"AGCATGG" + primer,
+			false,
+		},
+		{
+			"keep the hairpin's ΔG if the tail can't be shifted",
+			&noShift,
+			primer,
+			true,
+		},
+		{
+			"keep the hairpin's ΔG if the junction is at the max homology",
+			&maxHomology,
+			primer,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := shiftTailHairpins(newFrag(), last, nil, seq, tt.conf)
+
+			if f.Primers[0].Seq != tt.wantFWD {
+				t.Errorf("shiftTailHairpins() FWD = %s, want %s", f.Primers[0].Seq, tt.wantFWD)
+			}
+			if (f.Primers[0].TailHairpin < 0) != tt.wantTailHairpin {
+				t.Errorf("shiftTailHairpins() TailHairpin = %v, want a hairpin %v", f.Primers[0].TailHairpin, tt.wantTailHairpin)
+			}
+			if f.Primers[1].Seq != rev || f.Primers[1].TailHairpin != 0 {
+				t.Errorf("shiftTailHairpins() changed the REV primer without a tail: %+v", f.Primers[1])
+			}
+			if wantPCRSeq := seq[f.Primers[0].Range.start:]; f.PCRSeq != wantPCRSeq {
+				t.Errorf("shiftTailHairpins() PCRSeq = %s, want %s", f.PCRSeq, wantPCRSeq)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package repp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+// layoutRow is a fragment of a user's layout: the region of a source to amplify.
+type layoutRow struct {
+	// source is the ID of the template in the fragment databases, or a local file
+	source string
+
+	// start of the region on the source (1-based)
+	start int
+
+	// end of the region on the source (1-based and inclusive)
+	end int
+
+	// forward is false if the fragment is the source's bottom strand
+	forward bool
+}
+
+// PrimersOnly designs primers for a layout of fragments that the user provides. BLAST
+// and the search for assemblies are skipped: each fragment in the layout is amplified
+// from its source and the junctions between them are checked.
+func PrimersOnly(flags *Flags, conf *config.Config) [][]*Frag {
+	start := time.Now()
+
+	fragments, err := read(flags.in, false, flags.stripInvalid)
+	if err != nil {
+		stderr.Fatalf("failed to read target sequence from %s: %v", flags.in, err)
+	}
+	target := fragments[0]
+
+	rows, err := readLayout(flags.primersOnly)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	// gather each fragment's source
+	sources := make(map[string]*Frag)
+	for _, row := range rows {
+		if _, gathered := sources[row.source]; gathered {
+			continue
+		}
+
+		source, err := queryDatabases(row.source, flags.dbs)
+		if err != nil {
+			stderr.Fatalln(err)
+		}
+		if source.fragType == circular {
+			source.Seq = source.Seq[:len(source.Seq)/2] // undo the doubling of circular sequences
+		}
+		sources[row.source] = source
+	}
+
+	frags, err := layoutFrags(target.Seq, rows, sources, conf)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	setBuildCache(frags, newBuildCache())
+	a := assembly{frags: frags}
+	solution, err := a.fill(strings.ToUpper(target.Seq), conf)
+	if err != nil {
+		err = fmt.Errorf("failed to design primers for the layout in %s: %v", flags.primersOnly, err)
+		stderr.Fatalln(inPhase(phasePrimer, err, map[string]interface{}{"layout": flags.primersOnly}))
+	}
+
+	solutions := [][]*Frag{solution}
+	if _, err = writeJSON(
+		flags.out,
+		target.ID,
+		target.readSeq(),
+		0,
+		solutions,
+		len(target.Seq),
+		time.Since(start).Seconds(),
+		flags.backboneMeta,
+		flags.dbs,
+		conf,
+	); err != nil {
+		stderr.Fatalln(err)
+	}
+
+	if flags.outputFormat == "benchling" {
+		if err = writeBenchling(flags.out, target.Seq, solutions, flags.backboneMeta); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.outputFormat == "sbol" {
+		if err = writeSBOL(flags.out, target.ID, target.Seq, solutions); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	return solutions
+}
+
+// readLayout reads a layout file. Each line is a fragment's source, start, end and strand
+// (1 or -1) separated by tabs or commas, in the order they're assembled. Coordinates are
+// 1-based and inclusive. Blank lines and those starting with "#" are skipped.
+func readLayout(filename string) (rows []layoutRow, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cols := strings.FieldsFunc(line, func(c rune) bool { return c == '\t' || c == ',' })
+		if len(cols) != 4 {
+			return nil, fmt.Errorf("line %d of layout %s has %d columns, expected source, start, end and strand", lineNumber, filename, len(cols))
+		}
+
+		row := layoutRow{source: strings.TrimSpace(cols[0])}
+		if row.start, err = strconv.Atoi(strings.TrimSpace(cols[1])); err != nil {
+			return nil, fmt.Errorf("failed to parse start on line %d of layout %s: %v", lineNumber, filename, err)
+		}
+		if row.end, err = strconv.Atoi(strings.TrimSpace(cols[2])); err != nil {
+			return nil, fmt.Errorf("failed to parse end on line %d of layout %s: %v", lineNumber, filename, err)
+		}
+
+		switch strings.TrimSpace(cols[3]) {
+		case "1", "+1", "+":
+			row.forward = true
+		case "-1", "-":
+			row.forward = false
+		default:
+			return nil, fmt.Errorf("failed to parse strand on line %d of layout %s, expected 1 or -1", lineNumber, filename)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no fragments in layout %s", filename)
+	}
+
+	return rows, scanner.Err()
+}
+
+// layoutFrags returns a PCR fragment for each row of the layout. Each fragment is placed
+// on the target after the one before it. An error is returned if the fragment isn't
+// in its source or on the target.
+func layoutFrags(target string, rows []layoutRow, sources map[string]*Frag, conf *config.Config) (frags []*Frag, err error) {
+	target = strings.ToUpper(target)
+	doubled := target + target // fragments may span the zero index
+
+	for i, row := range rows {
+		source := sources[row.source]
+		sourceSeq := strings.ToUpper(source.Seq)
+		if row.start < 1 || row.start > len(sourceSeq) || row.end < 1 || row.end > len(sourceSeq) {
+			return nil, fmt.Errorf("fragment %s:%d-%d is outside the %d bp source", row.source, row.start, row.end, len(sourceSeq))
+		}
+
+		// across the zero index of a circular source if the end's before the start
+		seq, sourceEnd := "", row.end-1
+		if row.end < row.start {
+			seq = sourceSeq[row.start-1:] + sourceSeq[:row.end]
+			sourceEnd += len(sourceSeq)
+		} else {
+			seq = sourceSeq[row.start-1 : row.end]
+		}
+		if !row.forward {
+			seq = reverseComplement(seq)
+		}
+
+		// place it after the last fragment, within one length of the target from the first
+		from := 0
+		if i > 0 {
+			from = frags[i-1].start + 1
+		}
+		index := strings.Index(doubled[from:], seq)
+		if index < 0 || (i > 0 && from+index >= frags[0].start+len(target)) {
+			return nil, fmt.Errorf("failed to find fragment %s:%d-%d in the target after %s", row.source, row.start, row.end, previousID(frags))
+		}
+		start := from + index
+
+		frags = append(frags, &Frag{
+			ID:       row.source,
+			uniqueID: row.source + strconv.Itoa(start%len(target)),
+			Seq:      seq,
+			start:    start,
+			end:      start + len(seq) - 1,
+			db:       source.db,
+			URL:      parseURL(row.source, source.db),
+			fragType: pcr,
+			conf:     conf,
+			source: match{
+				entry:        row.source,
+				queryStart:   start,
+				queryEnd:     start + len(seq) - 1,
+				subjectStart: row.start - 1,
+				subjectEnd:   sourceEnd,
+				forward:      row.forward,
+				db:           source.db,
+			},
+		})
+	}
+
+	return frags, nil
+}
+
+// previousID returns the ID of the last fragment or "the start" if there is none.
+func previousID(frags []*Frag) string {
+	if len(frags) == 0 {
+		return "the start"
+	}
+	return frags[len(frags)-1].ID
+}
@@ -0,0 +1,146 @@
+package repp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_readLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "layout-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		contents string
+		wantRows []layoutRow
+		wantErr  bool
+	}{
+		{
+			"tabs and commas",
+			"# source\tstart\tend\tstrand\npSB1A3\t1\t2000\t1\n\nBBa_E0040,20,740,-1\n",
+			[]layoutRow{
+				layoutRow{source: "pSB1A3", start: 1, end: 2000, forward: true},
+				layoutRow{source: "BBa_E0040", start: 20, end: 740, forward: false},
+			},
+			false,
+		},
+		{
+			"missing strand",
+			"pSB1A3\t1\t2000\n",
+			nil,
+			true,
+		},
+		{
+			"unknown strand",
+			"pSB1A3\t1\t2000\tfwd\n",
+			nil,
+			true,
+		},
+		{
+			"no fragments",
+			"# just a comment\n",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.name+".tsv")
+			if err := ioutil.WriteFile(filename, []byte(tt.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			gotRows, err := readLayout(filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readLayout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotRows, tt.wantRows) {
+				t.Errorf("readLayout() = %+v, want %+v", gotRows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func Test_layoutFrags(t *testing.T) {
+	conf := &config.Config{}
+	target := "AAAACCCCGGGGTTTTACGTACGTGCATGCAT"
+	sources := map[string]*Frag{
+		"s1": &Frag{ID: "s1", Seq: "TTTAAAACCCCGGGGTTT"},   // target 0-13 on its top strand
+		"s2": &Frag{ID: "s2", Seq: "ATGCATGCACGTACGTAAAA"}, // target 12-31 on its bottom strand
+		"s3": &Frag{ID: "s3", Seq: "AAAACCTTGCAT"},         // target 28-37, across its zero index
+	}
+
+	type wantFrag struct {
+		seq        string
+		start, end int
+	}
+	tests := []struct {
+		name      string
+		rows      []layoutRow
+		wantFrags []wantFrag
+		wantErr   bool
+	}{
+		{
+			"fragments from both strands",
+			[]layoutRow{
+				layoutRow{source: "s1", start: 4, end: 17, forward: true},
+				layoutRow{source: "s2", start: 1, end: 20, forward: false},
+			},
+			[]wantFrag{
+				wantFrag{"AAAACCCCGGGGTT", 0, 13},
+				wantFrag{"TTTTACGTACGTGCATGCAT", 12, 31},
+			},
+			false,
+		},
+		{
+			"fragment across the source's zero index",
+			[]layoutRow{
+				layoutRow{source: "s3", start: 9, end: 6, forward: true},
+			},
+			[]wantFrag{
+				wantFrag{"GCATAAAACC", 28, 37},
+			},
+			false,
+		},
+		{
+			"fragment not in the target",
+			[]layoutRow{
+				layoutRow{source: "s1", start: 1, end: 18, forward: false},
+			},
+			nil,
+			true,
+		},
+		{
+			"fragment outside its source",
+			[]layoutRow{
+				layoutRow{source: "s1", start: 4, end: 40, forward: true},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFrags, err := layoutFrags(target, tt.rows, sources, conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("layoutFrags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for i, f := range gotFrags {
+				if f.Seq != tt.wantFrags[i].seq || f.start != tt.wantFrags[i].start || f.end != tt.wantFrags[i].end {
+					t.Errorf("layoutFrags() frag %d = %s %d-%d, want %+v", i, f.Seq, f.start, f.end, tt.wantFrags[i])
+				}
+			}
+		})
+	}
+}
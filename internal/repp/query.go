@@ -0,0 +1,154 @@
+package repp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// sourceUse is a fragment of a solution in an output file that's from a source part.
+type sourceUse struct {
+	// filename of the output
+	filename string
+
+	// target of the output
+	target string
+
+	// solution is the 1-based index of the solution in the output
+	solution int
+
+	// frag is the fragment from the source part
+	frag *Frag
+
+	// start and end are the 1-based junctions of the fragment on the target, end may be
+	// before start if the fragment is across the zero index. 0 if it isn't found on the target
+	start, end int
+}
+
+// QueryCmd logs the fragments, in the solutions of the output files, that are from a
+// source part. For finding every design affected by a part that's found to be problematic.
+func QueryCmd(cmd *cobra.Command, args []string) {
+	source, _ := cmd.Flags().GetString("source")
+	if source == "" {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass a --source part to query for.")
+	}
+	if len(args) < 1 {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass output JSON files to query.")
+	}
+
+	repo, entry := parseSource(source)
+
+	var uses []sourceUse
+	for _, filename := range args {
+		out, err := readOutput(filename)
+		if err != nil {
+			stderr.Fatalln(err)
+		}
+		uses = append(uses, querySource(filename, out, repo, entry)...)
+	}
+
+	if len(uses) == 0 {
+		stderr.Printf("no solutions use %s\n", source)
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+	fmt.Fprintf(tw, "output\ttarget\tsolution\tfragment\ttype\tstart\tend\t\n")
+	for _, u := range uses {
+		start, end := "-", "-"
+		if u.start > 0 {
+			start, end = fmt.Sprint(u.start), fmt.Sprint(u.end)
+		}
+
+		name := u.frag.ID
+		if name == "" {
+			name = u.frag.URL
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n", u.filename, u.target, u.solution, name, u.frag.Type, start, end)
+	}
+	tw.Flush()
+}
+
+// parseSource splits a source part, like "addgene:12345", into its repository, one of
+// "addgene", "igem" or "dnasu", and its entry. Parts without a known repository are
+// only an entry, ex: "pSB1A3".
+func parseSource(source string) (repo, entry string) {
+	source = strings.TrimSpace(source)
+	if colon := strings.Index(source, ":"); colon > 0 {
+		switch prefix := strings.ToLower(source[:colon]); prefix {
+		case "addgene", "igem", "dnasu":
+			return prefix, strings.TrimSpace(source[colon+1:])
+		}
+	}
+
+	return "", source
+}
+
+// querySource returns the fragments of the output's solutions from the source part, with
+// their junctions on the target.
+func querySource(filename string, out Output, repo, entry string) (uses []sourceUse) {
+	for i, s := range out.Solutions {
+		for _, f := range s.Fragments {
+			if !fromSource(f, repo, entry) {
+				continue
+			}
+
+			start, end := fragJunctions(f, out.TargetSeq)
+			uses = append(uses, sourceUse{
+				filename: filename,
+				target:   out.Target,
+				solution: i + 1,
+				frag:     f,
+				start:    start,
+				end:      end,
+			})
+		}
+	}
+
+	return
+}
+
+// fromSource returns whether the fragment is from the source part: by the URL of its
+// repository's page, if it has one, or its ID or the ID of its template.
+func fromSource(f *Frag, repo, entry string) bool {
+	if entry == "" {
+		return false
+	}
+
+	if repo != "" {
+		if url := parseURL(entry, repo); url != "" && f.URL == url {
+			return true
+		}
+	}
+
+	return f.ID == entry || f.SourceID == entry
+}
+
+// fragJunctions returns the 1-based start and end of the fragment, with the bp added by
+// its primers, on the circular target. 0, 0 if it isn't found.
+func fragJunctions(f *Frag, targetSeq string) (start, end int) {
+	seq := f.PCRSeq
+	if seq == "" {
+		seq = f.Seq
+	}
+
+	target := strings.ToUpper(targetSeq)
+	if seq == "" || target == "" || len(seq) > len(target) {
+		return 0, 0
+	}
+
+	index := strings.Index(target+target, strings.ToUpper(seq))
+	if index < 0 {
+		return 0, 0
+	}
+
+	start = index%len(target) + 1
+	end = (index+len(seq)-1)%len(target) + 1
+	return start, end
+}
@@ -0,0 +1,98 @@
+package repp
+
+import (
+	"testing"
+)
+
+func Test_parseSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		wantRepo  string
+		wantEntry string
+	}{
+		{"addgene entry", "addgene:12345", "addgene", "12345"},
+		{"repository is case insensitive", "iGEM:BBa_E0040", "igem", "BBa_E0040"},
+		{"no repository", "pSB1A3", "", "pSB1A3"},
+		{"unknown repository is part of the entry", "lab:pSB1A3", "", "lab:pSB1A3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRepo, gotEntry := parseSource(tt.source)
+			if gotRepo != tt.wantRepo || gotEntry != tt.wantEntry {
+				t.Errorf("parseSource() = %v, %v, want %v, %v", gotRepo, gotEntry, tt.wantRepo, tt.wantEntry)
+			}
+		})
+	}
+}
+
+func Test_querySource(t *testing.T) {
+	out := Output{
+		Target:    "target",
+		TargetSeq: "AAAAACCCCCGGGGGTTTTT",
+		Solutions: []Solution{
+			{
+				Fragments: []*Frag{
+					{ID: "12345", Type: "pcr", URL: "https://www.addgene.org/12345/", PCRSeq: "CCCCCGGGGG"},
+					{ID: "12345-12345-synthesis-1", Type: "synthetic", Seq: "GGGGGTTTTTAAAAACCCCC"},
+				},
+			},
+			{
+				Fragments: []*Frag{
+					{ID: "12345.2", Type: "pcr", URL: "https://www.addgene.org/12345/", PCRSeq: "TTTTTAAAAA"},
+					{ID: "BBa_E0040", Type: "pcr", SourceID: "BBa_E0040", Seq: "AAAAACCCCCGGGGGTTTTT"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		repo, entry   string
+		wantIDs       []string
+		wantSolutions []int
+		wantRanges    [][2]int
+	}{
+		{
+			"addgene part by its URL, across the zero index",
+			"addgene",
+			"12345",
+			[]string{"12345", "12345.2"},
+			[]int{1, 2},
+			[][2]int{{6, 15}, {16, 5}},
+		},
+		{
+			"part by its template's ID",
+			"",
+			"BBa_E0040",
+			[]string{"BBa_E0040"},
+			[]int{2},
+			[][2]int{{1, 20}},
+		},
+		{
+			"unused part",
+			"addgene",
+			"54321",
+			nil,
+			nil,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uses := querySource("target.output.json", out, tt.repo, tt.entry)
+			if len(uses) != len(tt.wantIDs) {
+				t.Fatalf("querySource() = %d uses, want %d", len(uses), len(tt.wantIDs))
+			}
+
+			for i, u := range uses {
+				if u.frag.ID != tt.wantIDs[i] || u.solution != tt.wantSolutions[i] {
+					t.Errorf("querySource() use %d = %s in solution %d, want %s in %d", i, u.frag.ID, u.solution, tt.wantIDs[i], tt.wantSolutions[i])
+				}
+				if u.start != tt.wantRanges[i][0] || u.end != tt.wantRanges[i][1] {
+					t.Errorf("querySource() use %d = %d-%d, want %d-%d", i, u.start, u.end, tt.wantRanges[i][0], tt.wantRanges[i][1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package repp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+// ReconstructCmd logs, as FASTA, the plasmid assembled from a solution in an output
+// JSON file. The fragments are joined at their overlaps. It fails if the plasmid isn't
+// the output's target.
+func ReconstructCmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.Help()
+		stderr.Fatalln("\nmust pass an output JSON file to reconstruct.")
+	}
+
+	index, err := cmd.Flags().GetInt("solution")
+	if err != nil {
+		index = 1
+	}
+	out, _ := cmd.Flags().GetString("out")
+
+	output, err := readOutput(args[0])
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+	if index < 1 || index > len(output.Solutions) {
+		stderr.Fatalf("no solution %d in %s, it has %d", index, args[0], len(output.Solutions))
+	}
+
+	solution := output.Solutions[index-1]
+	seq, err := reconstruct(solution.Fragments, config.New().FragmentsMinHomology)
+	if err != nil {
+		stderr.Fatalf("failed to reconstruct solution %d of %s: %v", index, args[0], err)
+	}
+
+	fasta := fmt.Sprintf(">%s solution %d\n%s\n", output.Target, index, seq)
+	if out == "" {
+		fmt.Print(fasta)
+	} else if err = ioutil.WriteFile(out, []byte(fasta), 0644); err != nil {
+		stderr.Fatalf("failed to write %s: %v", out, err)
+	}
+
+	if output.TargetSeq != "" && !isRotation(seq, mutate(output.TargetSeq, solution.Fragments)) {
+		stderr.Fatalf(
+			"the %d bp plasmid of solution %d isn't the %d bp target %s",
+			len(seq), index, len(output.TargetSeq), output.Target,
+		)
+	}
+}
+
+// mutate returns the target with the substitutions made by the fragments' mutations,
+// the bp changed to remove enzymes' sites from synthetic fragments.
+func mutate(target string, frags []*Frag) string {
+	mutated := []byte(strings.ToUpper(target))
+	for _, f := range frags {
+		for _, m := range f.Mutations {
+			if m.Index >= 1 && m.Index <= len(mutated) && len(m.To) == 1 {
+				mutated[m.Index-1] = strings.ToUpper(m.To)[0]
+			}
+		}
+	}
+	return string(mutated)
+}
+
+// reconstruct returns the circular plasmid made by joining each fragment to the next,
+// and the last to the first, at their longest overlap. Overlaps are at least minHomology
+// bp, or a single bp if either fragment is a digested backbone with overhangs. The
+// sequence is from the start of the first fragment.
+func reconstruct(frags []*Frag, minHomology int) (string, error) {
+	if len(frags) == 0 {
+		return "", fmt.Errorf("no fragments")
+	}
+
+	// a lone plasmid is the whole sequence, without an overlap to itself
+	if len(frags) == 1 && frags[0].Type == circular.String() {
+		return strings.ToUpper(frags[0].Seq), nil
+	}
+
+	seqs := make([]string, len(frags))
+	for i, f := range frags {
+		seqs[i] = strings.ToUpper(f.Seq)
+		if f.PCRSeq != "" {
+			seqs[i] = strings.ToUpper(f.PCRSeq)
+		}
+	}
+
+	var sb strings.Builder
+	for i, f := range frags {
+		j := (i + 1) % len(frags)
+		next := frags[j]
+
+		min := minHomology
+		if len(f.Overhangs) > 0 || len(next.Overhangs) > 0 {
+			min = 1
+		}
+
+		overlap := longestOverlap(seqs[i], seqs[j], min)
+		if overlap < 0 {
+			return "", fmt.Errorf("no overlap of at least %d bp between %s and %s", min, diffName(f), diffName(next))
+		}
+		sb.WriteString(seqs[i][:len(seqs[i])-overlap])
+	}
+
+	return sb.String(), nil
+}
+
+// longestOverlap returns the length of the longest end of the first sequence that's the
+// start of the second, at least min bp and shorter than either. -1 if there's none.
+func longestOverlap(first, second string, min int) int {
+	longest := len(first) - 1
+	if len(second)-1 < longest {
+		longest = len(second) - 1
+	}
+
+	for length := longest; length >= min && length > 0; length-- {
+		if first[len(first)-length:] == second[:length] {
+			return length
+		}
+	}
+
+	return -1
+}
+
+// isRotation returns whether the circular sequences are the same, from any start.
+func isRotation(seq, target string) bool {
+	seq, target = strings.ToUpper(seq), strings.ToUpper(target)
+	return len(seq) == len(target) && strings.Contains(target+target, seq)
+}
@@ -0,0 +1,96 @@
+package repp
+
+import "testing"
+
+func Test_reconstruct(t *testing.T) {
+	tests := []struct {
+		name        string
+		frags       []*Frag
+		minHomology int
+		want        string
+		wantErr     bool
+	}{
+		{
+			"joined at overlaps, with the last to the first",
+			[]*Frag{
+				{ID: "1", Type: "pcr", Seq: "GGGG", PCRSeq: "ccAAAAACCCCC"},
+				{ID: "2", Type: "synthetic", Seq: "CCCCCTTTTTGGGG"},
+				{ID: "3", Type: "pcr", PCRSeq: "TTTGGGGCCAAA"},
+			},
+			4,
+			"CCAAAAACCCCCTTTTTGGGG",
+			false,
+		},
+		{
+			"lone plasmid",
+			[]*Frag{{ID: "1", Type: "plasmid", Seq: "atgcATGC"}},
+			4,
+			"ATGCATGC",
+			false,
+		},
+		{
+			"overlap under the min homology",
+			[]*Frag{
+				{ID: "1", Type: "pcr", PCRSeq: "AAAAACCC"},
+				{ID: "2", Type: "pcr", PCRSeq: "CCCTTTTTAAA"},
+			},
+			4,
+			"",
+			true,
+		},
+		{
+			"single bp overhangs of a digested backbone",
+			[]*Frag{
+				{ID: "1", Type: "linear", Seq: "AATTCGGGGCTGCA", Overhangs: []string{"AATT", "TGCA"}},
+				{ID: "2", Type: "pcr", PCRSeq: "TGCATTTTTAATT"},
+			},
+			20,
+			"AATTCGGGGCTGCATTTTT",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reconstruct(tt.frags, tt.minHomology)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("reconstruct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("reconstruct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		seq    string
+		target string
+		want   bool
+	}{
+		{"same", "ATGCAA", "atgcaa", true},
+		{"rotated", "CAAATG", "ATGCAA", true},
+		{"different", "CAAATC", "ATGCAA", false},
+		{"longer", "ATGCAAATGCAA", "ATGCAA", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRotation(tt.seq, tt.target); got != tt.want {
+				t.Errorf("isRotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mutate(t *testing.T) {
+	frags := []*Frag{
+		&Frag{ID: "a"},
+		&Frag{ID: "b", Mutations: []Mutation{Mutation{Index: 1, From: "G", To: "A"}, Mutation{Index: 6, From: "C", To: "t"}}},
+	}
+
+	if got := mutate("gaattcgg", frags); got != "AAATTTGG" {
+		t.Errorf("mutate() = %s, want AAATTTGG", got)
+	}
+}
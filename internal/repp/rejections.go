@@ -0,0 +1,141 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+const (
+	// rejectFragmentCount is for assemblies that circularized with more than the max fragments
+	rejectFragmentCount = "fragment-count"
+
+	// rejectDuplicateJunction is for assemblies with unintended homology between fragments
+	rejectDuplicateJunction = "duplicate-junction"
+
+	// rejectJunction is for assemblies with fragments that won't anneal to their neighbors
+	rejectJunction = "junction"
+
+	// rejectPrimers is for assemblies with a PCR fragment that primers couldn't be made for
+	rejectPrimers = "primers"
+
+	// rejectOffTarget is for assemblies with primers that bind elsewhere in their source
+	rejectOffTarget = "off-target"
+
+	// rejectCost is for assemblies that cost more than a solution with as few, or fewer, fragments
+	rejectCost = "cost"
+
+	// rejectSynthFraction is for assemblies that synthesize more than the synthetic-max-fraction
+	rejectSynthFraction = "synthetic-max-fraction"
+
+	// rejectShort is for assemblies with a fragment under the min-fragment-length
+	rejectShort = "min-fragment-length"
+
+	// rejectPenalty is for assemblies that tied another's cost but had a higher primer3 penalty
+	rejectPenalty = "primer3-penalty"
+)
+
+var (
+	// rejected, the assemblies discarded in the search for solutions during each build,
+	// by the build's reject log. Targets of a batch have their own reject logs
+	rejected = make(map[string][]rejection)
+
+	// rejectedMu guards rejected, for the targets of a batch built concurrently
+	rejectedMu sync.Mutex
+)
+
+// rejection is an assembly discarded in the search for solutions and why.
+type rejection struct {
+	// Fragments are the IDs of the assembly's fragments, in order
+	Fragments []string `json:"fragments"`
+
+	// Count is the estimated number of fragments, including synthetic ones
+	Count int `json:"count"`
+
+	// Cost is the estimated, or filled, cost of the assembly
+	Cost float64 `json:"cost"`
+
+	// Reason is why the assembly was discarded, ex: "off-target"
+	Reason string `json:"reason"`
+
+	// Detail is a description of the failure, ex: the primers with an off-target
+	Detail string `json:"detail,omitempty"`
+}
+
+// rejectError is a failure to fill an assembly with the reason it's rejected.
+type rejectError struct {
+	// reason is why the assembly was rejected, one of the reject constants
+	reason string
+
+	// err is the failure
+	err error
+}
+
+// Error returns the failure's message.
+func (e *rejectError) Error() string {
+	return e.err.Error()
+}
+
+// rejectReason returns the reason of a rejectError, or the fallback for other errors.
+func rejectReason(err error, fallback string) string {
+	if re, ok := err.(*rejectError); ok {
+		return re.reason
+	}
+	return fallback
+}
+
+// resetRejections clears the assemblies rejected during a prior build with the reject log.
+func resetRejections(rejectLog string) {
+	rejectedMu.Lock()
+	defer rejectedMu.Unlock()
+	delete(rejected, rejectLog)
+}
+
+// reject records that the assembly was discarded and why. It's logged with --verbose
+// and only recorded if it's logged or there's a --reject-log to write it to.
+func (a *assembly) reject(count int, cost float64, reason, detail string, conf *config.Config) {
+	if conf == nil || (conf.RejectLog == "" && !conf.Verbose) {
+		return
+	}
+
+	r := rejection{Count: count, Cost: cost, Reason: reason, Detail: detail}
+	for _, f := range a.frags {
+		r.Fragments = append(r.Fragments, f.ID)
+	}
+	rejectedMu.Lock()
+	rejected[conf.RejectLog] = append(rejected[conf.RejectLog], r)
+	rejectedMu.Unlock()
+
+	if conf.Verbose {
+		fmt.Printf("rejected %s (%d fragments, %s): %s", strings.Join(r.Fragments, ", "), count, conf.FormatCost(cost), reason)
+		if detail != "" {
+			fmt.Printf(", %s", detail)
+		}
+		fmt.Println()
+	}
+}
+
+// writeRejectLog writes the rejected assemblies to the file, one JSON object per line.
+func writeRejectLog(filename string) error {
+	rejectedMu.Lock()
+	defer rejectedMu.Unlock()
+
+	var sb strings.Builder
+	for _, r := range rejected[filename] {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to serialize rejected assembly: %v", err)
+		}
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+
+	if err := ioutil.WriteFile(filename, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write reject log %s: %v", filename, err)
+	}
+	return nil
+}
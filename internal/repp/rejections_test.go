@@ -0,0 +1,103 @@
+package repp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_rejectReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"reason of a rejectError",
+			&rejectError{rejectOffTarget, fmt.Errorf("found a mismatching sequence")},
+			rejectOffTarget,
+		},
+		{
+			"fallback for other errors",
+			fmt.Errorf("failed to execute primer3"),
+			rejectPrimers,
+		},
+		{
+			"fallback for nil",
+			nil,
+			rejectPrimers,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rejectReason(tt.err, rejectPrimers); got != tt.want {
+				t.Errorf("rejectReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeRejectLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reject-log-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer resetRejections("")
+
+	a := assembly{frags: []*Frag{{ID: "pSB1A3"}, {ID: "BBa_E0040"}}}
+
+	// nothing is recorded without a reject log or --verbose
+	resetRejections("")
+	a.reject(2, 95, rejectCost, "", &config.Config{})
+	if len(rejected) > 0 {
+		t.Errorf("reject() recorded %d rejections without a reject log", len(rejected))
+	}
+
+	conf := &config.Config{RejectLog: filepath.Join(dir, "rejects.jsonl")}
+	defer resetRejections(conf.RejectLog)
+	a.reject(2, 95, rejectCost, "", conf)
+	a.reject(3, 120.5, rejectOffTarget, "found a mismatching sequence", conf)
+	if err := writeRejectLog(conf.RejectLog); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := ioutil.ReadFile(conf.RejectLog)
+	want := `{"fragments":["pSB1A3","BBa_E0040"],"count":2,"cost":95,"reason":"cost"}` + "\n" +
+		`{"fragments":["pSB1A3","BBa_E0040"],"count":3,"cost":120.5,"reason":"off-target","detail":"found a mismatching sequence"}` + "\n"
+	if string(got) != want {
+		t.Errorf("writeRejectLog() = %q, want %q", got, want)
+	}
+}
+
+func Test_reject_concurrent(t *testing.T) {
+	a := assembly{frags: []*Frag{{ID: "pSB1A3"}, {ID: "BBa_E0040"}}}
+	logs := []string{"gfp.rejects.jsonl", "rfp.rejects.jsonl"}
+
+	var wg sync.WaitGroup
+	for _, log := range logs {
+		resetRejections(log)
+		defer resetRejections(log)
+
+		wg.Add(1)
+		go func(conf *config.Config) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				a.reject(2, 95, rejectCost, "", conf)
+			}
+		}(&config.Config{RejectLog: log})
+	}
+	wg.Wait()
+
+	// each target of a batch keeps its own rejections
+	for _, log := range logs {
+		if got := len(rejected[log]); got != 100 {
+			t.Errorf("reject() recorded %d rejections for %s, want 100", got, log)
+		}
+	}
+}
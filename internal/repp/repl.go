@@ -0,0 +1,222 @@
+package repp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+// replHelp lists the commands of the repl.
+const replHelp = `commands:
+	run                     design the target with the current settings
+	set [setting] [value]   change a setting, by its name in the settings file
+	set backbone [name]     change the backbone
+	set enzymes [names]     replace the comma separated enzymes that linearize the backbone
+	show [setting]          log a setting, or the target, backbone and enzymes
+	help                    log these commands
+	quit                    exit the repl
+ex: set fragments-min-junction-length 25
+`
+
+// repl is an interactive session for iterating on the design of a single target.
+// The target and databases are loaded once and BLAST matches are reused between runs.
+type repl struct {
+	// flags of the session, the backbone is replaced when it or the enzymes change
+	flags *Flags
+
+	// conf is the settings of the next run
+	conf *config.Config
+
+	// backbone is the name of the backbone in the dbs
+	backbone string
+
+	// enzymes are the names of the enzymes that linearize the backbone
+	enzymes []string
+
+	// enzymeSeqs are the recognition sequences of enzymes that linearize the backbone
+	enzymeSeqs []string
+
+	// out is where the session is logged to
+	out io.Writer
+}
+
+// ReplCmd starts a repl for designing the target plasmid. Settings are changed and
+// the design is re-run from the stdin.
+func ReplCmd(cmd *cobra.Command, args []string) {
+	flags, conf := parseCmdFlags(cmd, args, true)
+
+	backbone, _ := cmd.Flags().GetString("backbone")
+	enzymes, _ := cmd.Flags().GetString("enzymes")
+	enzymeSeqs, _ := cmd.Flags().GetString("enzyme-seq")
+	p := &inputParser{}
+	r := &repl{
+		flags:      flags,
+		conf:       conf,
+		backbone:   backbone,
+		enzymes:    p.parseCommaList(enzymes),
+		enzymeSeqs: p.parseCommaList(enzymeSeqs),
+		out:        os.Stdout,
+	}
+
+	fmt.Fprintf(r.out, "designing %s, enter 'help' for the commands\n", flags.in)
+	r.loop(os.Stdin)
+}
+
+// loop executes each line of the input as a command till it's exhausted or a quit.
+func (r *repl) loop(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for fmt.Fprint(r.out, "> "); scanner.Scan(); fmt.Fprint(r.out, "> ") {
+		if quit := r.exec(scanner.Text()); quit {
+			return
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
+// exec executes a single command. It returns true if the repl should exit.
+func (r *repl) exec(line string) (quit bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch fields[0] {
+	case "run":
+		r.run()
+	case "set":
+		if len(fields) != 3 {
+			fmt.Fprintln(r.out, "expected a setting and value: set [setting] [value]")
+			return false
+		}
+		if err := r.set(fields[1], fields[2]); err != nil {
+			fmt.Fprintln(r.out, err)
+		}
+	case "show":
+		if len(fields) == 1 {
+			fmt.Fprintf(r.out, "target: %s\nbackbone: %s\nenzymes: %s\n", r.flags.in, r.backbone, strings.Join(r.enzymes, ","))
+			return false
+		}
+		value, err := getSetting(r.conf, fields[1])
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+			return false
+		}
+		fmt.Fprintf(r.out, "%s: %s\n", fields[1], value)
+	case "help":
+		fmt.Fprint(r.out, replHelp)
+	case "quit", "exit":
+		return true
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, enter 'help' for the commands\n", fields[0])
+	}
+
+	return false
+}
+
+// run designs the target with the current settings and logs a summary of each solution.
+func (r *repl) run() {
+	start := time.Now()
+
+	results, err := Design(r.flags, r.conf)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+
+	fmt.Fprintf(r.out, "%d solutions in %.1fs, written to %s\n", len(results), time.Since(start).Seconds(), r.flags.out)
+	for _, result := range results {
+		var names []string
+		for _, f := range result.Fragments() {
+			name := f.ID
+			if name == "" {
+				name = f.URL
+			}
+			names = append(names, fmt.Sprintf("%s (%s)", name, f.Type))
+		}
+		fmt.Fprintf(r.out, "\t%d fragments, %s: %s\n", result.FragmentCount(), r.conf.FormatCost(result.Cost()), strings.Join(names, ", "))
+	}
+}
+
+// set changes a setting of the next run. The backbone is digested again if it or
+// the enzymes change.
+func (r *repl) set(name, value string) error {
+	switch name {
+	case "backbone", "enzymes":
+		p := &inputParser{}
+		backbone, enzymes, enzymeSeqs := r.backbone, r.enzymes, r.enzymeSeqs
+		if name == "backbone" {
+			backbone = value
+		} else {
+			enzymes, enzymeSeqs = p.parseCommaList(value), nil
+		}
+
+		frag, meta, err := p.parseBackbone(backbone, enzymes, enzymeSeqs, r.flags.dbs, r.conf)
+		if err != nil {
+			return err
+		}
+		r.backbone, r.enzymes, r.enzymeSeqs = backbone, enzymes, enzymeSeqs
+		r.flags.backbone, r.flags.backboneMeta = frag, meta
+		return nil
+	default:
+		return setSetting(r.conf, name, value)
+	}
+}
+
+// settingField returns the field of the settings with the name from the settings file.
+func settingField(conf *config.Config, name string) (reflect.Value, error) {
+	v := reflect.ValueOf(conf).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if tag := v.Type().Field(i).Tag.Get("mapstructure"); tag != "" && tag != "-" && tag == name {
+			return v.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown setting %s", name)
+}
+
+// setSetting sets the setting with the name from the settings file, like
+// fragments-min-junction-length, to the value. Only numbers and strings can be set.
+func setSetting(conf *config.Config, name, value string) error {
+	field, err := settingField(conf, name)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s as an integer: %v", value, err)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s as a number: %v", value, err)
+		}
+		field.SetFloat(n)
+	case reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("%s can't be set in the repl, change it in a settings file", name)
+	}
+
+	return nil
+}
+
+// getSetting returns the value of the setting with the name from the settings file.
+func getSetting(conf *config.Config, name string) (string, error) {
+	field, err := settingField(conf, name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(field.Interface()), nil
+}
@@ -0,0 +1,78 @@
+package repp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_setSetting(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting string
+		value   string
+		wantErr bool
+	}{
+		{"int", "fragments-min-junction-length", "25", false},
+		{"float", "pcr-bp-cost", "0.7", false},
+		{"string", "pcr-primer-name", "{{.ID}}_{{.Direction}}", false},
+		{"bad int", "fragments-min-junction-length", "twenty", true},
+		{"unknown", "fragments-min-nothing", "1", true},
+		{"map", "pcr-primer3-settings", "PRIMER_OPT_TM=60", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.Config{}
+			err := setSetting(conf, tt.setting, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setSetting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := getSetting(conf, tt.setting)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.value {
+				t.Errorf("getSetting() = %s, want %s", got, tt.value)
+			}
+		})
+	}
+}
+
+func Test_repl_exec(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantQuit bool
+		wantOut  string
+	}{
+		{"set", "set fragments-min-junction-length 25", false, ""},
+		{"show", "show fragments-min-junction-length", false, "fragments-min-junction-length: 20\n"},
+		{"set without a value", "set fragments-min-junction-length", false, "expected a setting and value"},
+		{"unknown", "design", false, "unknown command design"},
+		{"blank", "  ", false, ""},
+		{"quit", "quit", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			r := &repl{
+				flags: &Flags{in: "target.fa"},
+				conf:  &config.Config{FragmentsMinHomology: 20},
+				out:   out,
+			}
+
+			if quit := r.exec(tt.line); quit != tt.wantQuit {
+				t.Errorf("exec() = %v, want %v", quit, tt.wantQuit)
+			}
+			if !strings.HasPrefix(out.String(), tt.wantOut) {
+				t.Errorf("exec() logged %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
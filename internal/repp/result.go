@@ -0,0 +1,84 @@
+package repp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+// AssemblyResult is a single solution of a design, with accessors for the queries
+// that callers using repp as a library commonly need.
+type AssemblyResult struct {
+	// solution is the solution as it's written to the output file
+	solution Solution
+}
+
+// Design builds the target sequence with fragments from the databases. Unlike
+// Sequence, failures are returned rather than logged. The output file is written
+// and a result is returned for each of its solutions, in the same order.
+func Design(flags *Flags, conf *config.Config) ([]AssemblyResult, error) {
+	output, _, err := buildSequence(flags, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAssemblyResults(output)
+}
+
+// newAssemblyResults returns a result for each solution in a design's JSON output.
+func newAssemblyResults(output []byte) (results []AssemblyResult, err error) {
+	out := Output{}
+	if err = json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse design output: %v", err)
+	}
+
+	for _, s := range out.Solutions {
+		results = append(results, AssemblyResult{solution: s})
+	}
+
+	return results, nil
+}
+
+// Cost returns the estimated cost of the assembly, including its procurement,
+// PCRs, synthesis and assembly.
+func (r AssemblyResult) Cost() float64 {
+	return r.solution.Cost
+}
+
+// FragmentCount returns the number of fragments in the assembly.
+func (r AssemblyResult) FragmentCount() int {
+	return r.solution.Count
+}
+
+// Fragments returns the assembly's fragments, in order from its rotation on the target.
+func (r AssemblyResult) Fragments() []*Frag {
+	return r.solution.Fragments
+}
+
+// Primers returns the primers of every PCR fragment in the assembly, FWD then REV.
+func (r AssemblyResult) Primers() (primers []Primer) {
+	for _, f := range r.solution.Fragments {
+		primers = append(primers, f.Primers...)
+	}
+	return
+}
+
+// SyntheticFragments returns the fragments of the assembly that have to be synthesized.
+func (r AssemblyResult) SyntheticFragments() (synths []*Frag) {
+	for _, f := range r.solution.Fragments {
+		if f.Type == synthetic.String() {
+			synths = append(synths, f)
+		}
+	}
+	return
+}
+
+// JunctionTms returns the estimated Tm (celcius) of each fragment's junction with the
+// next, in fragment order. It's 0 for a fragment without a junction.
+func (r AssemblyResult) JunctionTms() (tms []float64) {
+	for _, f := range r.solution.Fragments {
+		tms = append(tms, f.JunctionTm)
+	}
+	return
+}
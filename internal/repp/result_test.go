@@ -0,0 +1,44 @@
+package repp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_newAssemblyResults(t *testing.T) {
+	output := []byte(`{
+		"target": "p1",
+		"solutions": [
+			{"count": 2, "cost": 120.5, "fragments": [
+				{"type": "pcr", "seq": "ATGC", "junctionTm": 64.2, "primers": [{"seq": "ATG", "strand": true}, {"seq": "GCA", "strand": false}]},
+				{"type": "synthetic", "seq": "ATGCATGC", "junctionTm": 61.8}
+			]}
+		]
+	}`)
+
+	results, err := newAssemblyResults(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("newAssemblyResults() = %d results, want 1", len(results))
+	}
+
+	r := results[0]
+	if r.Cost() != 120.5 || r.FragmentCount() != 2 {
+		t.Errorf("Cost(), FragmentCount() = %v, %v, want 120.5, 2", r.Cost(), r.FragmentCount())
+	}
+
+	wantPrimers := []Primer{Primer{Seq: "ATG", Strand: true}, Primer{Seq: "GCA", Strand: false}}
+	if !reflect.DeepEqual(r.Primers(), wantPrimers) {
+		t.Errorf("Primers() = %+v, want %+v", r.Primers(), wantPrimers)
+	}
+
+	if synths := r.SyntheticFragments(); len(synths) != 1 || synths[0].Seq != "ATGCATGC" {
+		t.Errorf("SyntheticFragments() = %+v, want the ATGCATGC fragment", synths)
+	}
+
+	if tms := r.JunctionTms(); !reflect.DeepEqual(tms, []float64{64.2, 61.8}) {
+		t.Errorf("JunctionTms() = %v, want [64.2 61.8]", tms)
+	}
+}
@@ -0,0 +1,288 @@
+package repp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// sbolNamespace is the prefix of the URIs of the objects in an SBOL document
+	sbolNamespace = "https://jjtimmons.github.io/repp/"
+
+	// sbolDNA is the BioPAX type of a ComponentDefinition of DNA
+	sbolDNA = "http://www.biopax.org/release/biopax-level3.owl#DnaRegion"
+
+	// sbolCircular is the Sequence Ontology type of a circular ComponentDefinition
+	sbolCircular = "http://identifiers.org/so/SO:0000988"
+
+	// sbolEncoding is the encoding of IUPAC DNA sequences
+	sbolEncoding = "http://www.chem.qmul.ac.uk/iubmb/misc/naseq.html"
+)
+
+// sbolRoles are Sequence Ontology roles of features, by a word in their name
+var sbolRoles = []struct {
+	word, role string
+}{
+	{"promoter", "SO:0000167"},
+	{"terminator", "SO:0000141"},
+	{"rbs", "SO:0000139"},
+	{"ori", "SO:0000296"},
+}
+
+var (
+	// sbolInvalidID matches the characters that can't be in an SBOL displayId
+	sbolInvalidID = regexp.MustCompile("[^A-Za-z0-9_]+")
+
+	// sbolWordSplit matches the characters between the words of a feature's name
+	sbolWordSplit = regexp.MustCompile("[^a-z0-9]+")
+)
+
+// writeSBOL writes the cheapest of the assemblies as an SBOL2 document. The plasmid is
+// a ComponentDefinition with a Component for each fragment, itself a ComponentDefinition
+// with the fragment's sequence. Fragments and their junctions are SequenceAnnotations of
+// the plasmid. The document is next to the output file: example.output.xml
+func writeSBOL(filename, name, targetSeq string, assemblies [][]*Frag) error {
+	if len(assemblies) < 1 {
+		return fmt.Errorf("failed to write SBOL document: no solutions")
+	}
+
+	doc, err := sbolDocument(name, targetSeq, assemblies[0], NewFeatureDB().features)
+	if err != nil {
+		return fmt.Errorf("failed to write SBOL document: %v", err)
+	}
+
+	sbolFile := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".xml"
+	if err = ioutil.WriteFile(sbolFile, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("failed to write SBOL document: %v", err)
+	}
+
+	return nil
+}
+
+// sbolDocument returns the SBOL2 RDF/XML of a plasmid and the fragments that make it.
+// Coordinates are 1-based and inclusive, and a fragment across the zero index has two ranges.
+func sbolDocument(name, targetSeq string, assembly []*Frag, features map[string]string) (string, error) {
+	targetSeq = strings.ToUpper(targetSeq)
+	tL := len(targetSeq)
+	if tL < 1 {
+		return "", fmt.Errorf("no plasmid sequence")
+	}
+
+	plasmidID := sbolDisplayID(name, "plasmid")
+	plasmidURI := sbolNamespace + plasmidID
+
+	// the ranges of each fragment, and each junction, on the plasmid
+	ranges := func(start, end int) (r [][2]int) {
+		start = (start%tL+tL)%tL + 1
+		end = (end%tL+tL)%tL + 1
+		if end < start {
+			return [][2]int{{start, tL}, {1, end}}
+		}
+		return [][2]int{{start, end}}
+	}
+	span := func(f *Frag) (int, int) {
+		if len(f.Primers) == 2 {
+			return f.Primers[0].Range.start, f.Primers[1].Range.end
+		}
+		return f.start, f.end
+	}
+
+	var components, annotations, definitions strings.Builder
+	definitionIDs := make(map[string]bool)
+	for i, f := range assembly {
+		fragName := f.ID
+		if fragName == "" {
+			fragName = f.URL
+		}
+
+		// each distinct fragment is a ComponentDefinition, with a unique displayId
+		defID := sbolDisplayID(fragName, fmt.Sprintf("fragment_%d", i+1))
+		for suffix := 2; definitionIDs[defID] || defID == plasmidID; suffix++ {
+			defID = fmt.Sprintf("%s_%d", sbolDisplayID(fragName, "fragment"), suffix)
+		}
+		definitionIDs[defID] = true
+
+		fragSeq := f.PCRSeq
+		if fragSeq == "" {
+			fragSeq = f.Seq
+		}
+		definitions.WriteString(sbolDefinition(defID, fragName, sbolRole(f, features), false, fragSeq, ""))
+
+		componentURI := fmt.Sprintf("%s/%s_%d", plasmidURI, defID, i+1)
+		fmt.Fprintf(&components, `    <sbol:component>
+      <sbol:Component rdf:about="%s/1">
+        <sbol:persistentIdentity rdf:resource="%s"/>
+        <sbol:displayId>%s_%d</sbol:displayId>
+        <sbol:version>1</sbol:version>
+        <sbol:definition rdf:resource="%s%s/1"/>
+        <sbol:access rdf:resource="http://sbols.org/v2#public"/>
+      </sbol:Component>
+    </sbol:component>
+`, componentURI, componentURI, defID, i+1, sbolNamespace, defID)
+
+		start, end := span(f)
+		annotations.WriteString(sbolAnnotation(plasmidURI, fmt.Sprintf("%s_%d_annotation", defID, i+1), ranges(start, end), componentURI+"/1", ""))
+
+		// the homology with the next fragment in the assembly
+		if len(assembly) > 1 {
+			next := assembly[(i+1)%len(assembly)]
+			nextStart, _ := span(next)
+			if i == len(assembly)-1 {
+				nextStart += tL // the next fragment is across the zero index
+			}
+			if nextStart <= end {
+				annotations.WriteString(sbolAnnotation(plasmidURI, fmt.Sprintf("junction_%d", i+1), ranges(nextStart, end), "", "SO:0000699"))
+			}
+		}
+	}
+
+	var doc strings.Builder
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:prov="http://www.w3.org/ns/prov#" xmlns:sbol="http://sbols.org/v2#">
+`)
+	doc.WriteString(sbolDefinition(plasmidID, name, "SO:0000637", true, targetSeq, components.String()+annotations.String()))
+	doc.WriteString(definitions.String())
+	doc.WriteString("</rdf:RDF>\n")
+
+	return doc.String(), nil
+}
+
+// sbolDefinition returns a ComponentDefinition of DNA, with the Sequence Ontology role and
+// the children, like its Components, followed by its Sequence.
+func sbolDefinition(displayID, title, role string, circular bool, seq, children string) string {
+	uri := sbolNamespace + displayID
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `  <sbol:ComponentDefinition rdf:about="%s/1">
+    <sbol:persistentIdentity rdf:resource="%s"/>
+    <sbol:displayId>%s</sbol:displayId>
+    <sbol:version>1</sbol:version>
+    <dcterms:title>%s</dcterms:title>
+    <sbol:type rdf:resource="%s"/>
+`, uri, uri, displayID, sbolEscape(title), sbolDNA)
+	if circular {
+		fmt.Fprintf(&sb, "    <sbol:type rdf:resource=\"%s\"/>\n", sbolCircular)
+	}
+	fmt.Fprintf(&sb, "    <sbol:role rdf:resource=\"http://identifiers.org/so/%s\"/>\n", role)
+	fmt.Fprintf(&sb, "    <sbol:sequence rdf:resource=\"%s_sequence/1\"/>\n", uri)
+	sb.WriteString(children)
+	fmt.Fprintf(&sb, `  </sbol:ComponentDefinition>
+  <sbol:Sequence rdf:about="%s_sequence/1">
+    <sbol:persistentIdentity rdf:resource="%s_sequence"/>
+    <sbol:displayId>%s_sequence</sbol:displayId>
+    <sbol:version>1</sbol:version>
+    <sbol:elements>%s</sbol:elements>
+    <sbol:encoding rdf:resource="%s"/>
+  </sbol:Sequence>
+`, uri, uri, displayID, strings.ToLower(seq), sbolEncoding)
+
+	return sb.String()
+}
+
+// sbolAnnotation returns a SequenceAnnotation of the plasmid with a Range for each of the
+// ranges. It's of the component if one is passed, otherwise of the Sequence Ontology role.
+func sbolAnnotation(plasmidURI, displayID string, ranges [][2]int, componentURI, role string) string {
+	uri := plasmidURI + "/" + displayID
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `    <sbol:sequenceAnnotation>
+      <sbol:SequenceAnnotation rdf:about="%s/1">
+        <sbol:persistentIdentity rdf:resource="%s"/>
+        <sbol:displayId>%s</sbol:displayId>
+        <sbol:version>1</sbol:version>
+`, uri, uri, displayID)
+	for i, r := range ranges {
+		rangeURI := fmt.Sprintf("%s/range_%d", uri, i+1)
+		fmt.Fprintf(&sb, `        <sbol:location>
+          <sbol:Range rdf:about="%s/1">
+            <sbol:persistentIdentity rdf:resource="%s"/>
+            <sbol:displayId>range_%d</sbol:displayId>
+            <sbol:version>1</sbol:version>
+            <sbol:start>%d</sbol:start>
+            <sbol:end>%d</sbol:end>
+            <sbol:orientation rdf:resource="http://sbols.org/v2#inline"/>
+          </sbol:Range>
+        </sbol:location>
+`, rangeURI, rangeURI, i+1, r[0], r[1])
+	}
+	if componentURI != "" {
+		fmt.Fprintf(&sb, "        <sbol:component rdf:resource=\"%s\"/>\n", componentURI)
+	} else {
+		fmt.Fprintf(&sb, "        <sbol:role rdf:resource=\"http://identifiers.org/so/%s\"/>\n", role)
+	}
+	sb.WriteString("      </sbol:SequenceAnnotation>\n    </sbol:sequenceAnnotation>\n")
+
+	return sb.String()
+}
+
+// sbolRole returns the Sequence Ontology role of a fragment from the features it's annotated
+// with: those of the features database, by name, whose sequences are in the fragment on
+// either strand. A feature named like a promoter, terminator, RBS, or origin has that role.
+// If the fragment's features have one role, it's the fragment's, otherwise it's an
+// engineered region.
+func sbolRole(f *Frag, features map[string]string) string {
+	seq := strings.ToUpper(f.PCRSeq)
+	if seq == "" {
+		seq = strings.ToUpper(f.Seq)
+	}
+
+	role := ""
+	for name, featSeq := range features {
+		featSeq = strings.ToUpper(featSeq)
+		if featSeq == "" || (!strings.Contains(seq, featSeq) && !strings.Contains(seq, reverseComplement(featSeq))) {
+			continue
+		}
+
+		featRole := sbolFeatureRole(name)
+		if featRole == "" {
+			continue
+		}
+		if role != "" && role != featRole {
+			return "SO:0000804"
+		}
+		role = featRole
+	}
+
+	if role == "" {
+		return "SO:0000804"
+	}
+	return role
+}
+
+// sbolFeatureRole returns the Sequence Ontology role of a feature by the words in its
+// name, or an empty string if it has none of the roles' words.
+func sbolFeatureRole(name string) string {
+	for _, r := range sbolRoles {
+		for _, word := range sbolWordSplit.Split(strings.ToLower(name), -1) {
+			if word == r.word || (len(r.word) > 3 && strings.Contains(word, r.word)) {
+				return r.role
+			}
+		}
+	}
+	return ""
+}
+
+// sbolDisplayID returns the name as an SBOL displayId: only alphanumerics and underscores,
+// and not starting with a number. The fallback is used if the name is empty.
+func sbolDisplayID(name, fallback string) string {
+	id := strings.Trim(sbolInvalidID.ReplaceAllString(name, "_"), "_")
+	if id == "" {
+		return fallback
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		id = "_" + id
+	}
+	return id
+}
+
+// sbolEscape escapes the text for an XML element.
+func sbolEscape(text string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
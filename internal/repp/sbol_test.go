@@ -0,0 +1,100 @@
+package repp
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_sbolDocument(t *testing.T) {
+	target := "AAAAACCCCCGGGGGTTTTT"
+	assembly := []*Frag{
+		{ID: "pSB1A3", Seq: "AAAAACCCCCGG", start: 0, end: 11},
+		{ID: "BBa_E0040", Seq: "CCGGGGGTTTTTAA", start: 8, end: 21},
+	}
+
+	doc, err := sbolDocument("target & backbone", target, assembly, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// it has to be well formed XML
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("sbolDocument() isn't well formed XML: %v", err)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		"<sbol:displayId>target_backbone</sbol:displayId>",
+		"<dcterms:title>target &amp; backbone</dcterms:title>",
+		"<sbol:displayId>BBa_E0040</sbol:displayId>",
+		"<sbol:elements>aaaaacccccgggggttttt</sbol:elements>",
+		"<sbol:start>9</sbol:start>\n            <sbol:end>12</sbol:end>",    // the first junction
+		"<sbol:start>9</sbol:start>\n            <sbol:end>20</sbol:end>",    // the second fragment, across the zero index
+		"<sbol:start>1</sbol:start>\n            <sbol:end>2</sbol:end>",     // ...and its second range
+		"<sbol:role rdf:resource=\"http://identifiers.org/so/SO:0000699\"/>", // the junctions
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("sbolDocument() is missing %q", want)
+		}
+	}
+}
+
+func Test_sbolRole(t *testing.T) {
+	features := map[string]string{
+		"J23100 promoter":    "TTGACGGCTAGCTCAGTCCTAGG",
+		"pUC ori":            "TTGAGATCCTTTTTTTCTGCGCG",
+		"rrnB_T1_terminator": "CAAATAAAACGAAAGGCTCAG",
+		"glorious":           "GGCCTTAAGGCC",
+	}
+
+	tests := []struct {
+		name string
+		frag *Frag
+		want string
+	}{
+		{
+			"promoter by its feature",
+			&Frag{ID: "frag1", Seq: "AAATTGACGGCTAGCTCAGTCCTAGGAAA"},
+			"SO:0000167",
+		},
+		{
+			"origin by its feature on the reverse strand",
+			&Frag{ID: "frag2", Seq: "CGCGCAGAAAAAAAGGATCTCAA"},
+			"SO:0000296",
+		},
+		{
+			"feature's name doesn't have to be a whole word to be a terminator",
+			&Frag{ID: "frag3", Seq: "CAAATAAAACGAAAGGCTCAG"},
+			"SO:0000141",
+		},
+		{
+			"not named by the fragment's ID",
+			&Frag{ID: "J23100_promoter", Seq: "ATGCGTAAAGGCTAA"},
+			"SO:0000804",
+		},
+		{
+			"features with different roles",
+			&Frag{ID: "frag4", Seq: "TTGACGGCTAGCTCAGTCCTAGGCAAATAAAACGAAAGGCTCAG"},
+			"SO:0000804",
+		},
+		{
+			"roles aren't found inside other words",
+			&Frag{ID: "frag5", Seq: "GGCCTTAAGGCC"},
+			"SO:0000804",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sbolRole(tt.frag, features); got != tt.want {
+				t.Errorf("sbolRole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
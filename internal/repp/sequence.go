@@ -1,11 +1,15 @@
 package repp
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -21,9 +25,9 @@ func SequenceFindCmd(cmd *cobra.Command, args []string) {
 	}
 	seq := args[0]
 
-	flags, _ := parseCmdFlags(cmd, args, false)
+	flags, conf := parseCmdFlags(cmd, args, false)
 	tw := blastWriter()
-	matches, err := blast("find_cmd", seq, true, flags.dbs, flags.filters, flags.identity, tw)
+	matches, err := blast("find_cmd", seq, true, conf.BlastCircularPadding, flags.dbs, flags.filters, flags.identity, flags.dbIdentity, flags.dust, tw)
 	if err != nil {
 		stderr.Fatalln(err)
 	}
@@ -60,41 +64,396 @@ func SequenceFindCmd(cmd *cobra.Command, args []string) {
 	writer.Flush()
 }
 
+// CheckCmd checks whether the target plasmid can be built from the fragment databases
+// without searching for assemblies. The regions without a matching fragment are logged.
+func CheckCmd(cmd *cobra.Command, args []string) {
+	flags, conf := parseCmdFlags(cmd, args, true)
+
+	fragments, err := read(flags.in, false, flags.stripInvalid)
+	if err != nil {
+		stderr.Fatalf("failed to read target sequence from %s: %v", flags.in, err)
+	}
+	target := fragments[0]
+	insert := target.copy()
+	if flags.backbone.ID != "" {
+		target.Seq += flags.backbone.Seq
+	}
+
+	matches, err := targetMatches(target, flags, conf)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	gaps := coverageGaps(append(matches, backboneMatch(insert, target, flags)...), len(target.Seq))
+	if err = checkFeasible(target.ID, gaps, len(target.Seq), conf); err != nil {
+		stderr.Fatalln(err)
+	}
+
+	if len(gaps) == 0 {
+		fmt.Printf("%s can be built from these databases, every bp has a matching fragment\n", target.ID)
+		return
+	}
+	fmt.Printf(
+		"%s can be built from these databases with at least %d synthetic fragments\n%s",
+		target.ID, minSynths(gaps, len(target.Seq), conf.SyntheticMaxLength), gapsReport(gaps, len(target.Seq), conf),
+	)
+}
+
 // SequenceCmd takes a cobra command (with its flags) and runs plasmid.
 func SequenceCmd(cmd *cobra.Command, args []string) {
-	Sequence(parseCmdFlags(cmd, args, true))
+	flags, conf := parseCmdFlags(cmd, args, true)
+	if flags.products {
+		Products(flags)
+		return
+	}
+
+	if flags.primersOnly != "" {
+		PrimersOnly(flags, conf)
+		return
+	}
+
+	if len(flags.batch) > 0 || flags.costReport != "" || flags.outputFormat == "jsonl" {
+		SequenceBatch(flags, conf)
+		return
+	}
+
+	Sequence(flags, conf)
 }
 
 // Sequence is for running an end to end plasmid design using a target sequence.
 func Sequence(flags *Flags, conf *config.Config) [][]*Frag {
-	start := time.Now()
+	_, solutions, err := buildSequence(flags, conf)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	return solutions
+}
+
+// SequenceBatch designs a plasmid for each input file of a batch run. A failed design
+// is logged and doesn't stop the batch. With more than one worker, the targets are
+// designed concurrently. If a cost report path was set, a summary of every target's
+// cheapest solution is written to it. With the jsonl output format, each target's output
+// is written as a line of the output file as soon as it's designed.
+func SequenceBatch(flags *Flags, conf *config.Config) {
+	inputs := flags.batch
+	if len(inputs) == 0 {
+		inputs = []string{flags.in}
+	}
+
+	workers := flags.workers
+	if workers < 1 {
+		workers = 1
+	} else if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	var lines *jsonlWriter
+	if flags.outputFormat == "jsonl" {
+		out, err := os.Create(flags.out)
+		if err != nil {
+			stderr.Fatalf("failed to create the output: %v", err)
+		}
+		defer out.Close()
+		lines = &jsonlWriter{w: out}
+	}
 
-	insert, target, solutions, err := sequence(flags, conf) // build up the assemblies that make the sequence
+	// the results of each target, in the order of the inputs
+	rows := make([]costRow, len(inputs))
+	synthRecords := make([]string, len(inputs))
+	boms := make([][][]string, len(inputs))
+
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+	built, failed := 0, 0
+	targets := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range targets {
+				rows[i], synthRecords[i], boms[i] = batchTarget(inputs[i], len(inputs) > 1, flags, conf, lines)
+
+				progressMu.Lock()
+				built++
+				if rows[i].err != nil {
+					failed++
+				}
+				if len(inputs) > 1 {
+					stderr.Printf("built %d of %d targets, %d failed\n", built, len(inputs), failed)
+				}
+				progressMu.Unlock()
+			}
+		}()
+	}
+	for i := range inputs {
+		targets <- i
+	}
+	close(targets)
+	wg.Wait()
+
+	if flags.synthFasta != "" {
+		if err := writeSynthFasta(flags.synthFasta, strings.Join(synthRecords, "")); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.bom != "" {
+		var bom [][]string
+		for _, targetBOM := range boms {
+			bom = append(bom, targetBOM...)
+		}
+		if err := writeBOM(flags.bom, bom); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+
+	if flags.costReport != "" {
+		if err := writeCostReport(flags.costReport, rows); err != nil {
+			stderr.Fatalln(err)
+		}
+	}
+}
+
+// batchTarget designs the plasmid of one input file of a batch run and returns its row of
+// the cost report and, if there's a synth FASTA or BOM, its synthetic fragments' records
+// and its bill of materials' rows. In a batch of many targets, its output, graph, overlap
+// report and reject log are next to its input file.
+// If there are lines, its output is written to them instead.
+func batchTarget(in string, many bool, flags *Flags, conf *config.Config, lines *jsonlWriter) (row costRow, synthRecords string, bom [][]string) {
+	p := inputParser{}
+	targetFlags := *flags
+	targetFlags.in = in
+	targetFlags.synthFasta = "" // every target's synthetic fragments are written together
+	targetFlags.bom = ""
+	if flags.backbone != nil {
+		// its range is set on the target. Copied by hand, copy() drops unexported fields
+		backbone := *flags.backbone
+		backbone.Primers = append([]Primer{}, flags.backbone.Primers...)
+		targetFlags.backbone = &backbone
+	}
+	if many {
+		targetFlags.out = p.guessOutput(in) // each target's output is next to its input
+		if flags.graph != "" {
+			targetFlags.graph = strings.TrimSuffix(targetFlags.out, filepath.Ext(targetFlags.out)) + ".dot"
+		}
+		if flags.overlapReport != "" {
+			targetFlags.overlapReport = strings.TrimSuffix(targetFlags.out, filepath.Ext(targetFlags.out)) + ".overlaps.tsv"
+		}
+	}
+	targetConf := *conf
+	if many && conf.RejectLog != "" {
+		targetConf.RejectLog = strings.TrimSuffix(targetFlags.out, filepath.Ext(targetFlags.out)) + ".rejects.jsonl"
+	}
+	if lines != nil {
+		targetFlags.out = "" // the output is only a line of the batch's
+	}
+
+	output, solutions, err := buildSequence(&targetFlags, &targetConf)
 	if err != nil {
+		stderr.Printf("warning: failed to build %s: %v\n", in, err)
+		return costRow{target: in, err: err}, "", nil
+	}
+
+	if row, err = newCostRow(output); err != nil {
 		stderr.Fatalln(err)
 	}
 
+	if lines != nil {
+		if err = lines.write(output); err != nil {
+			stderr.Fatalf("failed to write the output of %s: %v", in, err)
+		}
+	}
+
+	if flags.synthFasta != "" || flags.bom != "" {
+		out := Output{}
+		if err = json.Unmarshal(output, &out); err != nil {
+			stderr.Fatalln(err)
+		}
+		if flags.synthFasta != "" {
+			synthRecords = synthFasta(out.Target, len(out.TargetSeq), solutions)
+		}
+		if flags.bom != "" {
+			bom = bomRows(out.Target, solutions, flags.backboneMeta, conf)
+		}
+	}
+
+	return row, synthRecords, bom
+}
+
+// buildSequence designs a plasmid from the target sequence and writes the results.
+// The JSON output is returned alongside the solutions.
+func buildSequence(flags *Flags, conf *config.Config) (output []byte, solutions [][]*Frag, err error) {
+	start := time.Now()
+	resetRejections(conf.RejectLog)
+
+	// build up the assemblies that make the sequence, on either strand with --both-strands
+	insert, target, solutions, strand, err := sequenceStrands(flags, conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flags.noBackbone {
+		if err = closingJunctions(solutions, conf); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// write the results to a file
 	elapsed := time.Since(start)
-	_, err = writeJSON(
+	output, err = writeJSON(
 		flags.out,
 		target.ID,
-		target.Seq,
+		target.readSeq(),
+		strand,
 		solutions,
 		len(insert.Seq),
 		elapsed.Seconds(),
 		flags.backboneMeta,
+		flags.dbs,
 		conf,
 	)
 	if err != nil {
-		stderr.Fatalln(err)
+		return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.out})
+	}
+
+	if flags.outputFormat == "benchling" {
+		if err = writeBenchling(flags.out, target.Seq, solutions, flags.backboneMeta); err != nil {
+			return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.out})
+		}
+	}
+
+	if flags.outputFormat == "sbol" {
+		if err = writeSBOL(flags.out, target.ID, target.Seq, solutions); err != nil {
+			return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.out})
+		}
+	}
+
+	if flags.synthFasta != "" {
+		if err = writeSynthFasta(flags.synthFasta, synthFasta(target.ID, len(target.Seq), solutions)); err != nil {
+			return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.synthFasta})
+		}
+	}
+
+	if flags.bom != "" {
+		if err = writeBOM(flags.bom, bomRows(target.ID, solutions, flags.backboneMeta, conf)); err != nil {
+			return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.bom})
+		}
+	}
+
+	if flags.overlapReport != "" {
+		if err = writeOverlapReport(flags.overlapReport, overlapRows(target.Seq, solutions, conf)); err != nil {
+			return nil, nil, inPhase(phaseOutput, err, map[string]interface{}{"out": flags.overlapReport})
+		}
 	}
 
 	if conf.Verbose {
 		fmt.Printf("%s\n\n", elapsed)
 	}
 
-	return solutions
+	return output, solutions, nil
+}
+
+// sequenceStrands designs the target and, with --both-strands, its reverse complement,
+// an equivalent plasmid that may have cheaper matches. The cheaper design is returned
+// with its strand: 1 if it's the target's, -1 if it's the reverse complement's and 0
+// if only the target was designed.
+func sequenceStrands(input *Flags, conf *config.Config) (insert, target *Frag, solutions [][]*Frag, strand int, err error) {
+	if !input.bothStrands {
+		insert, target, solutions, err = sequence(input, conf, false)
+		return insert, target, solutions, 0, err
+	}
+
+	if input.backbone.ID != "" || len(input.inserts) > 0 {
+		stderr.Println("warning: only designing the target's strand, --both-strands isn't supported with a backbone or inserts")
+		insert, target, solutions, err = sequence(input, conf, false)
+		return insert, target, solutions, 0, err
+	}
+
+	if conf.Explain {
+		fmt.Println("target strand:")
+	}
+	insert, target, solutions, err = sequence(input, conf, false)
+
+	rcInput := *input
+	rcInput.graph = "" // only the target's graph is written
+	rcConf := reverseConf(conf)
+	resetRejections(rcConf.RejectLog)
+	if conf.Explain {
+		fmt.Println("reverse complement strand:")
+	}
+	rcInsert, rcTarget, rcSolutions, rcErr := sequence(&rcInput, rcConf, true)
+	if rcErr != nil {
+		return insert, target, solutions, 1, err
+	}
+
+	if err != nil || cheapestCost(rcSolutions) < cheapestCost(solutions) {
+		if conf.Verbose && err == nil {
+			fmt.Printf("the reverse complement of %s is cheaper to build\n", target.ID)
+		}
+		return rcInsert, rcTarget, rcSolutions, -1, nil
+	}
+	return insert, target, solutions, 1, nil
+}
+
+// reverseConf returns a copy of the settings for designing the reverse complement of
+// the target. The order hint and the junctions with set overlaps are reversed, and its
+// rejections are written to their own reject log, ex: "GFP.rc.jsonl" for "GFP.jsonl".
+func reverseConf(conf *config.Config) *config.Config {
+	rcConf := *conf
+
+	if conf.RejectLog != "" {
+		ext := filepath.Ext(conf.RejectLog)
+		rcConf.RejectLog = strings.TrimSuffix(conf.RejectLog, ext) + ".rc" + ext
+	}
+
+	rcConf.OrderHint = nil
+	for i := len(conf.OrderHint) - 1; i >= 0; i-- {
+		rcConf.OrderHint = append(rcConf.OrderHint, conf.OrderHint[i])
+	}
+
+	if conf.JunctionOverlaps != nil {
+		rcConf.JunctionOverlaps = make(map[string]int)
+		for junction, length := range conf.JunctionOverlaps {
+			ids := strings.SplitN(junction, "/", 2)
+			rcConf.JunctionOverlaps[ids[len(ids)-1]+"/"+ids[0]] = length
+		}
+	}
+
+	return &rcConf
+}
+
+// reverseRanges returns the ranges on the reverse complement of a sequence. Ranges may
+// span its zero-index, with ends beyond its length. Ranges repeated across the sequence's
+// copies, like those of noJunctionRanges, are reversed once and repeated again.
+func reverseRanges(ranges []config.Range, seqLength int) (reversed []config.Range) {
+	seen := make(map[config.Range]bool)
+	for _, r := range ranges {
+		// the range in the first copy of the sequence
+		start := r.Start % seqLength
+		if start < 0 {
+			start += seqLength
+		}
+		r.End, r.Start = start+r.End-r.Start, start
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		rc := config.Range{Start: seqLength - 1 - r.End, End: seqLength - 1 - r.Start, Name: r.Name, Seq: r.Seq}
+		if rc.Start < 0 {
+			rc.Start += seqLength
+			rc.End += seqLength
+		}
+		for copies := 0; copies < 4; copies++ {
+			reversed = append(reversed, config.Range{
+				Start: rc.Start + copies*seqLength,
+				End:   rc.End + copies*seqLength,
+				Name:  rc.Name,
+				Seq:   rc.Seq,
+			})
+		}
+	}
+	return
 }
 
 // sequence builds a plasmid cost optimization
@@ -120,53 +479,101 @@ func Sequence(flags *Flags, conf *config.Config) [][]*Frag {
 // "fill-in" the nodes. Create primers on the Frag if it's a PCR Frag
 // or create a sequence to be synthesized if it's a synthetic fragment.
 // Error out and repeat the build stage if a Frag fails to be filled
-func sequence(input *Flags, conf *config.Config) (insert, target *Frag, solutions [][]*Frag, err error) {
-	// read the target sequence (the first in the slice is used)
-	fragments, err := read(input.in, false)
-	if err != nil {
-		return &Frag{}, &Frag{}, nil, fmt.Errorf("failed to read target sequence from %s: %v", input.in, err)
-	}
+//
+// If reverse, the reverse complement of the target is designed instead
+func sequence(input *Flags, conf *config.Config, reverse bool) (insert, target *Frag, solutions [][]*Frag, err error) {
+	if len(input.inserts) > 0 {
+		// the target is the backbone with each insert cloned into it
+		if insert, target, err = insertTarget(input.insertBackbone, input.inserts, input.stripInvalid); err != nil {
+			return &Frag{}, &Frag{}, nil, inPhase(phaseInput, err, nil)
+		}
+	} else {
+		// read the target sequence (the first in the slice is used)
+		fragments, err := read(input.in, false, input.stripInvalid)
+		if err != nil {
+			err = fmt.Errorf("failed to read target sequence from %s: %v", input.in, err)
+			return &Frag{}, &Frag{}, nil, inPhase(phaseInput, err, map[string]interface{}{"in": input.in})
+		}
 
-	if len(fragments) > 1 {
-		stderr.Printf(
-			"warning: %d fragments were in %s. Only targeting the sequence of the first: %s\n",
-			len(fragments),
-			input.in,
-			fragments[0].ID,
-		)
+		if len(fragments) > 1 {
+			stderr.Print(multipleTargetsWarning(input.in, fragments))
+		}
+
+		target = fragments[0]
+		insert = target.copy() // store a copy for logging later
 	}
 
-	target = fragments[0]
 	if conf.Verbose {
 		fmt.Printf("Building %s\n", target.ID)
 	}
 
 	// if a backbone was specified, add it to the sequence of the target frag
-	insert = target.copy() // store a copy for logging later
 	if input.backbone.ID != "" {
+		target.caseSeq = target.readSeq() + input.backbone.Seq
 		target.Seq += input.backbone.Seq
+
+		// the insert shouldn't be cut by the enzymes that linearized the backbone
+		if err = checkSites(target.ID, insert.Seq, backboneEnzymes(input.backboneMeta), input.strict); err != nil {
+			return &Frag{}, &Frag{}, nil, inPhase(phaseInput, err, map[string]interface{}{"target": target.ID, "backbone": input.backbone.ID})
+		}
 	}
 
-	// get all the matches against the target plasmid
-	tw := blastWriter()
-	matches, err := blast(target.ID, target.Seq, true, input.dbs, input.filters, input.identity, tw)
-	if conf.Verbose {
-		tw.Flush()
+	// the target has to be long enough to have a junction
+	if err = checkLength("target", target, conf.FragmentsMinHomology); err != nil {
+		return &Frag{}, &Frag{}, nil, inPhase(phaseInput, err, map[string]interface{}{"target": target.ID})
+	}
+
+	// find the regions of the target that can't have junctions
+	if len(input.noJunctions) > 0 {
+		buildConf := *conf
+		if buildConf.NoJunctions, err = noJunctionRanges(input.noJunctions, target.Seq, conf); err != nil {
+			return &Frag{}, &Frag{}, nil, inPhase(phaseInput, err, nil)
+		}
+		conf = &buildConf
+	}
+
+	// find the target's features, junctions on their boundaries are preferred
+	if input.featureJunctions {
+		buildConf := *conf
+		buildConf.FeatureJunctions = featureJunctionRanges(target.Seq, NewFeatureDB().features, conf)
+		conf = &buildConf
 	}
+
+	// design the reverse complement of the target, its regions without junctions are too
+	if reverse {
+		target = target.Reverse()
+		insert = target.copy()
+
+		buildConf := *conf
+		buildConf.NoJunctions = reverseRanges(conf.NoJunctions, len(target.Seq))
+		buildConf.FeatureJunctions = reverseRanges(conf.FeatureJunctions, len(target.Seq))
+		conf = &buildConf
+	}
+
+	// get all the matches against the target plasmid
+	matches, err := targetMatches(target, input, conf)
 	if err != nil {
-		dbMessage := strings.Join(input.dbs, ", ")
-		return &Frag{}, &Frag{}, nil, fmt.Errorf("failed to blast %s against the dbs %s: %v", target.ID, dbMessage, err)
+		return &Frag{}, &Frag{}, nil, inPhase(phaseBLAST, err, map[string]interface{}{"target": target.ID, "dbs": input.dbs})
 	}
 
-	// keep only "proper" arcs (non-self-contained)
-	matches = cull(matches, len(target.Seq), conf.PCRMinLength, 1)
-	if conf.Verbose {
-		fmt.Printf("%d matches after culling\n", len(matches)/2)
+	// find the stretches of the target without a matching fragment, and fail fast
+	// if they need more synthetic fragments than an assembly can have
+	gaps := coverageGaps(append(matches, backboneMatch(insert, target, input)...), len(target.Seq))
+	if conf.Verbose && len(gaps) > 0 {
+		fmt.Print(gapsReport(gaps, len(target.Seq), conf))
+	}
+	if err = checkFeasible(target.ID, gaps, len(target.Seq), conf); err != nil {
+		return &Frag{}, &Frag{}, nil, inPhase(phaseAssemble, err, gapsDetails(target.ID, gaps, len(target.Seq)))
 	}
 
 	// map fragment Matches to nodes
 	frags := newFrags(matches, conf)
 
+	// confirm every required fragment matched the target plasmid
+	if err = matchedRequired(frags, input.required); err != nil {
+		return &Frag{}, &Frag{}, nil, inPhase(phaseAssemble, err, map[string]interface{}{"required": input.required})
+	}
+
 	if input.backbone.ID != "" {
 		// add the backbone in as fragment (copy twice across zero index)
 		input.backbone.conf = conf
@@ -187,14 +594,424 @@ func sequence(input *Flags, conf *config.Config) (insert, target *Frag, solution
 	}
 
 	// build up a slice of assemblies that could, within the upper-limit on
-	// fragment count, be assembled to make the target plasmid
+	// fragment count, be assembled to make the target plasmid. They share the build's
+	// primers and junctions, and no other build's
+	setBuildCache(frags, newBuildCache())
 	assemblies := createAssemblies(frags, target.Seq, len(target.Seq), false, conf)
 
+	// prune the assemblies without every required fragment
+	if assemblies, err = requireFrags(assemblies, input.required); err != nil {
+		return &Frag{}, &Frag{}, nil, inPhase(phaseAssemble, err, map[string]interface{}{"required": input.required})
+	}
+
 	// build up a map from fragment count to a sorted list of assemblies with that number
 	assemblyCounts, countToAssemblies := groupAssembliesByCount(assemblies)
 
 	// fill in pareto optimal assembly solutions
 	solutions = fillAssemblies(target.Seq, assemblyCounts, countToAssemblies, conf)
+	if input.graph != "" {
+		if err = writeGraph(input.graph, frags, false, solutions); err != nil {
+			return &Frag{}, &Frag{}, nil, inPhase(phaseOutput, err, nil)
+		}
+	}
+	if conf.RejectLog != "" {
+		if err = writeRejectLog(conf.RejectLog); err != nil {
+			return &Frag{}, &Frag{}, nil, inPhase(phaseOutput, err, nil)
+		}
+	}
+	if len(solutions) == 0 && len(gaps) > 0 {
+		err = fmt.Errorf("failed to find a solution for %s\n%s", target.ID, gapsReport(gaps, len(target.Seq), conf))
+		return &Frag{}, &Frag{}, nil, inPhase(phaseAssemble, err, gapsDetails(target.ID, gaps, len(target.Seq)))
+	}
+	if len(solutions) == 0 && len(input.required) > 0 {
+		err = fmt.Errorf("failed to fill any assemblies with the required fragments: %s", strings.Join(input.required, ", "))
+		return &Frag{}, &Frag{}, nil, inPhase(phaseAssemble, err, map[string]interface{}{"required": input.required})
+	}
 
 	return insert, target, solutions, nil
 }
+
+// multipleTargetsWarning returns the warning logged when an input file has more than one
+// sequence. Only the first is designed.
+func multipleTargetsWarning(in string, fragments []*Frag) string {
+	return fmt.Sprintf(
+		"warning: %d fragments were in %s. Only targeting the sequence of the first: %s\n",
+		len(fragments),
+		in,
+		fragments[0].ID,
+	)
+}
+
+// parseInsert parses an insert of a multi-insert cloning: "file[:rev]@position". The insert
+// goes after the 1-based position on the backbone, 0 is before its first bp.
+func parseInsert(spec string) (path string, fwd bool, position int, err error) {
+	at := strings.LastIndex(spec, "@")
+	if at < 0 {
+		return "", false, 0, fmt.Errorf("failed to parse insert %s, expected file[:rev]@position", spec)
+	}
+
+	if position, err = strconv.Atoi(strings.TrimSpace(spec[at+1:])); err != nil {
+		return "", false, 0, fmt.Errorf("failed to parse the position of insert %s: %v", spec, err)
+	}
+
+	path, fwd = orientation(strings.TrimSpace(spec[:at]))
+	return path, fwd, position, nil
+}
+
+// insertTarget returns the target plasmid of a multi-insert cloning, the backbone with
+// each insert at its position, and the inserts' combined sequence. Inserts at the same
+// position are cloned in the order they're passed. Junctions are designed between
+// each insert and the backbone by designing this target like any other.
+func insertTarget(backbone *Frag, specs []string, stripInvalid bool) (insert, target *Frag, err error) {
+	type clonedInsert struct {
+		id       string
+		seq      string
+		position int
+	}
+
+	inserts := []clonedInsert{}
+	for _, spec := range specs {
+		path, fwd, position, err := parseInsert(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if position < 0 || position > len(backbone.Seq) {
+			return nil, nil, fmt.Errorf("insert %s is outside the %d bp backbone %s", spec, len(backbone.Seq), backbone.ID)
+		}
+
+		frags, err := read(path, false, stripInvalid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read insert from %s: %v", path, err)
+		}
+
+		seq := strings.ToUpper(frags[0].Seq)
+		if !fwd {
+			seq = reverseComplement(seq)
+		}
+		inserts = append(inserts, clonedInsert{frags[0].ID, seq, position})
+	}
+
+	sort.SliceStable(inserts, func(i, j int) bool {
+		return inserts[i].position < inserts[j].position
+	})
+
+	bbSeq := strings.ToUpper(backbone.Seq)
+	ids := []string{}
+	var insertSeqs, targetSeq strings.Builder
+	last := 0
+	for _, ins := range inserts {
+		targetSeq.WriteString(bbSeq[last:ins.position] + ins.seq)
+		insertSeqs.WriteString(ins.seq)
+		ids = append(ids, ins.id)
+		last = ins.position
+	}
+	targetSeq.WriteString(bbSeq[last:])
+
+	insert = &Frag{ID: strings.Join(ids, "-"), Seq: insertSeqs.String()}
+	target = &Frag{ID: backbone.ID + "-" + insert.ID, Seq: targetSeq.String()}
+	return insert, target, nil
+}
+
+// noJunctionRanges turns ranges of the target, as "start-end" (1-indexed), and feature names
+// into the ranges that fragment junctions can't be in. An error is returned if a range is too
+// long to synthesize across. Each is repeated across the target's copies, like fragment ranges.
+func noJunctionRanges(specs []string, target string, conf *config.Config) (ranges []config.Range, err error) {
+	tL := len(target)
+	target = strings.ToUpper(target + target)
+	rangeRegex := regexp.MustCompile(`^(\d+)-(\d+)$`)
+	featureDB := NewFeatureDB()
+
+	for _, spec := range specs {
+		var r config.Range
+		if m := rangeRegex.FindStringSubmatch(spec); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			end, _ := strconv.Atoi(m[2])
+			if start < 1 || start > tL || end < 1 || end > tL {
+				return nil, fmt.Errorf("no-junction range %s is outside the %d bp target", spec, tL)
+			}
+
+			r = config.Range{Start: start - 1, End: end - 1}
+			if r.End < r.Start {
+				r.End += tL // across the zero-index
+			}
+		} else {
+			featureSeq, exists := featureDB.features[spec]
+			if !exists {
+				if suggestion := suggestNames(spec, featureDB.features); suggestion != "" {
+					return nil, fmt.Errorf("failed to find no-junction feature %s in the feature database\n%s", spec, suggestion)
+				}
+				return nil, fmt.Errorf("failed to find no-junction feature %s in the feature database", spec)
+			}
+
+			featureSeq = strings.ToUpper(featureSeq)
+			start := strings.Index(target, featureSeq)
+			if start < 0 {
+				start = strings.Index(target, reverseComplement(featureSeq))
+			}
+			if start < 0 || start >= tL {
+				return nil, fmt.Errorf("failed to find no-junction feature %s in the target sequence", spec)
+			}
+
+			r = config.Range{Start: start, End: start + len(featureSeq) - 1}
+		}
+
+		if r.End-r.Start+1 > conf.SyntheticMaxLength {
+			return nil, fmt.Errorf(
+				"no-junction region %s is %d bp, longer than the %d bp synthetic fragment limit",
+				spec, r.End-r.Start+1, conf.SyntheticMaxLength,
+			)
+		}
+
+		for copies := 0; copies < 4; copies++ {
+			ranges = append(ranges, config.Range{Start: r.Start + copies*tL, End: r.End + copies*tL})
+		}
+	}
+
+	return ranges, nil
+}
+
+// featureJunctionRanges returns the ranges of the features, from the features database, that
+// are in the target on either strand, like the features of --no-junctions. Features shorter
+// than a junction, or as long as the target, are skipped. Each is repeated across the
+// target's copies, like fragment ranges.
+func featureJunctionRanges(target string, features map[string]string, conf *config.Config) (ranges []config.Range) {
+	tL := len(target)
+	target = strings.ToUpper(target + target)
+
+	names := []string{}
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		featureSeq := strings.ToUpper(features[name])
+		if len(featureSeq) < conf.FragmentsMinHomology || len(featureSeq) >= tL {
+			continue
+		}
+
+		start := strings.Index(target, featureSeq)
+		if start < 0 {
+			start = strings.Index(target, reverseComplement(featureSeq))
+		}
+		if start < 0 || start >= tL {
+			continue
+		}
+
+		for copies := 0; copies < 4; copies++ {
+			ranges = append(ranges, config.Range{
+				Start: start + copies*tL,
+				End:   start + len(featureSeq) - 1 + copies*tL,
+				Name:  name,
+				Seq:   featureSeq,
+			})
+		}
+	}
+
+	return ranges
+}
+
+// maxBlastedMatches is the number of targets' matches kept in blastedMatches. Enough for
+// a target and its reverse complement in each of a few builds, as in the repl
+const maxBlastedMatches = 8
+
+// cacheBlastedMatches stores the matches of a target in blastedMatches, removing the
+// oldest target's if there are more than maxBlastedMatches.
+func cacheBlastedMatches(key string, matches []match) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if _, contained := blastedMatches[key]; !contained {
+		blastedKeys = append(blastedKeys, key)
+	}
+	blastedMatches[key] = append([]match{}, matches...)
+
+	for len(blastedKeys) > maxBlastedMatches {
+		delete(blastedMatches, blastedKeys[0])
+		blastedKeys = blastedKeys[1:]
+	}
+}
+
+// targetMatches returns the culled matches of the fragment databases against the target.
+// The target is only BLAST'ed once against the same dbs and filters.
+func targetMatches(target *Frag, input *Flags, conf *config.Config) (matches []match, err error) {
+	key := fmt.Sprintf("%s|%v|%v|%d|%v|%v|%d", target.Seq, input.dbs, input.filters, input.identity, input.dbIdentity, input.dust, conf.BlastCircularPadding)
+	cacheMu.Lock()
+	blasted, contained := blastedMatches[key]
+	cacheMu.Unlock()
+	if contained {
+		matches = append([]match{}, blasted...)
+	} else {
+		tw := blastWriter()
+		matches, err = blast(target.ID, target.Seq, true, conf.BlastCircularPadding, input.dbs, input.filters, input.identity, input.dbIdentity, input.dust, tw)
+		if conf.Verbose {
+			tw.Flush()
+		}
+		if err != nil {
+			dbMessage := strings.Join(input.dbs, ", ")
+			return nil, fmt.Errorf("failed to blast %s against the dbs %s: %v", target.ID, dbMessage, err)
+		}
+		cacheBlastedMatches(key, matches)
+	}
+
+	// mark the matches from the user's inventory
+	for i, m := range matches {
+		matches[i].inventory = input.inInventory(m.db)
+		matches[i].inHand = input.inHand(m.db)
+	}
+
+	// keep only "proper" arcs (non-self-contained)
+	matches = cull(matches, len(target.Seq), conf.PCRMinLength, 1)
+	matches = removeWrapped(matches, len(target.Seq))
+	if conf.Verbose {
+		fmt.Printf("%d matches after culling\n", len(matches)/2)
+	}
+
+	return matches, nil
+}
+
+// backboneMatch returns a match for the backbone's range of the target, if there's one.
+// The backbone isn't BLAST'ed but it covers the end of the target after the insert.
+func backboneMatch(insert, target *Frag, input *Flags) []match {
+	if input.backbone == nil || input.backbone.ID == "" {
+		return nil
+	}
+	return []match{match{entry: input.backbone.ID, queryStart: len(insert.Seq), queryEnd: len(target.Seq) - 1}}
+}
+
+// checkFeasible returns an error, with the regions without a matching fragment, if the
+// gaps in the target's coverage need more synthetic fragments than an assembly can have.
+// Then there's no assembly and the search for them can be skipped.
+func checkFeasible(targetID string, gaps []ranged, targetLength int, conf *config.Config) error {
+	if synths := minSynths(gaps, targetLength, conf.SyntheticMaxLength); synths > conf.FragmentsMaxCount {
+		return fmt.Errorf(
+			"%s can't be built from these databases: it needs at least %d synthetic fragments, more than the %d fragment limit\n%s",
+			targetID, synths, conf.FragmentsMaxCount, gapsReport(gaps, targetLength, conf),
+		)
+	}
+	return nil
+}
+
+// minSynths returns the fewest synthetic fragments, of at most maxLength bp each, that
+// cover every gap of a circular target. Each gap has to be synthesized, so it's a lower
+// bound on the fragment count of any assembly.
+func minSynths(gaps []ranged, targetLength, maxLength int) (fewest int) {
+	if len(gaps) == 0 || maxLength < 1 {
+		return 0
+	}
+
+	// a fragment of an optimal cover can start at the first gap bp it covers, so
+	// cover greedily from each gap bp and keep the fewest
+	fewest = -1
+	for first, firstGap := range gaps {
+		for from := firstGap.start; from <= firstGap.end; from++ {
+			synths, reach := 0, from // reach is the index after the last synthesized bp
+			for k := 0; k <= len(gaps); k++ {
+				g := gaps[(first+k)%len(gaps)]
+				start, end := g.start, g.end
+				if k > 0 && start <= firstGap.start {
+					start, end = start+targetLength, end+targetLength // after the zero index
+				}
+				if k == len(gaps) {
+					end = from + targetLength - 1 // back to where the cover started
+				}
+				if end < reach {
+					continue // covered by the last synthetic fragment
+				}
+				if start < reach {
+					start = reach
+				}
+
+				count := (end - start + maxLength) / maxLength
+				synths += count
+				reach = start + count*maxLength
+			}
+
+			if fewest < 0 || synths < fewest {
+				fewest = synths
+			}
+		}
+	}
+
+	return fewest
+}
+
+// coverageGaps returns the ranges of the circular target sequence that aren't covered by
+// any match. These would have to be synthesized. Gaps across the zero index have an end
+// beyond the target's length.
+func coverageGaps(matches []match, targetLength int) (gaps []ranged) {
+	if targetLength < 1 {
+		return nil
+	}
+
+	covered := make([]bool, targetLength)
+	for _, m := range matches {
+		for i := m.queryStart; i <= m.queryEnd && i-m.queryStart < targetLength; i++ {
+			covered[(i%targetLength+targetLength)%targetLength] = true
+		}
+	}
+
+	// start from a covered index so a gap across the zero index isn't split in two
+	offset := 0
+	for offset < targetLength && !covered[offset] {
+		offset++
+	}
+	if offset == targetLength {
+		return []ranged{ranged{start: 0, end: targetLength - 1}} // no coverage at all
+	}
+
+	gapStart := -1
+	for i := offset; i <= offset+targetLength; i++ {
+		isCovered := covered[i%targetLength]
+		if !isCovered && gapStart < 0 {
+			gapStart = i
+		} else if isCovered && gapStart >= 0 {
+			gaps = append(gaps, ranged{start: gapStart % targetLength, end: gapStart%targetLength + i - gapStart - 1})
+			gapStart = -1
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i].start < gaps[j].start
+	})
+
+	return gaps
+}
+
+// gapsReport returns a description of each gap in coverage, with 1-based coordinates,
+// and whether it's too long to synthesize as a single fragment. The end of a gap across
+// the zero index is wrapped onto the target.
+func gapsReport(gaps []ranged, targetLength int, conf *config.Config) string {
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("%d regions without a matching fragment:\n", len(gaps)))
+	for _, g := range gaps {
+		length := g.end - g.start + 1
+		report.WriteString(fmt.Sprintf("\tbp %d-%d (%d bp)", g.start%targetLength+1, g.end%targetLength+1, length))
+		if length > conf.SyntheticMaxLength {
+			report.WriteString(" is too long to synthesize")
+		}
+		report.WriteString("\n")
+	}
+
+	return report.String()
+}
+
+// matchedRequired returns an error if a required fragment is not among the fragments
+func matchedRequired(frags []*Frag, required []string) error {
+	for _, id := range required {
+		found := false
+		for _, f := range frags {
+			if f.ID == id {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("required fragment %s does not match the target plasmid", id)
+		}
+	}
+
+	return nil
+}
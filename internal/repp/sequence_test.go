@@ -1,8 +1,16 @@
 package repp
 
 import (
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/jjtimmons/repp/config"
 )
 
 // if an input fragment being built is exactly the same as one in a DB, it should be used
@@ -26,3 +34,433 @@ func Test_sequence(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_coverageGaps(t *testing.T) {
+	type args struct {
+		matches      []match
+		targetLength int
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantGaps []ranged
+	}{
+		{
+			"fully covered",
+			args{
+				[]match{
+					match{queryStart: 0, queryEnd: 60},
+					match{queryStart: 50, queryEnd: 109},
+				},
+				100,
+			},
+			nil,
+		},
+		{
+			"gap in the middle",
+			args{
+				[]match{
+					match{queryStart: 0, queryEnd: 40},
+					match{queryStart: 60, queryEnd: 99},
+				},
+				100,
+			},
+			[]ranged{ranged{start: 41, end: 59}},
+		},
+		{
+			"gap across the zero index",
+			args{
+				[]match{
+					match{queryStart: 10, queryEnd: 89},
+				},
+				100,
+			},
+			[]ranged{ranged{start: 90, end: 109}},
+		},
+		{
+			"no coverage",
+			args{
+				[]match{},
+				100,
+			},
+			[]ranged{ranged{start: 0, end: 99}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotGaps := coverageGaps(tt.args.matches, tt.args.targetLength); !reflect.DeepEqual(gotGaps, tt.wantGaps) {
+				t.Errorf("coverageGaps() = %v, want %v", gotGaps, tt.wantGaps)
+			}
+		})
+	}
+}
+
+func Test_gapsReport(t *testing.T) {
+	conf := config.New()
+	conf.SyntheticMaxLength = 100
+
+	// the second gap crosses the zero index
+	got := gapsReport([]ranged{{start: 10, end: 19}, {start: 390, end: 409}}, 400, conf)
+	want := "2 regions without a matching fragment:\n\tbp 11-20 (10 bp)\n\tbp 391-10 (20 bp)\n"
+	if got != want {
+		t.Errorf("gapsReport() = %q, want %q", got, want)
+	}
+}
+
+func Test_minSynths(t *testing.T) {
+	tests := []struct {
+		name         string
+		gaps         []ranged
+		targetLength int
+		maxLength    int
+		want         int
+	}{
+		{
+			"no gaps",
+			nil,
+			100,
+			10,
+			0,
+		},
+		{
+			"gap longer than a synthetic fragment",
+			[]ranged{ranged{start: 41, end: 59}},
+			100,
+			10,
+			2,
+		},
+		{
+			"one fragment across two gaps",
+			[]ranged{ranged{start: 10, end: 14}, ranged{start: 20, end: 24}},
+			100,
+			20,
+			1,
+		},
+		{
+			"gap across the zero index",
+			[]ranged{ranged{start: 20, end: 29}, ranged{start: 90, end: 109}},
+			100,
+			20,
+			2,
+		},
+		{
+			"no coverage",
+			[]ranged{ranged{start: 0, end: 99}},
+			100,
+			30,
+			4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := minSynths(tt.gaps, tt.targetLength, tt.maxLength); got != tt.want {
+				t.Errorf("minSynths() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_noJunctionRanges(t *testing.T) {
+	conf := config.New()
+	conf.SyntheticMaxLength = 100
+	target := strings.Repeat("ATGC", 100) // 400 bp
+
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []config.Range
+		wantErr bool
+	}{
+		{
+			"range",
+			[]string{"11-20"},
+			[]config.Range{{Start: 10, End: 19}, {Start: 410, End: 419}, {Start: 810, End: 819}, {Start: 1210, End: 1219}},
+			false,
+		},
+		{
+			"range across the zero-index",
+			[]string{"391-10"},
+			[]config.Range{{Start: 390, End: 409}, {Start: 790, End: 809}, {Start: 1190, End: 1209}, {Start: 1590, End: 1609}},
+			false,
+		},
+		{
+			"range outside the target",
+			[]string{"391-410"},
+			nil,
+			true,
+		},
+		{
+			"range too long to synthesize across",
+			[]string{"1-200"},
+			nil,
+			true,
+		},
+		{
+			"unknown feature",
+			[]string{"not-a-feature-name"},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := noJunctionRanges(tt.specs, target, conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("noJunctionRanges() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("noJunctionRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_featureJunctionRanges(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 10
+
+	target := "GGGGGAATTGTGAGCGGATAACAATCCCCCTTAGCTAGCTTACGATCG"
+	features := map[string]string{
+		"lacO":     "AATTGTGAGCGGATAACAAT",
+		"rev":      reverseComplement("TTAGCTAGCTTACG"),
+		"short":    "GGGGG",
+		"absent":   "ATATATATATATATAT",
+		"whole":    target,
+		"zeroSpan": "ATCGGGGGGAATT", // across the zero index
+	}
+
+	got := featureJunctionRanges(target, features, c)
+
+	tL := len(target)
+	want := []config.Range{}
+	for _, r := range []config.Range{
+		{Start: 5, End: 24, Name: "lacO", Seq: "AATTGTGAGCGGATAACAAT"},
+		{Start: 30, End: 43, Name: "rev", Seq: reverseComplement("TTAGCTAGCTTACG")},
+		{Start: 44, End: 56, Name: "zeroSpan", Seq: "ATCGGGGGGAATT"},
+	} {
+		for copies := 0; copies < 4; copies++ {
+			want = append(want, config.Range{Start: r.Start + copies*tL, End: r.End + copies*tL, Name: r.Name, Seq: r.Seq})
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("featureJunctionRanges() = %v, want %v", got, want)
+	}
+}
+
+func Test_reverseRanges_featureJunctionRanges(t *testing.T) {
+	c := config.New()
+	c.FragmentsMinHomology = 10
+
+	target := "GGGGGAATTGTGAGCGGATAACAATCCCCCTTAGCTAGCTTACGATCG"
+	lacO := "AATTGTGAGCGGATAACAAT"
+	ranges := featureJunctionRanges(target, map[string]string{"lacO": lacO}, c)
+
+	// reversed, they're the feature's ranges in each copy of the reverse complement
+	want := featureJunctionRanges(reverseComplement(target), map[string]string{"lacO": lacO}, c)
+	if len(want) != 4 {
+		t.Fatalf("featureJunctionRanges() = %v, want the feature in 4 copies", want)
+	}
+	if got := reverseRanges(ranges, len(target)); !reflect.DeepEqual(got, want) {
+		t.Errorf("reverseRanges() = %v, want %v", got, want)
+	}
+}
+
+func Test_multipleTargetsWarning(t *testing.T) {
+	fragments := []*Frag{&Frag{ID: "GFP_CDS"}, &Frag{ID: "RFP_CDS"}}
+
+	want := "warning: 2 fragments were in ./targets.fa. Only targeting the sequence of the first: GFP_CDS\n"
+	if got := multipleTargetsWarning("./targets.fa", fragments); got != want {
+		t.Errorf("multipleTargetsWarning() = %q, want %q", got, want)
+	}
+}
+
+func Test_insertTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inserts-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	gfp := filepath.Join(dir, "gfp.fa")
+	rfp := filepath.Join(dir, "rfp.fa")
+	ioutil.WriteFile(gfp, []byte(">gfp\nGGGGAAAA\n"), 0644)
+	ioutil.WriteFile(rfp, []byte(">rfp\nCCCCTTTA\n"), 0644)
+
+	backbone := &Frag{ID: "bb", Seq: "acgtacgtacgtacgtacgt"}
+
+	tests := []struct {
+		name       string
+		specs      []string
+		wantInsert string
+		wantTarget string
+		wantErr    bool
+	}{
+		{
+			"single insert",
+			[]string{gfp + "@4"},
+			"GGGGAAAA",
+			"ACGTGGGGAAAAACGTACGTACGTACGT",
+			false,
+		},
+		{
+			"inserts out of order and reversed",
+			[]string{rfp + "@16", gfp + ":rev@4"},
+			"TTTTCCCCCCCCTTTA",
+			"ACGTTTTTCCCCACGTACGTACGTCCCCTTTAACGT",
+			false,
+		},
+		{
+			"insert outside the backbone",
+			[]string{gfp + "@21"},
+			"",
+			"",
+			true,
+		},
+		{
+			"no position",
+			[]string{gfp},
+			"",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insert, target, err := insertTarget(backbone, tt.specs, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("insertTarget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if insert.Seq != tt.wantInsert {
+				t.Errorf("insertTarget() insert = %s, want %s", insert.Seq, tt.wantInsert)
+			}
+			if target.Seq != tt.wantTarget {
+				t.Errorf("insertTarget() target = %s, want %s", target.Seq, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func Test_reverseRanges(t *testing.T) {
+	// copies repeats the ranges across the 4 copies of a 100 bp sequence
+	copies := func(ranges ...config.Range) (repeated []config.Range) {
+		for _, r := range ranges {
+			for c := 0; c < 4; c++ {
+				repeated = append(repeated, config.Range{Start: r.Start + c*100, End: r.End + c*100, Name: r.Name, Seq: r.Seq})
+			}
+		}
+		return
+	}
+
+	tests := []struct {
+		name   string
+		ranges []config.Range
+		want   []config.Range
+	}{
+		{
+			"within the sequence",
+			[]config.Range{{Start: 10, End: 19}},
+			copies(config.Range{Start: 80, End: 89}),
+		},
+		{
+			"across the zero-index",
+			[]config.Range{{Start: 95, End: 104}},
+			copies(config.Range{Start: 95, End: 104}),
+		},
+		{
+			"ending at the last bp",
+			[]config.Range{{Start: 90, End: 99}},
+			copies(config.Range{Start: 0, End: 9}),
+		},
+		{
+			"repeated across the sequence's copies",
+			copies(config.Range{Start: 10, End: 20}, config.Range{Start: 95, End: 104}),
+			copies(config.Range{Start: 79, End: 89}, config.Range{Start: 95, End: 104}),
+		},
+		{
+			"a feature's name and sequence are kept",
+			copies(config.Range{Start: 10, End: 19, Name: "lacO", Seq: "AATTGTGAGC"}),
+			copies(config.Range{Start: 80, End: 89, Name: "lacO", Seq: "AATTGTGAGC"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reverseRanges(tt.ranges, 100); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reverseRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reverseRanges_noJunctionRanges(t *testing.T) {
+	c := config.New()
+	target := strings.Repeat("ATGC", 25)
+
+	ranges, err := noJunctionRanges([]string{"11-21", "96-5"}, target, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := reverseRanges(ranges, len(target))
+	if len(got) != len(ranges) {
+		t.Fatalf("reverseRanges() = %d ranges, want %d", len(got), len(ranges))
+	}
+	seen := make(map[config.Range]bool)
+	for _, r := range got {
+		if r.Start < 0 || r.End < r.Start || seen[r] {
+			t.Errorf("reverseRanges() = %v, want distinct non-negative ranges", got)
+		}
+		seen[r] = true
+	}
+	if !seen[config.Range{Start: 79 + 300, End: 89 + 300}] {
+		t.Errorf("reverseRanges() = %v, want [79, 89] in the last copy", got)
+	}
+}
+
+func Test_reverseConf(t *testing.T) {
+	conf := &config.Config{
+		OrderHint:        []string{"a", "b", "c"},
+		JunctionOverlaps: map[string]int{"a/b": 40},
+		RejectLog:        "GFP.rejects.jsonl",
+	}
+
+	got := reverseConf(conf)
+	if got.RejectLog != "GFP.rejects.rc.jsonl" {
+		t.Errorf("reverseConf() reject log = %s, want GFP.rejects.rc.jsonl", got.RejectLog)
+	}
+	if !reflect.DeepEqual(got.OrderHint, []string{"c", "b", "a"}) {
+		t.Errorf("reverseConf() order hint = %v, want c, b, a", got.OrderHint)
+	}
+	if length, set := got.JunctionOverlap("b", "a"); !set || length != 40 {
+		t.Errorf("reverseConf() junction b/a = %d, %v, want 40", length, set)
+	}
+	if conf.OrderHint[0] != "a" || conf.JunctionOverlaps["a/b"] != 40 {
+		t.Errorf("reverseConf() changed the original settings: %+v", conf)
+	}
+}
+
+func Test_cacheBlastedMatches(t *testing.T) {
+	blastedMatches, blastedKeys = make(map[string][]match), nil
+	defer func() { blastedMatches, blastedKeys = make(map[string][]match), nil }()
+
+	for i := 0; i <= maxBlastedMatches; i++ {
+		cacheBlastedMatches(strconv.Itoa(i), []match{match{entry: strconv.Itoa(i)}})
+	}
+	cacheBlastedMatches("1", []match{match{entry: "1"}}) // already cached, not added again
+
+	if len(blastedMatches) != maxBlastedMatches || len(blastedKeys) != maxBlastedMatches {
+		t.Errorf("cacheBlastedMatches() kept %d matches and %d keys, want %d", len(blastedMatches), len(blastedKeys), maxBlastedMatches)
+	}
+	if _, contained := blastedMatches["0"]; contained {
+		t.Error("cacheBlastedMatches() kept the oldest target's matches")
+	}
+	if matches := blastedMatches[strconv.Itoa(maxBlastedMatches)]; len(matches) != 1 {
+		t.Errorf("cacheBlastedMatches() = %v for the newest target, want its match", matches)
+	}
+}
@@ -0,0 +1,169 @@
+package repp
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// gcSkewWindow is the length of the windows that GC skew is measured over
+	gcSkewWindow = 1000
+
+	// stabilityMinGC and stabilityMaxGC are the GC % beyond which a construct is flagged
+	stabilityMinGC = 30.0
+	stabilityMaxGC = 70.0
+
+	// stabilityMaxGCSkew is the GC skew, in either direction, beyond which a window is flagged
+	stabilityMaxGCSkew = 0.3
+
+	// stabilityMaxHomopolymer is the length of a single base run beyond which it's flagged
+	stabilityMaxHomopolymer = 10
+
+	// stabilityMaxTandemRepeat is the length of a tandem repeat beyond which it's flagged
+	stabilityMaxTandemRepeat = 20
+)
+
+// Stability is advisory metadata about the sequence of the assembled plasmid. Extreme GC,
+// strong GC skew, and long homopolymers or repeats can make a plasmid unstable or hard to
+// clone. None of them constrain the design.
+type Stability struct {
+	// GC is the GC % of the whole plasmid
+	GC float64 `json:"gc"`
+
+	// MinGCSkew is the lowest (G-C)/(G+C) of a window of the plasmid
+	MinGCSkew float64 `json:"minGCSkew"`
+
+	// MinGCSkewStart is the 1-based start of the window with the lowest GC skew
+	MinGCSkewStart int `json:"minGCSkewStart"`
+
+	// MaxGCSkew is the highest (G-C)/(G+C) of a window of the plasmid
+	MaxGCSkew float64 `json:"maxGCSkew"`
+
+	// MaxGCSkewStart is the 1-based start of the window with the highest GC skew
+	MaxGCSkewStart int `json:"maxGCSkewStart"`
+
+	// Homopolymer is the longest run of a single base
+	Homopolymer *Repeat `json:"homopolymer,omitempty"`
+
+	// TandemRepeat is the longest run of a 2-6 bp unit repeated back to back
+	TandemRepeat *Repeat `json:"tandemRepeat,omitempty"`
+
+	// Warnings are the measures beyond what's typically stable
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Repeat is a run of a repeated unit of sequence.
+type Repeat struct {
+	// Unit is the repeated sequence
+	Unit string `json:"unit"`
+
+	// Start is the 1-based index of the repeat's start
+	Start int `json:"start"`
+
+	// Length is the length of the whole repeat in bp
+	Length int `json:"length"`
+}
+
+// stability measures the GC content, GC skew and longest repeats of a plasmid's sequence.
+// GC skew windows wrap across the zero index of the circular plasmid.
+func stability(seq string) *Stability {
+	seq = strings.ToUpper(seq)
+	if len(seq) < 1 {
+		return nil
+	}
+
+	s := &Stability{GC: math.Round(gcContent(seq)*10) / 10}
+	s.MinGCSkew, s.MinGCSkewStart, s.MaxGCSkew, s.MaxGCSkewStart = gcSkewExtremes(seq)
+	s.Homopolymer = longestRepeat(seq, 1, 1)
+	s.TandemRepeat = longestRepeat(seq, 2, 6)
+
+	if s.GC < stabilityMinGC || s.GC > stabilityMaxGC {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("GC content of %.1f%% is outside %.0f-%.0f%%", s.GC, stabilityMinGC, stabilityMaxGC))
+	}
+	if math.Max(-s.MinGCSkew, s.MaxGCSkew) > stabilityMaxGCSkew {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("GC skew ranges from %.2f at %d to %.2f at %d", s.MinGCSkew, s.MinGCSkewStart, s.MaxGCSkew, s.MaxGCSkewStart))
+	}
+	if h := s.Homopolymer; h != nil && h.Length > stabilityMaxHomopolymer {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("%d bp homopolymer of %s at %d", h.Length, h.Unit, h.Start))
+	}
+	if r := s.TandemRepeat; r != nil && r.Length > stabilityMaxTandemRepeat {
+		s.Warnings = append(s.Warnings, fmt.Sprintf("%d bp tandem repeat of %s at %d", r.Length, r.Unit, r.Start))
+	}
+
+	return s
+}
+
+// gcSkewExtremes returns the lowest and highest GC skew, (G-C)/(G+C), of the windows
+// of the sequence and the 1-based starts of those windows. Windows are gcSkewWindow
+// long, or the length of the sequence if it's shorter.
+func gcSkewExtremes(seq string) (min float64, minStart int, max float64, maxStart int) {
+	window := gcSkewWindow
+	if len(seq) < window {
+		window = len(seq)
+	}
+
+	wrapped := seq + seq[:window-1] // windows that span the zero index
+	g, c := 0, 0
+	count := func(b byte, n int) {
+		switch b {
+		case 'G':
+			g += n
+		case 'C':
+			c += n
+		}
+	}
+	for i := 0; i < window; i++ {
+		count(wrapped[i], 1)
+	}
+
+	for i := 0; i < len(seq); i++ {
+		if i > 0 {
+			count(wrapped[i-1], -1)
+			count(wrapped[i+window-1], 1)
+		}
+
+		skew := 0.0
+		if g+c > 0 {
+			skew = float64(g-c) / float64(g+c)
+		}
+		if i == 0 || skew < min {
+			min, minStart = skew, i+1
+		}
+		if i == 0 || skew > max {
+			max, maxStart = skew, i+1
+		}
+	}
+
+	round := func(skew float64) float64 { return math.Round(skew*1000) / 1000 }
+	return round(min), minStart, round(max), maxStart
+}
+
+// longestRepeat returns the longest run of a unit, minUnit to maxUnit bp long, that's
+// repeated back to back in the sequence. Units of a single base are only counted as
+// homopolymers (a minUnit of 1). Returns nil if there's no repeat of at least two copies.
+func longestRepeat(seq string, minUnit, maxUnit int) (longest *Repeat) {
+	for unit := minUnit; unit <= maxUnit; unit++ {
+		// run is the number of bases that match the one a unit before them
+		for i, run := unit, 0; i <= len(seq); i++ {
+			if i < len(seq) && seq[i] == seq[i-unit] {
+				run++
+				continue
+			}
+
+			start, length := i-run-unit, run+unit
+			run = 0
+			if length < 2*unit || (longest != nil && length <= longest.Length) {
+				continue
+			}
+
+			repeated := seq[start : start+unit]
+			if unit > 1 && strings.Count(repeated, repeated[:1]) == unit {
+				continue // a homopolymer
+			}
+			longest = &Repeat{Unit: repeated, Start: start + 1, Length: length}
+		}
+	}
+
+	return
+}
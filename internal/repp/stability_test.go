@@ -0,0 +1,113 @@
+package repp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_stability(t *testing.T) {
+	tests := []struct {
+		name         string
+		seq          string
+		wantGC       float64
+		wantWarnings int
+	}{
+		{
+			"balanced",
+			strings.Repeat("ATGCTAGC", 10),
+			50,
+			0,
+		},
+		{
+			"GC rich with a long homopolymer",
+			"ATGC" + strings.Repeat("G", 12) + strings.Repeat("GC", 20),
+			96.4,
+			3,
+		},
+		{
+			"empty",
+			"",
+			0,
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stability(tt.seq)
+			if tt.seq == "" {
+				if got != nil {
+					t.Errorf("stability() = %+v, want nil", got)
+				}
+				return
+			}
+
+			if got.GC != tt.wantGC || len(got.Warnings) != tt.wantWarnings {
+				t.Errorf("stability() = %.1f%%, %v, want %.1f%% and %d warnings", got.GC, got.Warnings, tt.wantGC, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func Test_gcSkewExtremes(t *testing.T) {
+	// a G rich half then a C rich half, the windows are the whole sequence so all are 0
+	seq := strings.Repeat("G", 10) + strings.Repeat("C", 10)
+
+	min, minStart, max, maxStart := gcSkewExtremes(seq)
+	if min != 0 || minStart != 1 || max != 0 || maxStart != 1 {
+		t.Errorf("gcSkewExtremes() = %v, %d, %v, %d, want 0, 1, 0, 1", min, minStart, max, maxStart)
+	}
+
+	// the first window without a G is the first that's all C and AT
+	seq = strings.Repeat("G", 1500) + strings.Repeat("AT", 500) + strings.Repeat("C", 500)
+	min, minStart, max, maxStart = gcSkewExtremes(seq)
+	if min != -1 || minStart != 1502 || max != 1 || maxStart != 1 {
+		t.Errorf("gcSkewExtremes() = %v, %d, %v, %d, want -1, 1502, 1, 1", min, minStart, max, maxStart)
+	}
+}
+
+func Test_longestRepeat(t *testing.T) {
+	tests := []struct {
+		name    string
+		seq     string
+		minUnit int
+		maxUnit int
+		want    *Repeat
+	}{
+		{
+			"homopolymer",
+			"ATGCAAAAAAGTC",
+			1,
+			1,
+			&Repeat{Unit: "A", Start: 5, Length: 6},
+		},
+		{
+			"dinucleotide repeat",
+			"GGCATATATATGC",
+			2,
+			6,
+			&Repeat{Unit: "AT", Start: 4, Length: 8},
+		},
+		{
+			"homopolymers aren't tandem repeats",
+			"GCAAAAAAAATCG",
+			2,
+			6,
+			nil,
+		},
+		{
+			"trinucleotide repeat at the end",
+			"GATCAGCAGCAG",
+			2,
+			6,
+			&Repeat{Unit: "CAG", Start: 4, Length: 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longestRepeat(tt.seq, tt.minUnit, tt.maxUnit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("longestRepeat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package repp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jjtimmons/repp/config"
+	"github.com/spf13/cobra"
+)
+
+// SynthesisCmd accepts a cobra command and splits the target plasmid into a number
+// of equal length synthetic fragments that overlap their neighbors.
+func SynthesisCmd(cmd *cobra.Command, args []string) {
+	p := &inputParser{}
+	conf := config.New()
+
+	in, err := cmd.Flags().GetString("in")
+	if in == "" || err != nil {
+		if in, err = p.guessInput(); err != nil {
+			cmd.Help()
+			stderr.Fatal(err)
+		}
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if out == "" || err != nil {
+		out = p.guessOutput(in)
+	}
+
+	if vendor, _ := cmd.Flags().GetString("synth-vendor"); vendor != "" {
+		if err := conf.UseSynthVendor(vendor); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	if avoidSites, _ := cmd.Flags().GetString("avoid-sites"); avoidSites != "" {
+		if conf.AvoidSites, err = p.parseAvoidSites(avoidSites); err != nil {
+			stderr.Fatal(err)
+		}
+	}
+
+	pieces, _ := cmd.Flags().GetInt("pieces")
+	overlap, err := cmd.Flags().GetInt("overlap")
+	if err != nil {
+		overlap = conf.FragmentsMinHomology
+	}
+
+	stripInvalid, _ := cmd.Flags().GetBool("strip-invalid")
+	frags, err := read(in, false, stripInvalid)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+	if len(frags) < 1 {
+		stderr.Fatalf("failed: no sequences found in %s\n", in)
+	}
+	target := frags[0]
+
+	solution, err := synthesis(target.Seq, pieces, overlap, conf)
+	if err != nil {
+		stderr.Fatalln(err)
+	}
+
+	for _, f := range solution {
+		f.ID = fmt.Sprintf("%s-synthesis-%s", target.ID, f.ID)
+	}
+
+	if _, err = writeJSON(out, target.ID, target.readSeq(), 0, [][]*Frag{solution}, len(target.Seq), 0, &Backbone{}, nil, conf); err != nil {
+		stderr.Fatalln(err)
+	}
+}
+
+// synthesis splits a circular target sequence into pieces synthetic fragments
+// of roughly equal length, each with overlap bp of homology with the next.
+// An error is returned if the pieces are outside the synthesis length limits.
+func synthesis(target string, pieces, overlap int, conf *config.Config) (frags []*Frag, err error) {
+	if target == "" {
+		return nil, fmt.Errorf("no plasmid sequence to split")
+	}
+
+	if pieces < 1 {
+		return nil, fmt.Errorf("must split the plasmid into at least one piece, got %d", pieces)
+	}
+
+	if overlap < 0 {
+		return nil, fmt.Errorf("overlap must be positive, got %d", overlap)
+	}
+
+	tL := len(target)
+	if pieces > 1 && tL/pieces <= overlap {
+		return nil, fmt.Errorf("%d bp overlap is too large for %d pieces of a %d bp plasmid", overlap, pieces, tL)
+	}
+
+	// remove enzymes' sites from the whole plasmid, including those across the zero-index
+	target = strings.ToUpper(target)
+	var mutations []Mutation
+	if enzymes := avoidEnzymes(conf); len(enzymes) > 0 {
+		wrapped := target + target[:len(target)/2]
+		wrapped, mutations = removeSites(wrapped, 0, tL, enzymes)
+		target = wrapped[:tL]
+	}
+
+	// add to self to account for the last piece's overlap across the zero-index
+	doubled := target + target
+
+	for i := 0; i < pieces; i++ {
+		start := i * tL / pieces
+		end := (i+1)*tL/pieces + overlap // exclusive
+		if pieces == 1 {
+			end = tL // the whole plasmid, there's nothing to overlap with
+		}
+
+		seq := doubled[start:end]
+		if len(seq) < conf.SyntheticMinLength || len(seq) > conf.SyntheticMaxLength {
+			return nil, fmt.Errorf(
+				"piece %d is %d bp, outside the synthesis limits of %d-%d bp. try a different number of pieces",
+				i+1, len(seq), conf.SyntheticMinLength, conf.SyntheticMaxLength,
+			)
+		}
+
+		frags = append(frags, &Frag{
+			ID:        fmt.Sprintf("%d", i+1),
+			Seq:       seq,
+			fragType:  synthetic,
+			Mutations: mutationsIn(mutations, start, end, tL),
+			conf:      conf,
+		})
+	}
+
+	// set the start and end of each piece on the plasmid via their overlap
+	if pieces > 1 {
+		annealFragments(overlap, overlap, frags)
+
+		junctionConf := *conf
+		junctionConf.FragmentsMinHomology = overlap
+		junctionConf.FragmentsMaxHomology = overlap
+		if err = validateJunctions(frags, &junctionConf); err != nil {
+			return nil, err
+		}
+	}
+
+	return frags, nil
+}
+
+// removeSites substitutes single bp of the sequence, within [from, to), to remove the
+// recognition sites of the enzymes that overlap that range. Bp outside it are shared
+// with other fragments and aren't changed. Each substitution is the one, of those
+// that don't make another site, that's synonymous in the most reading frames: the
+// reading frame of a coding sequence in the target isn't known. Sites that can't be
+// removed are left. The indexes of the mutations are in the sequence (0-indexed).
+func removeSites(seq string, from, to int, enzymes []enzyme) (string, []Mutation) {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(seq) {
+		to = len(seq)
+	}
+	if len(enzymes) == 0 || from >= to {
+		return seq, nil
+	}
+
+	maxRecog := 0
+	for _, e := range enzymes {
+		if len(e.recog) > maxRecog {
+			maxRecog = len(e.recog)
+		}
+	}
+
+	// sitesIn returns the sites within [start, end) of the sequence and the start
+	bases := []byte(seq)
+	sitesIn := func(start, end int) ([]cut, int) {
+		if start < 0 {
+			start = 0
+		}
+		if end > len(bases) {
+			end = len(bases)
+		}
+		cuts, _ := cutsites(string(bases[start:end]), enzymes)
+		return cuts, start
+	}
+
+	// sitesNear counts the sites that a substitution at the index could be in
+	sitesNear := func(index int) int {
+		cuts, _ := sitesIn(index-maxRecog+1, index+maxRecog)
+		return len(cuts)
+	}
+
+	var mutations []Mutation
+	unremovable := make(map[string]bool)
+	for removed := true; removed; {
+		removed = false
+
+		cuts, start := sitesIn(from-maxRecog+1, to+maxRecog-1)
+		for _, c := range cuts {
+			siteKey := fmt.Sprintf("%s%d", c.enzyme.name, start+c.index)
+			if unremovable[siteKey] {
+				continue
+			}
+
+			// find the best substitution of the site's specified bp in the range
+			best := Mutation{Index: -1, SynonymousFrames: -1}
+			for i := 0; i < len(c.enzyme.recog); i++ {
+				index := start + c.index + i
+				recogBase := c.enzyme.recog[i]
+				if !c.strand {
+					recogBase = c.enzyme.recog[len(c.enzyme.recog)-1-i]
+				}
+				if index < from || index >= to || recogBase == 'N' {
+					continue
+				}
+
+				original, before := bases[index], sitesNear(index)
+				for _, b := range []byte("ACGT") {
+					if b == original {
+						continue
+					}
+
+					bases[index] = b
+					if sitesNear(index) < before {
+						if frames := synonymousFrames(bases, index, original); frames > best.SynonymousFrames {
+							best = Mutation{Index: index, From: string(original), To: string(b), Enzyme: c.enzyme.name, SynonymousFrames: frames}
+						}
+					}
+					bases[index] = original
+				}
+			}
+
+			if best.Index < 0 {
+				unremovable[siteKey] = true
+				continue
+			}
+
+			bases[best.Index] = best.To[0]
+			mutations = append(mutations, best)
+			removed = true
+			break // find the remaining sites again
+		}
+	}
+
+	return string(bases), mutations
+}
+
+// synonymousFrames returns the number of reading frames, of the six, in which the
+// bp at the index, substituted for the original, doesn't change the amino acid.
+func synonymousFrames(seq []byte, index int, original byte) (frames int) {
+	for frame := 0; frame < 3; frame++ {
+		codonStart := index - (index+frame)%3
+		if codonStart < 0 || codonStart+3 > len(seq) {
+			continue
+		}
+
+		codon := string(seq[codonStart : codonStart+3])
+		originalCodon := []byte(codon)
+		originalCodon[index-codonStart] = original
+
+		if translate(codon) == translate(string(originalCodon)) {
+			frames++
+		}
+		if translate(reverseComplement(codon)) == translate(reverseComplement(string(originalCodon))) {
+			frames++
+		}
+	}
+
+	return
+}
+
+// translate returns the amino acid of a codon in the standard genetic code. Stops are "*".
+func translate(codon string) byte {
+	const aminoAcids = "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG"
+
+	index := 0
+	for i := 0; i < 3; i++ {
+		base := strings.IndexByte("TCAG", codon[i])
+		if base < 0 {
+			return 'X' // an ambiguous bp
+		}
+		index = index*4 + base
+	}
+
+	return aminoAcids[index]
+}
+
+// mutationsIn returns the mutations in [start, end) of a sequence that repeats the
+// target plasmid, with their indexes in the target plasmid (1-indexed).
+func mutationsIn(mutations []Mutation, start, end, targetLength int) (in []Mutation) {
+	for _, m := range mutations {
+		for index := m.Index % targetLength; index < end; index += targetLength {
+			if index >= start {
+				m.Index = m.Index%targetLength + 1
+				in = append(in, m)
+				break
+			}
+		}
+	}
+
+	return
+}
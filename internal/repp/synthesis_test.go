@@ -0,0 +1,187 @@
+package repp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jjtimmons/repp/config"
+)
+
+func Test_synthesis(t *testing.T) {
+	c := &config.Config{
+		SyntheticMinLength: 50,
+		SyntheticMaxLength: 500,
+	}
+	target := strings.Repeat("ATGCTAGCTA", 10) + strings.Repeat("GCATCGATCG", 10) + strings.Repeat("TTAGCCGATA", 10)
+
+	type args struct {
+		pieces  int
+		overlap int
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			"three pieces",
+			args{3, 20},
+			3,
+			false,
+		},
+		{
+			"pieces too short to synthesize",
+			args{12, 20},
+			0,
+			true,
+		},
+		{
+			"overlap larger than the pieces",
+			args{3, 120},
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFrags, err := synthesis(target, tt.args.pieces, tt.args.overlap, c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("synthesis() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(gotFrags) != tt.wantCount {
+				t.Errorf("synthesis() = %d fragments, want %d", len(gotFrags), tt.wantCount)
+			}
+
+			// each piece should overlap the next and the last should overlap the first
+			for i, f := range gotFrags {
+				next := gotFrags[(i+1)%len(gotFrags)]
+				if f.fragType != synthetic || !strings.HasSuffix(f.Seq, next.Seq[:tt.args.overlap]) {
+					t.Errorf("synthesis() piece %d does not overlap the next: %s, %s", i+1, f.Seq, next.Seq)
+				}
+			}
+		})
+	}
+
+	if _, err := synthesis("", 1, 20, c); err == nil {
+		t.Error("synthesis() expected an error without a plasmid sequence")
+	}
+}
+
+func Test_removeSites(t *testing.T) {
+	bsaI := newEnzyme("BsaI", "GGTCTCN^NNNN_")
+	ecoRI := newEnzyme("EcoRI", "G^AATT_C")
+
+	type args struct {
+		seq  string
+		from int
+		to   int
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantMutations int
+		wantSites     int
+	}{
+		{
+			"no sites",
+			args{"ATGAAACCCGGGTTTAAACCCGGGTTTAAA", 0, 30},
+			0,
+			0,
+		},
+		{
+			"BsaI site on the top strand",
+			args{"ATGAAACCCGGTCTCTTTAAACCCGGGTTTAAA", 0, 33},
+			1,
+			0,
+		},
+		{
+			"BsaI site on the bottom strand and an EcoRI site",
+			args{"ATGAAACCCGAGACCTTTAAAGAATTCGGGTTTAAA", 0, 36},
+			2,
+			0,
+		},
+		{
+			"site outside the range that can change",
+			args{"ATGAAACCCGGTCTCTTTAAACCCGGGTTTAAA", 20, 33},
+			0,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSeq, gotMutations := removeSites(tt.args.seq, tt.args.from, tt.args.to, []enzyme{bsaI, ecoRI})
+			if len(gotMutations) != tt.wantMutations {
+				t.Errorf("removeSites() = %d mutations, want %d", len(gotMutations), tt.wantMutations)
+			}
+
+			if sites, _ := cutsites(gotSeq, []enzyme{bsaI, ecoRI}); len(sites) != tt.wantSites {
+				t.Errorf("removeSites() = %s with %d sites, want %d", gotSeq, len(sites), tt.wantSites)
+			}
+
+			// only the mutated bp are changed, and only within the range
+			diffs := 0
+			for i := range gotSeq {
+				if gotSeq[i] != tt.args.seq[i] {
+					diffs++
+					if i < tt.args.from || i >= tt.args.to {
+						t.Errorf("removeSites() changed bp %d, outside %d-%d", i, tt.args.from, tt.args.to)
+					}
+				}
+			}
+			if diffs != len(gotMutations) {
+				t.Errorf("removeSites() changed %d bp, with %d mutations", diffs, len(gotMutations))
+			}
+		})
+	}
+}
+
+func Test_synonymousFrames(t *testing.T) {
+	type args struct {
+		seq      string
+		index    int
+		original byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantFrames int
+	}{
+		{
+			"same bp",
+			args{"AAAAAA", 2, 'A'},
+			6,
+		},
+		{
+			"third codon position of lysine, AAA to AAG, and of phenylalanine on the bottom strand",
+			args{"AAGAAA", 2, 'A'},
+			2,
+		},
+		{
+			"second codon position, nonsynonymous on both strands",
+			args{"TGG", 1, 'A'},
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotFrames := synonymousFrames([]byte(tt.args.seq), tt.args.index, tt.args.original); gotFrames != tt.wantFrames {
+				t.Errorf("synonymousFrames() = %v, want %v", gotFrames, tt.wantFrames)
+			}
+		})
+	}
+}
+
+func Test_mutationsIn(t *testing.T) {
+	mutations := []Mutation{{Index: 5}, {Index: 95}}
+
+	got := mutationsIn(mutations, 90, 110, 100)
+	if len(got) != 2 || got[0].Index != 6 || got[1].Index != 96 {
+		t.Errorf("mutationsIn() = %+v, want mutations at 6 and 96", got)
+	}
+
+	if got = mutationsIn(mutations, 10, 90, 100); len(got) != 0 {
+		t.Errorf("mutationsIn() = %+v, want none", got)
+	}
+}